@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// DefaultSecret dipakai bila JWT_SECRET kosong, hanya layak untuk development
+const DefaultSecret = "default-secret"
+
+// JWTSecret mengembalikan kunci penandatangan JWT dari JWT_SECRET, atau DefaultSecret bila kosong.
+// Semua penandatangan dan pemeriksa token (admin maupun officer) memakai kunci ini.
+func JWTSecret() []byte {
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	return []byte(DefaultSecret)
+}
+
+// ValidateSecret menolak secret yang kosong atau masih sama dengan DefaultSecret,
+// karena token yang ditandatangani dengan secret tersebut mudah dipalsukan
+func ValidateSecret(secret string) error {
+	switch strings.TrimSpace(secret) {
+	case "":
+		return errors.New("JWT_SECRET belum diisi")
+	case DefaultSecret:
+		return errors.New("JWT_SECRET masih memakai secret default")
+	}
+	return nil
+}
+
+// SignToken menandatangani claims dengan HS256 memakai JWTSecret
+func SignToken(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(JWTSecret())
+}
+
+// ParseToken memverifikasi token dengan JWTSecret dan hanya menerima metode signing HMAC.
+// Claims dibaca sebagai jwt.MapClaims.
+func ParseToken(tokenStr string) (*jwt.Token, error) {
+	return jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("metode signing tidak valid: %v", token.Header["alg"])
+		}
+		return JWTSecret(), nil
+	})
+}
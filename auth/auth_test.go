@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestValidateSecret(t *testing.T) {
+	tests := []struct {
+		secret  string
+		wantErr bool
+	}{
+		{"", true},
+		{"   ", true},
+		{DefaultSecret, true},
+		{" " + DefaultSecret + "\n", true},
+		{"s3cr3t-yang-panjang-dan-acak", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateSecret(tt.secret); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSecret(%q) = %v, wantErr %v", tt.secret, err, tt.wantErr)
+		}
+	}
+}
+
+func TestJWTSecretFallsBackToDefault(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	if got := string(JWTSecret()); got != DefaultSecret {
+		t.Errorf("JWTSecret() = %q, want %q", got, DefaultSecret)
+	}
+	t.Setenv("JWT_SECRET", "rahasia")
+	if got := string(JWTSecret()); got != "rahasia" {
+		t.Errorf("JWTSecret() = %q, want rahasia", got)
+	}
+}
+
+func TestParseTokenRejectsOtherSecretsAndMethods(t *testing.T) {
+	t.Setenv("JWT_SECRET", "rahasia")
+	token, err := SignToken(jwt.MapClaims{"username": "petugas"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err := ParseToken(token); err != nil || !parsed.Valid {
+		t.Fatalf("token sendiri ditolak: %v", err)
+	}
+
+	t.Setenv("JWT_SECRET", "rahasia-lain")
+	if _, err := ParseToken(token); err == nil {
+		t.Error("token dengan secret lain harus ditolak")
+	}
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"username": "petugas"}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseToken(unsigned); err == nil {
+		t.Error("token alg none harus ditolak")
+	}
+}
@@ -0,0 +1,66 @@
+// Command seed-admin membuat akun admin, atau mereset password-nya bila username sudah ada.
+//
+//	ADMIN_PASSWORD=rahasia123 go run ./cmd/seed-admin -username admin -name "Admin Pasar"
+//
+// Password juga bisa dikirim lewat -password, tetapi env lebih aman karena tidak
+// tercatat di riwayat shell. Koneksi database memakai variabel DB_* yang sama dengan server.
+package main
+
+import (
+	"backend/database"
+	"backend/logger"
+	"backend/models"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+const minAdminPasswordLength = 8
+
+func main() {
+	username := flag.String("username", "admin", "username admin")
+	name := flag.String("name", "", "nama tampilan admin")
+	password := flag.String("password", os.Getenv("ADMIN_PASSWORD"), "password admin (default dari ADMIN_PASSWORD)")
+	flag.Parse()
+
+	logger.Init()
+
+	if *username == "" {
+		logger.Fatal("username admin wajib diisi")
+	}
+	if len(*password) < minAdminPasswordLength {
+		logger.Fatal("password admin minimal 8 karakter, isi lewat ADMIN_PASSWORD atau -password")
+	}
+
+	if err := database.ConnectDatabase(); err != nil {
+		logger.Fatal("gagal menyiapkan database", "error", err)
+	}
+
+	var admin models.Admin
+	err := database.DB.Where("username = ?", *username).First(&admin).Error
+	created := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !created {
+		logger.Fatal("gagal mengambil data admin", "error", err)
+	}
+
+	admin.Username = *username
+	admin.IsActive = true
+	if *name != "" {
+		admin.Name = *name
+	}
+	if err := admin.HashPassword(*password); err != nil {
+		logger.Fatal("gagal membuat hash password", "error", err)
+	}
+	if err := database.DB.Save(&admin).Error; err != nil {
+		logger.Fatal("gagal menyimpan admin", "error", err)
+	}
+
+	if created {
+		slog.Info("admin dibuat", "admin_id", admin.ID, "username", admin.Username)
+	} else {
+		slog.Info("password admin direset", "admin_id", admin.ID, "username", admin.Username)
+	}
+}
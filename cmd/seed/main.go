@@ -0,0 +1,184 @@
+// Command seed menyiapkan database baru agar bisa langsung dipakai login: akun admin dashboard,
+// petugas pasar dengan role admin, serta contoh pasar, kategori, barang dan harga.
+//
+//	ADMIN_USERNAME=admin ADMIN_PASSWORD=rahasia123 go run ./cmd/seed
+//
+// Aman dijalankan berulang kali: data yang sudah ada (berdasarkan username atau nama) dilewati
+// dan password akun yang sudah ada tidak diubah, gunakan cmd/seed-admin untuk mereset password.
+// Contoh data bisa dilewati dengan -sample=false. Koneksi database memakai variabel DB_* yang
+// sama dengan server.
+package main
+
+import (
+	"backend/controllers"
+	"backend/database"
+	"backend/logger"
+	"backend/models"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const minAdminPasswordLength = 8
+
+// Contoh barang beserta harga tiga pedagang
+var sampleBarang = []models.Barang{
+	{Nama: "Beras Medium", Satuan: "kg", HargaPedagang1: 12500, HargaPedagang2: 13000, HargaPedagang3: 12800},
+	{Nama: "Cabai Merah", Satuan: "kg", HargaPedagang1: 45000, HargaPedagang2: 47000, HargaPedagang3: 46000},
+	{Nama: "Bawang Merah", Satuan: "kg", HargaPedagang1: 32000, HargaPedagang2: 33000, HargaPedagang3: 31500},
+}
+
+func main() {
+	username := flag.String("username", envOr("ADMIN_USERNAME", "admin"), "username admin (default dari ADMIN_USERNAME)")
+	password := flag.String("password", os.Getenv("ADMIN_PASSWORD"), "password admin (default dari ADMIN_PASSWORD)")
+	sample := flag.Bool("sample", true, "buat contoh pasar, kategori, barang dan harga")
+	flag.Parse()
+
+	logger.Init()
+
+	if *username == "" {
+		logger.Fatal("username admin wajib diisi")
+	}
+	if len(*password) < minAdminPasswordLength {
+		logger.Fatal("password admin minimal 8 karakter, isi lewat ADMIN_PASSWORD atau -password")
+	}
+
+	if err := database.ConnectDatabase(); err != nil {
+		logger.Fatal("gagal menyiapkan database", "error", err)
+	}
+
+	err := database.WithTransaction(func(tx *gorm.DB) error {
+		if err := seedAdmin(tx, *username, *password); err != nil {
+			return err
+		}
+
+		market := models.Market{Name: "Pasar Contoh", Location: "Alamat pasar contoh"}
+		if err := firstOrCreate(tx, &market, "name = ?", market.Name); err != nil {
+			return fmt.Errorf("gagal membuat pasar: %w", err)
+		}
+		if err := seedAdminOfficer(tx, *username, *password, uint64(market.ID)); err != nil {
+			return err
+		}
+		if !*sample {
+			return nil
+		}
+
+		category := models.Category{Name: "Bahan Pokok", Description: "Contoh kategori"}
+		if err := firstOrCreate(tx, &category, "name = ? AND parent_id IS NULL", category.Name); err != nil {
+			return fmt.Errorf("gagal membuat kategori: %w", err)
+		}
+		link := models.CategoryMarket{CategoryID: category.ID, MarketID: market.ID}
+		if err := tx.Where(&link).FirstOrCreate(&link).Error; err != nil {
+			return fmt.Errorf("gagal menghubungkan kategori dan pasar: %w", err)
+		}
+
+		for _, barang := range sampleBarang {
+			if err := seedBarang(tx, barang, category.ID, market.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Fatal("seed gagal, semua perubahan dibatalkan", "error", err)
+	}
+	slog.Info("seed selesai", "username", *username, "sample", *sample)
+}
+
+// seedAdmin membuat akun admin dashboard bila username belum ada
+func seedAdmin(tx *gorm.DB, username, password string) error {
+	var admin models.Admin
+	err := tx.Where("username = ?", username).First(&admin).Error
+	if err == nil {
+		slog.Info("admin sudah ada, dilewati", "username", username)
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("gagal mengambil data admin: %w", err)
+	}
+
+	admin = models.Admin{Username: username, Name: "Administrator", IsActive: true}
+	if err := admin.HashPassword(password); err != nil {
+		return fmt.Errorf("gagal membuat hash password: %w", err)
+	}
+	if err := tx.Create(&admin).Error; err != nil {
+		return fmt.Errorf("gagal membuat admin: %w", err)
+	}
+	slog.Info("admin dibuat", "admin_id", admin.ID, "username", username)
+	return nil
+}
+
+// seedAdminOfficer membuat petugas dengan role admin untuk aplikasi mobile bila username belum ada
+func seedAdminOfficer(tx *gorm.DB, username, password string, marketID uint64) error {
+	var officer models.MarketOfficer
+	err := tx.Unscoped().Where("username = ?", username).First(&officer).Error
+	if err == nil {
+		slog.Info("petugas admin sudah ada, dilewati", "username", username)
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("gagal mengambil data petugas: %w", err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("gagal membuat hash password: %w", err)
+	}
+	officer = models.MarketOfficer{
+		Name:     "Administrator",
+		Username: username,
+		Password: string(hashedPassword),
+		MarketID: marketID,
+		IsActive: true,
+		Role:     models.OfficerRoleAdmin,
+		// NIK unik, diisi penanda agar tidak bentrok dengan petugas lain yang NIK-nya kosong
+		Nik: "seed-" + username,
+	}
+	if err := tx.Create(&officer).Error; err != nil {
+		return fmt.Errorf("gagal membuat petugas admin: %w", err)
+	}
+	slog.Info("petugas admin dibuat", "officer_id", officer.ID, "username", username, "market_id", marketID)
+	return nil
+}
+
+// seedBarang membuat barang contoh beserta harganya lewat sinkronisasi yang sama dengan CreateBarang
+func seedBarang(tx *gorm.DB, barang models.Barang, categoryID, marketID uint) error {
+	var count int64
+	if err := tx.Model(&models.Barang{}).Where("name_key = ? AND market_id = ?", models.NameKey(barang.Nama), marketID).Count(&count).Error; err != nil {
+		return fmt.Errorf("gagal memeriksa barang %s: %w", barang.Nama, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	barang.CategoryID = &categoryID
+	barang.MarketID = marketID
+	barang.HargaSekarang = math.Round((barang.HargaPedagang1+barang.HargaPedagang2+barang.HargaPedagang3)/3*100) / 100
+	barang.TanggalUpdate = time.Now().UTC()
+	if err := tx.Create(&barang).Error; err != nil {
+		return fmt.Errorf("gagal membuat barang %s: %w", barang.Nama, err)
+	}
+	if err := controllers.SyncBarangWithPrice(barang.IdBarang, tx); err != nil {
+		return fmt.Errorf("gagal membuat harga %s: %w", barang.Nama, err)
+	}
+	slog.Info("barang contoh dibuat", "id_barang", barang.IdBarang, "nama", barang.Nama)
+	return nil
+}
+
+func firstOrCreate(tx *gorm.DB, value interface{}, query string, args ...interface{}) error {
+	return tx.Where(query, args...).FirstOrCreate(value).Error
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"backend/auth"
+	"backend/database"
+	"backend/logger"
+	"backend/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const adminTokenTTL = 24 * time.Hour
+
+// AdminLogin memeriksa kredensial admin dan menerbitkan JWT dengan claim is_admin,
+// yang diwajibkan oleh JWTAdminMiddleware
+func AdminLogin(c *fiber.Ctx) error {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Username == "" || req.Password == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Username dan password wajib diisi"})
+	}
+
+	var admin models.Admin
+	if err := database.DB.Where("username = ?", req.Username).First(&admin).Error; err != nil {
+		logger.FromCtx(c).Warn("login admin gagal: admin tidak ditemukan", "username", req.Username)
+		return c.Status(401).JSON(fiber.Map{"error": "Username atau password salah"})
+	}
+	if !admin.CheckPassword(req.Password) {
+		logger.FromCtx(c).Warn("login admin gagal: password salah", "username", req.Username, "admin_id", admin.ID)
+		return c.Status(401).JSON(fiber.Map{"error": "Username atau password salah"})
+	}
+	if !admin.IsActive {
+		return c.Status(401).JSON(fiber.Map{"error": "Akun admin tidak aktif"})
+	}
+
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(adminTokenTTL)
+	token, err := auth.SignToken(jwt.MapClaims{
+		"username": admin.Username,
+		"admin_id": admin.ID,
+		"is_admin": true,
+		"iat":      issuedAt.Unix(),
+		"exp":      expiresAt.Unix(),
+	})
+	if err != nil {
+		logger.FromCtx(c).Error("gagal membuat token admin", "username", admin.Username, "error", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat token login"})
+	}
+
+	return c.JSON(fiber.Map{
+		"token":      token,
+		"expires_at": expiresAt.UTC().Format(time.RFC3339),
+		"admin":      admin,
+	})
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const backfillBatchSize = 100
+
+type BackfillSkipped struct {
+	IdBarang   uint64 `json:"id_barang"`
+	Nama       string `json:"nama"`
+	CategoryID *uint  `json:"category_id"`
+	MarketIDs  []uint `json:"market_ids"`
+	Reason     string `json:"reason"`
+}
+
+// categoryMarketLinks memuat semua relasi kategori-pasar sebagai map category_id -> market_id
+func categoryMarketLinks(db *gorm.DB) (map[uint][]uint, error) {
+	var links []models.CategoryMarket
+	if err := db.Order("category_id, market_id").Find(&links).Error; err != nil {
+		return nil, err
+	}
+
+	marketsByCategory := make(map[uint][]uint)
+	for _, link := range links {
+		marketsByCategory[link.CategoryID] = append(marketsByCategory[link.CategoryID], link.MarketID)
+	}
+	return marketsByCategory, nil
+}
+
+// resolveBarangMarket menentukan pasar barang dari relasi kategorinya. Hanya kategori yang
+// terhubung ke tepat satu pasar yang bisa ditentukan; selain itu dikembalikan alasannya.
+func resolveBarangMarket(marketsByCategory map[uint][]uint, categoryID *uint) (uint, string) {
+	if categoryID == nil {
+		return 0, "barang tidak memiliki kategori"
+	}
+
+	markets := marketsByCategory[*categoryID]
+	switch len(markets) {
+	case 0:
+		return 0, "kategori tidak terhubung ke pasar mana pun"
+	case 1:
+		return markets[0], ""
+	default:
+		return 0, "kategori terhubung ke beberapa pasar"
+	}
+}
+
+// BackfillBarangMarket mengisi MarketID barang yang masih 0 berdasarkan relasi kategori-pasar,
+// diproses per batch dalam transaksi terpisah. Barang yang ambigu dilewati dan dilaporkan.
+func BackfillBarangMarket(c *fiber.Ctx) error {
+	marketsByCategory, err := categoryMarketLinks(database.DB)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil relasi kategori dan pasar"})
+	}
+
+	scanned, updated := 0, 0
+	skipped := []BackfillSkipped{}
+
+	var batch []models.Barang
+	result := database.DB.Where("market_id = 0").FindInBatches(&batch, backfillBatchSize, func(_ *gorm.DB, _ int) error {
+		batchUpdated := 0
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			for _, barang := range batch {
+				scanned++
+
+				marketID, reason := resolveBarangMarket(marketsByCategory, barang.CategoryID)
+				if reason != "" {
+					var candidates []uint
+					if barang.CategoryID != nil {
+						candidates = marketsByCategory[*barang.CategoryID]
+					}
+					skipped = append(skipped, BackfillSkipped{
+						IdBarang:   barang.IdBarang,
+						Nama:       barang.Nama,
+						CategoryID: barang.CategoryID,
+						MarketIDs:  candidates,
+						Reason:     reason,
+					})
+					continue
+				}
+
+				if err := tx.Model(&models.Barang{}).
+					Where("id_barang = ?", barang.IdBarang).
+					Update("market_id", marketID).Error; err != nil {
+					return err
+				}
+				batchUpdated++
+			}
+			return nil
+		})
+		if err == nil {
+			updated += batchUpdated
+		}
+		return err
+	})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Backfill market barang gagal di tengah proses",
+			"scanned": scanned,
+			"updated": updated,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"scanned":       scanned,
+		"updated":       updated,
+		"skipped_count": len(skipped),
+		"skipped":       skipped,
+	})
+}
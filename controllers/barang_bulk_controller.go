@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Batas jumlah barang dalam satu request bulk
+const maxBulkBarangItems = 500
+
+type BulkBarangResult struct {
+	Index    int            `json:"index"`
+	Nama     string         `json:"nama"`
+	IdBarang uint64         `json:"id_barang,omitempty"`
+	Success  bool           `json:"success"`
+	Error    string         `json:"error,omitempty"`
+	Barang   *models.Barang `json:"barang,omitempty"`
+}
+
+// BulkCreateBarang membuat banyak barang sekaligus (mis. hasil satu kunjungan pasar) dalam satu
+// transaksi, tiap barang divalidasi terhadap kategorinya lalu disinkronkan ke tabel harga.
+// Secara default atomic: satu barang gagal membatalkan semuanya (422). Dengan ?atomic=false
+// barang yang gagal dilewati lewat savepoint dan dilaporkan per item.
+func BulkCreateBarang(c *fiber.Ctx) error {
+	atomic := c.QueryBool("atomic", true)
+
+	avgMode, err := parseAvgMode(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var items []models.Barang
+	if err := c.BodyParser(&items); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Body harus berupa array barang"})
+	}
+	if len(items) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Minimal satu barang"})
+	}
+	if len(items) > maxBulkBarangItems {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Maksimal %d barang per request", maxBulkBarangItems)})
+	}
+
+	marketsByCategory, err := categoryMarketLinks(database.DB)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil relasi kategori dan pasar"})
+	}
+
+	var results []BulkBarangResult
+	failed := 0
+
+	// Barang dan hasil disusun ulang dari body pada setiap percobaan, jadi aman diulang saat deadlock
+	input := items
+	err = database.WithRetry(txMaxAttempts, func() error {
+		items = append([]models.Barang(nil), input...)
+		results = make([]BulkBarangResult, len(items))
+		failed = 0
+		return database.WithTransaction(func(tx *gorm.DB) error {
+			for i := range items {
+				barang := &items[i]
+				results[i] = BulkBarangResult{Index: i, Nama: barang.Nama}
+
+				savepoint := fmt.Sprintf("bulk_barang_%d", i)
+				if !atomic {
+					if err := tx.SavePoint(savepoint).Error; err != nil {
+						return err
+					}
+				}
+
+				if err := createBulkBarangItem(c, tx, barang, marketsByCategory, avgMode); err != nil {
+					failed++
+					results[i].Error = err.Error()
+					if atomic {
+						results = results[:i+1]
+						return errBulkItemFailed
+					}
+					if err := tx.RollbackTo(savepoint).Error; err != nil {
+						return err
+					}
+					continue
+				}
+
+				results[i].Success = true
+				results[i].IdBarang = barang.IdBarang
+				results[i].Barang = barang
+			}
+			return nil
+		})
+	})
+	if errors.Is(err, errBulkItemFailed) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Semua barang dibatalkan karena ada barang yang gagal",
+			"atomic":  true,
+			"results": results,
+		})
+	}
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to commit transaction")
+	}
+
+	status := fiber.StatusCreated
+	if failed > 0 {
+		status = fiber.StatusOK
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"atomic":    atomic,
+		"total":     len(items),
+		"succeeded": len(items) - failed,
+		"failed":    failed,
+		"results":   results,
+	})
+}
+
+// createBulkBarangItem memvalidasi satu barang seperti CreateBarang, menghitung harga rata-rata,
+// lalu menyimpannya dan menyinkronkan ke tabel harga di dalam tx
+func createBulkBarangItem(c *fiber.Ctx, tx *gorm.DB, barang *models.Barang, marketsByCategory map[uint][]uint, avgMode string) error {
+	if err := utils.Validate(barang); err != nil {
+		return err
+	}
+	if barang.CategoryID == nil || *barang.CategoryID == 0 {
+		return errors.New("category_id wajib diisi")
+	}
+
+	var category models.Category
+	if err := tx.First(&category, *barang.CategoryID).Error; err != nil {
+		return fmt.Errorf("Category ID %d not found", *barang.CategoryID)
+	}
+
+	// Pasar barang: dari input, atau turunan dari relasi kategori bila hanya satu
+	if barang.MarketID != 0 {
+		var market models.Market
+		if err := tx.First(&market, barang.MarketID).Error; err != nil {
+			return fmt.Errorf("Market ID %d not found", barang.MarketID)
+		}
+	} else {
+		if len(marketsByCategory[category.ID]) > 1 {
+			return errors.New("kategori terhubung ke beberapa pasar, market_id wajib diisi")
+		}
+		barang.MarketID, _ = resolveBarangMarket(marketsByCategory, &category.ID)
+	}
+	if !canWriteMarket(c, uint64(barang.MarketID)) {
+		return errors.New("akses ditolak untuk market ini")
+	}
+
+	if err := validateMerchantPrices(barang.HargaPedagang1, barang.HargaPedagang2, barang.HargaPedagang3); err != nil {
+		return err
+	}
+	barang.IdBarang = 0
+	barang.HargaSebelumnya = 0
+	barang.TanggalUpdate = time.Now().UTC()
+	barang.HargaPedagang1 = roundPrice(barang.HargaPedagang1)
+	barang.HargaPedagang2 = roundPrice(barang.HargaPedagang2)
+	barang.HargaPedagang3 = roundPrice(barang.HargaPedagang3)
+	barang.HargaSekarang = averageMerchantPrices(avgMode, barang.HargaPedagang1, barang.HargaPedagang2, barang.HargaPedagang3)
+	barang.CreatedBy = currentOfficerID(c)
+	barang.UpdatedBy = barang.CreatedBy
+
+	if err := tx.Create(barang).Error; err != nil {
+		return errors.New("gagal menyimpan barang")
+	}
+	if err := SyncBarangWithPrice(barang.IdBarang, tx); err != nil {
+		return fmt.Errorf("gagal sinkron dengan harga: %v", err)
+	}
+	return nil
+}
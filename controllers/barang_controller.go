@@ -2,19 +2,84 @@ package controllers
 
 import (
 	"backend/database"
+	"backend/logger"
 	"backend/models"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// barangSortOrders adalah whitelist nilai ?sort= untuk GetAllBarang
+var barangSortOrders = map[string]string{
+	"name_asc":     "nama ASC, id_barang ASC",
+	"name_desc":    "nama DESC, id_barang ASC",
+	"price_asc":    "harga_sekarang ASC, id_barang ASC",
+	"price_desc":   "harga_sekarang DESC, id_barang ASC",
+	"updated_desc": "tanggal_update DESC, id_barang DESC",
+}
+
+// GetAllBarang mendukung ?search= (nama, tidak peka huruf besar/kecil), ?category_id=,
+// ?min_price=/?max_price= pada harga_sekarang, dan ?sort= dari barangSortOrders, dengan ?page=/?limit=
 func GetAllBarang(c *fiber.Ctx) error {
+	pagination := parsePagination(c, 20)
+
 	var barang []models.Barang
-	if err := database.DB.Preload("Category").Find(&barang).Error; err != nil {
+	query := database.DB.Model(&models.Barang{})
+
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("LOWER(nama) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	for _, bound := range []struct{ param, condition string }{
+		{"min_price", "harga_sekarang >= ?"},
+		{"max_price", "harga_sekarang <= ?"},
+	} {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": bound.param + " harus berupa angka positif"})
+		}
+		query = query.Where(bound.condition, price)
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		order, ok := barangSortOrders[sort]
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "sort harus salah satu dari name_asc, name_desc, price_asc, price_desc, updated_desc",
+			})
+		}
+		query = query.Order(order)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung data barang"})
+	}
+
+	if err := query.
+		Preload("Category").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset()).
+		Find(&barang).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch barang"})
 	}
-	return c.JSON(barang)
+
+	meta := writePagination(c, pagination, total)
+	meta["data"] = barang
+	return c.JSON(meta)
 }
 
 func GetBarangByID(c *fiber.Ctx) error {
@@ -31,6 +96,9 @@ func CreateBarang(c *fiber.Ctx) error {
 	if err := c.BodyParser(&barang); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
+	if ok, err := validateInput(c, &barang); !ok {
+		return err
+	}
 
 	// Set default values
 	barang.HargaSebelumnya = 0
@@ -44,26 +112,61 @@ func CreateBarang(c *fiber.Ctx) error {
 		})
 	}
 
-	// Calculate average price
-	barang.HargaSekarang = (barang.HargaPedagang1 + barang.HargaPedagang2 + barang.HargaPedagang3) / 3
-
-	// Start transaction
-	tx := database.DB.Begin()
-
-	if err := tx.Create(&barang).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to create barang"})
+	// Tentukan pasar barang: pakai market_id dari input, atau turunkan dari relasi kategori
+	if barang.MarketID != 0 {
+		var market models.Market
+		if err := database.DB.First(&market, barang.MarketID).Error; err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error": fmt.Sprintf("Market ID %d not found", barang.MarketID),
+			})
+		}
+	} else {
+		marketsByCategory, err := categoryMarketLinks(database.DB.Where("category_id = ?", category.ID))
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil relasi kategori dan pasar"})
+		}
+		if len(marketsByCategory[category.ID]) > 1 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":      "Kategori terhubung ke beberapa pasar, market_id wajib diisi",
+				"market_ids": marketsByCategory[category.ID],
+			})
+		}
+		barang.MarketID, _ = resolveBarangMarket(marketsByCategory, &category.ID)
+	}
+	if !canWriteMarket(c, uint64(barang.MarketID)) {
+		return c.Status(403).JSON(fiber.Map{"error": "Akses ditolak untuk market ini"})
 	}
 
-	// Sync with price table
-	if err := SyncBarangWithPrice(barang.IdBarang, tx); err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to sync with price: %v", err)})
+	// Calculate average price
+	avgMode, err := parseAvgMode(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := validateMerchantPrices(barang.HargaPedagang1, barang.HargaPedagang2, barang.HargaPedagang3); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	barang.HargaPedagang1 = roundPrice(barang.HargaPedagang1)
+	barang.HargaPedagang2 = roundPrice(barang.HargaPedagang2)
+	barang.HargaPedagang3 = roundPrice(barang.HargaPedagang3)
+	barang.HargaSekarang = averageMerchantPrices(avgMode, barang.HargaPedagang1, barang.HargaPedagang2, barang.HargaPedagang3)
+
+	// Kolom audit selalu dari token, nilai dari body diabaikan
+	barang.CreatedBy = currentOfficerID(c)
+	barang.UpdatedBy = barang.CreatedBy
+
+	err = database.WithTransaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&barang).Error; err != nil {
+			return fiber.NewError(500, "Failed to create barang")
+		}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit transaction"})
+		// Sync with price table
+		if err := SyncBarangWithPrice(barang.IdBarang, tx); err != nil {
+			return fiber.NewError(500, fmt.Sprintf("Failed to sync with price: %v", err))
+		}
+		return nil
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to commit transaction")
 	}
 
 	return c.Status(201).JSON(barang)
@@ -76,135 +179,194 @@ func UpdateBarang(c *fiber.Ctx) error {
 	if err := database.DB.First(&existingBarang, "id_barang = ?", id).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Barang not found"})
 	}
+	if !canWriteMarket(c, uint64(existingBarang.MarketID)) {
+		return c.Status(403).JSON(fiber.Map{"error": "Akses ditolak untuk market ini"})
+	}
 
 	var input struct {
-		Nama            string  `json:"nama"`
-		Satuan          string  `json:"satuan"`
-		HargaPedagang1  float64 `json:"harga_pedagang1"`
-		HargaPedagang2  float64 `json:"harga_pedagang2"`
-		HargaPedagang3  float64 `json:"harga_pedagang3"`
+		Nama            string  `json:"nama" validate:"required,notblank,max=255"`
+		Satuan          string  `json:"satuan" validate:"max=50"`
+		HargaPedagang1  float64 `json:"harga_pedagang1" validate:"gte=0"`
+		HargaPedagang2  float64 `json:"harga_pedagang2" validate:"gte=0"`
+		HargaPedagang3  float64 `json:"harga_pedagang3" validate:"gte=0"`
 		CategoryID      uint64  `json:"category_id"`
 		MarketID        uint    `json:"market_id"`
-		AlasanPerubahan string  `json:"alasan_perubahan"`
+		AlasanPerubahan string  `json:"alasan_perubahan" validate:"max=255"`
 	}
 
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input format"})
 	}
+	if ok, err := validateInput(c, &input); !ok {
+		return err
+	}
+	avgMode, err := parseAvgMode(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := validateMerchantPrices(input.HargaPedagang1, input.HargaPedagang2, input.HargaPedagang3); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 
-	// Start transaction
-	tx := database.DB.Begin()
-
-	// Validasi CategoryID
-	if input.CategoryID != 0 {
-		var category models.Category
-		if err := tx.First(&category, input.CategoryID).Error; err != nil {
-			tx.Rollback()
+	// Pindah pasar: pasar tujuan harus ada dan juga boleh ditulis oleh petugas ini
+	if input.MarketID != 0 && input.MarketID != existingBarang.MarketID {
+		var market models.Market
+		if err := database.DB.First(&market, input.MarketID).Error; err != nil {
 			return c.Status(400).JSON(fiber.Map{
-				"error": fmt.Sprintf("Category ID %d not found", input.CategoryID),
+				"error": fmt.Sprintf("Market ID %d not found", input.MarketID),
 			})
 		}
-		categoryID := uint(input.CategoryID)
-		existingBarang.CategoryID = &categoryID
-	} else {
-		existingBarang.CategoryID = nil
+		if !canWriteMarket(c, uint64(input.MarketID)) {
+			return c.Status(403).JSON(fiber.Map{"error": "Akses ditolak untuk market ini"})
+		}
+		existingBarang.MarketID = input.MarketID
 	}
 
-	// Update other fields
-	existingBarang.Nama = input.Nama
-	existingBarang.Satuan = input.Satuan
-	existingBarang.HargaPedagang1 = input.HargaPedagang1
-	existingBarang.HargaPedagang2 = input.HargaPedagang2
-	existingBarang.HargaPedagang3 = input.HargaPedagang3
-	existingBarang.AlasanPerubahan = input.AlasanPerubahan
-
-	// Calculate new average price
-	newPrice := (input.HargaPedagang1 + input.HargaPedagang2 + input.HargaPedagang3) / 3
-
-	if newPrice != existingBarang.HargaSekarang {
-		history := models.BarangHistory{
-			BarangID:       existingBarang.IdBarang,
-			HargaPedagang1: existingBarang.HargaPedagang1,
-			HargaPedagang2: existingBarang.HargaPedagang2,
-			HargaPedagang3: existingBarang.HargaPedagang3,
-			HargaSekarang:  existingBarang.HargaSekarang,
-			TanggalUpdate:  time.Now(),
+	err = database.WithTransaction(func(tx *gorm.DB) error {
+		// Validasi CategoryID
+		if input.CategoryID != 0 {
+			var category models.Category
+			if err := tx.First(&category, input.CategoryID).Error; err != nil {
+				return fiber.NewError(400, fmt.Sprintf("Category ID %d not found", input.CategoryID))
+			}
+			categoryID := uint(input.CategoryID)
+			existingBarang.CategoryID = &categoryID
+		} else {
+			existingBarang.CategoryID = nil
 		}
 
-		if err := tx.Create(&history).Error; err != nil {
-			tx.Rollback()
-			return c.Status(500).JSON(fiber.Map{"error": "Failed to save price history"})
+		// Update other fields
+		existingBarang.Nama = input.Nama
+		existingBarang.Satuan = input.Satuan
+		existingBarang.HargaPedagang1 = roundPrice(input.HargaPedagang1)
+		existingBarang.HargaPedagang2 = roundPrice(input.HargaPedagang2)
+		existingBarang.HargaPedagang3 = roundPrice(input.HargaPedagang3)
+		existingBarang.AlasanPerubahan = input.AlasanPerubahan
+		existingBarang.UpdatedBy = currentOfficerID(c)
+
+		// Calculate new average price
+		newPrice := averageMerchantPrices(avgMode, existingBarang.HargaPedagang1, existingBarang.HargaPedagang2, existingBarang.HargaPedagang3)
+
+		if !pricesEqual(newPrice, existingBarang.HargaSekarang) {
+			history := models.BarangHistory{
+				BarangID:       existingBarang.IdBarang,
+				HargaPedagang1: existingBarang.HargaPedagang1,
+				HargaPedagang2: existingBarang.HargaPedagang2,
+				HargaPedagang3: existingBarang.HargaPedagang3,
+				HargaSekarang:  existingBarang.HargaSekarang,
+				TanggalUpdate:  time.Now(),
+			}
+
+			if err := tx.Create(&history).Error; err != nil {
+				return txError(500, "Failed to save price history", err)
+			}
+
+			existingBarang.HargaSebelumnya = existingBarang.HargaSekarang
+			existingBarang.HargaSekarang = newPrice
+			existingBarang.TanggalUpdate = time.Now().UTC()
 		}
 
-		existingBarang.HargaSebelumnya = existingBarang.HargaSekarang
-		existingBarang.HargaSekarang = newPrice
-		existingBarang.TanggalUpdate = time.Now().UTC()
-	}
-
-	
-
-	if err := tx.Save(&existingBarang).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update barang"})
-	}
-
-	// Sync with price table
-	if err := SyncBarangWithPrice(existingBarang.IdBarang, tx); err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to sync with price: %v", err)})
-	}
+		if err := tx.Save(&existingBarang).Error; err != nil {
+			return txError(500, "Failed to update barang", err)
+		}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit transaction"})
+		// Sync with price table
+		if err := SyncBarangWithPrice(existingBarang.IdBarang, tx); err != nil {
+			return fiber.NewError(500, fmt.Sprintf("Failed to sync with price: %v", err))
+		}
+		return nil
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to commit transaction")
 	}
 
 	return c.JSON(existingBarang)
 }
 
+// DeleteBarang memindahkan barang ke trash (soft delete) bersama harga dengan nama yang sama.
+// Keduanya diberi deleted_at yang sama agar RestoreBarang bisa mengembalikan pasangan yang tepat.
+// Histori tetap disimpan; penghapusan permanen lewat DeleteBarangPermanent.
 func DeleteBarang(c *fiber.Ctx) error {
 	id := c.Params("id")
-	fmt.Println("🧪 DELETE Request ID:", id)
-
-	tx := database.DB.Begin()
-
-	// Hapus history
-	if err := tx.Unscoped().Where("barang_id = ?", id).Delete(&models.BarangHistory{}).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal hapus history", "detail": err.Error()})
-	}
 
-	// Find the barang to get its name before deleting
-	var barang models.Barang
-	if err := tx.First(&barang, "id_barang = ?", id).Error; err == nil {
-		// Delete corresponding price records
-		if err := tx.Where("item_name = ?", barang.Nama).Delete(&models.Price{}).Error; err != nil {
-			tx.Rollback()
-			return c.Status(500).JSON(fiber.Map{"error": "Gagal hapus price terkait", "detail": err.Error()})
-		}
-
-		// Delete price history
-		if err := tx.Where("item_name = ?", barang.Nama).Delete(&models.PriceHistory{}).Error; err != nil {
-			tx.Rollback()
-			return c.Status(500).JSON(fiber.Map{"error": "Gagal hapus price history terkait", "detail": err.Error()})
-		}
+	err := database.WithRetry(txMaxAttempts, func() error {
+		return database.WithTransaction(func(tx *gorm.DB) error {
+			var barang models.Barang
+			if err := tx.First(&barang, "id_barang = ?", id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(404, "Barang tidak ditemukan")
+				}
+				return txError(500, "Gagal mengambil barang", err)
+			}
+
+			deletedAt := time.Now().UTC()
+			if err := tx.Model(&models.Price{}).Where("name_key = ?", barang.NameKey).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+				return txError(500, "Gagal hapus price terkait", err)
+			}
+			if err := tx.Model(&barang).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+				return txError(500, "Gagal hapus barang", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Gagal commit")
 	}
 
-	// Hapus barang
-	result := tx.Unscoped().Where("id_barang = ?", id).Delete(&models.Barang{})
-	fmt.Println("📦 Rows affected:", result.RowsAffected)
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Barang dipindahkan ke trash",
+	})
+}
 
-	if result.Error != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal hapus barang", "detail": result.Error.Error()})
-	}
-	if result.RowsAffected == 0 {
-		tx.Rollback()
-		return c.Status(404).JSON(fiber.Map{"error": "Barang tidak ditemukan"})
-	}
+// DeleteBarangPermanent menghapus barang (termasuk yang ada di trash) beserta histori, harga dan
+// histori harga dengan nama yang sama secara permanen
+func DeleteBarangPermanent(c *fiber.Ctx) error {
+	id := c.Params("id")
 
-	if err := tx.Commit().Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal commit", "detail": err.Error()})
+	// Hapus lintas tabel rawan deadlock saat ada penulisan bersamaan, jadi diulang bila perlu
+	err := database.WithRetry(txMaxAttempts, func() error {
+		return database.WithTransaction(func(tx *gorm.DB) error {
+			// Hapus history
+			if err := tx.Unscoped().Where("barang_id = ?", id).Delete(&models.BarangHistory{}).Error; err != nil {
+				return txError(500, "Gagal hapus history", err)
+			}
+
+			// Find the barang to get its name before deleting
+			var barang models.Barang
+			if err := tx.Unscoped().First(&barang, "id_barang = ?", id).Error; err == nil {
+				// Delete price history, lewat item_id harga terkait karena histori tidak punya name_key
+				var itemIDs []uint
+				if err := tx.Unscoped().Model(&models.Price{}).Where("name_key = ?", barang.NameKey).Pluck("item_id", &itemIDs).Error; err != nil {
+					return txError(500, "Gagal hapus price history terkait", err)
+				}
+				if len(itemIDs) > 0 {
+					if err := tx.Where("item_id IN ?", itemIDs).Delete(&models.PriceHistory{}).Error; err != nil {
+						return txError(500, "Gagal hapus price history terkait", err)
+					}
+				}
+
+				// Delete corresponding price records
+				if err := tx.Unscoped().Where("name_key = ?", barang.NameKey).Delete(&models.Price{}).Error; err != nil {
+					return txError(500, "Gagal hapus price terkait", err)
+				}
+			}
+
+			// Hapus barang
+			result := tx.Unscoped().Where("id_barang = ?", id).Delete(&models.Barang{})
+			logger.FromCtx(c).Debug("hapus barang", "barang_id", id, "rows_affected", result.RowsAffected)
+
+			if result.Error != nil {
+				return txError(500, "Gagal hapus barang", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(404, "Barang tidak ditemukan")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Gagal commit")
 	}
 
 	return c.JSON(fiber.Map{
@@ -227,49 +389,23 @@ func GetBarangHistory(c *fiber.Ctx) error {
 	return c.JSON(history)
 }
 
+// barangByMarketQuery memilih barang yang kategorinya terhubung dengan pasar lewat tabel
+// category_markets (relasi many2many Category.Markets), sama seperti GetCategoriesByMarketID
+func barangByMarketQuery(marketID string) *gorm.DB {
+	categoryIDs := database.DB.
+		Table("category_markets").
+		Select("category_id").
+		Where("market_id = ?", marketID)
+
+	return database.DB.Model(&models.Barang{}).Where("category_id IN (?)", categoryIDs)
+}
+
 // GetBarangByMarketID menampilkan semua barang yang berasal dari pasar tertentu
-// GetBarangByMarketID menampilkan semua barang yang berasal dari pasar tertentu
-// func GetBarangByMarketID(c *fiber.Ctx) error {
-// 	marketID := c.Params("marketId")
-
-// 	// First get all categories for this market
-// 	var categories []models.Category
-// 	if err := database.DB.
-// 		Where("JSON_CONTAINS(market_ids, ?)", marketID).
-// 		Find(&categories).Error; err != nil {
-// 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil kategori berdasarkan market"})
-// 	}
-
-// 	// If no categories found, return empty array
-// 	if len(categories) == 0 {
-// 		return c.JSON([]models.Barang{})
-// 	}
-
-// 	// Extract category IDs
-// 	var categoryIDs []uint
-// 	for _, category := range categories {
-// 		categoryIDs = append(categoryIDs, category.ID)
-// 	}
-
-// 	// Get all products for these categories
-// 	var barang []models.Barang
-// 	if err := database.DB.
-// 		Where("category_id IN ?", categoryIDs).
-// 		Preload("Category").
-// 		Find(&barang).Error; err != nil {
-// 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data barang berdasarkan kategori"})
-// 	}
-
-//		return c.JSON(barang)
-//	}
 func GetBarangByMarketID(c *fiber.Ctx) error {
 	marketID := c.Params("marketId")
 
 	var barang []models.Barang
-	if err := database.DB.
-		Joins("JOIN categories ON categories.id = barangs.category_id").
-		Joins("JOIN category_markets ON category_markets.category_id = categories.id").
-		Where("category_markets.market_id = ?", marketID).
+	if err := barangByMarketQuery(marketID).
 		Preload("Category").
 		Find(&barang).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data barang berdasarkan market"})
@@ -279,29 +415,27 @@ func GetBarangByMarketID(c *fiber.Ctx) error {
 }
 func GetBarangByMarketIDPaginated(c *fiber.Ctx) error {
 	marketID := c.Params("marketId")
-	page := c.QueryInt("page", 1)
-	limit := c.QueryInt("limit", 10)
+	pagination := parsePagination(c, 10)
 
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 {
-		limit = 10
+	query := barangByMarketQuery(marketID).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung data barang"})
 	}
-	offset := (page - 1) * limit
 
 	var barang []models.Barang
-	result := database.DB.
-		Joins("JOIN categories ON categories.id = barangs.category_id").
-		Where("categories.market_id = ?", marketID).
+	result := query.
 		Preload("Category").
-		Limit(limit).
-		Offset(offset).
+		Limit(pagination.Limit).
+		Offset(pagination.Offset()).
 		Find(&barang)
 
 	if result.Error != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data barang dengan pagination"})
 	}
 
-	return c.JSON(barang)
+	meta := writePagination(c, pagination, total)
+	meta["data"] = barang
+	return c.JSON(meta)
 }
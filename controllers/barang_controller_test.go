@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// seedMarketCatalog membuat dua pasar dengan kategori masing-masing lewat category_markets:
+// Sembako ada di kedua pasar, Sayur hanya di Pasar Baru, Ikan hanya di Pasar Lama
+func seedMarketCatalog(t *testing.T, db *gorm.DB) (baru, lama models.Market) {
+	t.Helper()
+	baru = models.Market{Name: "Pasar Baru", Location: "Kota"}
+	lama = models.Market{Name: "Pasar Lama", Location: "Desa"}
+	mustCreate(t, db, &baru, &lama)
+
+	sembako := models.Category{Name: "Sembako", Markets: []models.Market{baru, lama}}
+	sayur := models.Category{Name: "Sayur", Markets: []models.Market{baru}}
+	ikan := models.Category{Name: "Ikan", Markets: []models.Market{lama}}
+	mustCreate(t, db, &sembako, &sayur, &ikan)
+
+	mustCreate(t, db,
+		&models.Barang{Nama: "Beras", CategoryID: &sembako.ID},
+		&models.Barang{Nama: "Gula", CategoryID: &sembako.ID},
+		&models.Barang{Nama: "Bayam", CategoryID: &sayur.ID},
+		&models.Barang{Nama: "Bandeng", CategoryID: &ikan.ID},
+		&models.Barang{Nama: "Tanpa Kategori"},
+	)
+	return baru, lama
+}
+
+func barangNames(barang []models.Barang) []string {
+	names := make([]string, 0, len(barang))
+	for _, b := range barang {
+		names = append(names, b.Nama)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestGetBarangByMarketIDOnSQLite(t *testing.T) {
+	db := useTestDB(t)
+	baru, lama := seedMarketCatalog(t, db)
+
+	app := fiber.New()
+	app.Get("/api/barang/market/:marketId", GetBarangByMarketID)
+
+	tests := []struct {
+		marketID uint
+		want     []string
+	}{
+		{baru.ID, []string{"Bayam", "Beras", "Gula"}},
+		{lama.ID, []string{"Bandeng", "Beras", "Gula"}},
+		{999, []string{}},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/barang/market/"+strconv.FormatUint(uint64(tt.marketID), 10), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != 200 {
+			t.Fatalf("market %d: status = %d", tt.marketID, resp.StatusCode)
+		}
+		var barang []models.Barang
+		if err := json.NewDecoder(resp.Body).Decode(&barang); err != nil {
+			t.Fatal(err)
+		}
+		if got := barangNames(barang); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("market %d: barang = %v, want %v", tt.marketID, got, tt.want)
+		}
+		for _, b := range barang {
+			if b.Category.ID == 0 {
+				t.Errorf("barang %s dikirim tanpa kategori", b.Nama)
+			}
+		}
+	}
+}
+
+func TestGetBarangByMarketIDPaginatedOnSQLite(t *testing.T) {
+	db := useTestDB(t)
+	baru, _ := seedMarketCatalog(t, db)
+
+	app := fiber.New()
+	app.Get("/api/barang/market/:marketId/paginated", GetBarangByMarketIDPaginated)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/barang/market/"+strconv.FormatUint(uint64(baru.ID), 10)+"/paginated?limit=2&page=2", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		Total int64           `json:"total"`
+		Data  []models.Barang `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || body.Total != 3 || len(body.Data) != 1 {
+		t.Errorf("status = %d, total = %d, len(data) = %d, want 200, 3, 1", resp.StatusCode, body.Total, len(body.Data))
+	}
+}
+
+func TestBarangByMarketQuery(t *testing.T) {
+	db := useTestDB(t)
+	baru, lama := seedMarketCatalog(t, db)
+
+	// Barang yang dihapus tidak ikut, walaupun kategorinya terhubung ke pasar
+	var gula models.Barang
+	if err := db.Where("nama = ?", "Gula").First(&gula).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&gula).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		marketID string
+		want     []string
+	}{
+		{"pasar dengan kategori sendiri dan bersama", strconv.FormatUint(uint64(baru.ID), 10), []string{"Bayam", "Beras"}},
+		{"pasar lain tidak ikut kategori Sayur", strconv.FormatUint(uint64(lama.ID), 10), []string{"Bandeng", "Beras"}},
+		{"pasar tanpa kategori", "999", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var barang []models.Barang
+			if err := barangByMarketQuery(tt.marketID).Find(&barang).Error; err != nil {
+				t.Fatal(err)
+			}
+			if got := barangNames(barang); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("barang = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateBarangMarket(t *testing.T) {
+	db := useTestDB(t)
+	baru := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	lama := models.Market{Name: "Pasar Lama", Location: "Desa"}
+	mustCreate(t, db, &baru, &lama)
+	category := models.Category{Name: "Sembako"}
+	mustCreate(t, db, &category)
+
+	tests := []struct {
+		name       string
+		role       string
+		marketID   uint
+		categoryID uint
+		wantStatus int
+		wantMarket uint
+	}{
+		{"market_id kosong tetap di pasar lama", models.OfficerRoleOfficer, 0, category.ID, 200, baru.ID},
+		{"admin memindahkan pasar", models.OfficerRoleAdmin, lama.ID, category.ID, 200, lama.ID},
+		{"petugas tidak boleh memindahkan ke pasar lain", models.OfficerRoleOfficer, lama.ID, category.ID, 403, baru.ID},
+		{"pasar tidak ada", models.OfficerRoleAdmin, 999, category.ID, 400, baru.ID},
+		{"kategori tidak ada", models.OfficerRoleAdmin, lama.ID, 999, 400, baru.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			barang := models.Barang{Nama: "Beras " + tt.name, CategoryID: &category.ID, MarketID: baru.ID, HargaSekarang: 12000}
+			mustCreate(t, db, &barang)
+
+			app := fiber.New()
+			app.Put("/api/barang/:id", func(c *fiber.Ctx) error {
+				c.Locals("role", tt.role)
+				c.Locals("market_id", uint64(baru.ID))
+				return c.Next()
+			}, UpdateBarang)
+
+			body := fmt.Sprintf(`{"nama":%q,"category_id":%d,"market_id":%d,"harga_pedagang1":13000,"harga_pedagang2":13000,"harga_pedagang3":13000}`,
+				barang.Nama, tt.categoryID, tt.marketID)
+			req := httptest.NewRequest("PUT", "/api/barang/"+strconv.FormatUint(barang.IdBarang, 10), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			var stored models.Barang
+			if err := db.First(&stored, "id_barang = ?", barang.IdBarang).Error; err != nil {
+				t.Fatal(err)
+			}
+			if stored.MarketID != tt.wantMarket {
+				t.Errorf("market_id = %d, want %d", stored.MarketID, tt.wantMarket)
+			}
+
+			// Request yang gagal tidak boleh meninggalkan histori atau perubahan harga
+			var histories int64
+			db.Model(&models.BarangHistory{}).Where("barang_id = ?", barang.IdBarang).Count(&histories)
+			wantHistories, wantPrice := int64(1), 13000.0
+			if tt.wantStatus != 200 {
+				wantHistories, wantPrice = 0, 12000
+			}
+			if histories != wantHistories || stored.HargaSekarang != wantPrice {
+				t.Errorf("histori = %d, harga_sekarang = %v, want %d dan %v", histories, stored.HargaSekarang, wantHistories, wantPrice)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// barangPatchFields adalah whitelist field yang boleh diubah lewat PATCH /api/barang/:id.
+// id_barang, market_id dan kolom harga turunan (harga_sekarang, harga_sebelumnya) tidak bisa diubah.
+var barangPatchFields = map[string]bool{
+	"nama":             true,
+	"satuan":           true,
+	"harga_pedagang1":  true,
+	"harga_pedagang2":  true,
+	"harga_pedagang3":  true,
+	"category_id":      true,
+	"alasan_perubahan": true,
+}
+
+type barangPatchInput struct {
+	Nama            *string  `json:"nama" validate:"omitempty,max=255"`
+	Satuan          *string  `json:"satuan" validate:"omitempty,max=50"`
+	HargaPedagang1  *float64 `json:"harga_pedagang1" validate:"omitempty,gte=0"`
+	HargaPedagang2  *float64 `json:"harga_pedagang2" validate:"omitempty,gte=0"`
+	HargaPedagang3  *float64 `json:"harga_pedagang3" validate:"omitempty,gte=0"`
+	CategoryID      *uint    `json:"category_id"`
+	AlasanPerubahan *string  `json:"alasan_perubahan" validate:"omitempty,max=255"`
+}
+
+// PatchBarang mengubah sebagian field barang. Hanya field yang dikirim yang diubah;
+// harga sekarang dihitung ulang (dan histori dicatat) hanya bila harga pedagang berubah.
+// category_id bernilai null melepas kategori.
+func PatchBarang(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var barang models.Barang
+	if err := database.DB.First(&barang, "id_barang = ?", id).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Barang not found"})
+	}
+	if !canWriteMarket(c, uint64(barang.MarketID)) {
+		return c.Status(403).JSON(fiber.Map{"error": "Akses ditolak untuk market ini"})
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &fields); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input format"})
+	}
+	if len(fields) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Tidak ada field yang diubah"})
+	}
+	var rejected []string
+	for field := range fields {
+		if !barangPatchFields[field] {
+			rejected = append(rejected, field)
+		}
+	}
+	if len(rejected) > 0 {
+		sort.Strings(rejected)
+		return c.Status(400).JSON(fiber.Map{"error": "Field tidak bisa diubah: " + strings.Join(rejected, ", ")})
+	}
+
+	var input barangPatchInput
+	if err := json.Unmarshal(c.Body(), &input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input format"})
+	}
+	if ok, err := validateInput(c, &input); !ok {
+		return err
+	}
+	avgMode, err := parseAvgMode(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	updates := map[string]interface{}{}
+	if input.Nama != nil {
+		nama := strings.TrimSpace(*input.Nama)
+		if nama == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "nama tidak boleh kosong"})
+		}
+		updates["nama"] = nama
+		// Updates dengan map tidak melewati hook BeforeSave, jadi name_key diisi manual
+		updates["name_key"] = models.NameKey(nama)
+	}
+	if input.Satuan != nil {
+		updates["satuan"] = *input.Satuan
+	}
+	if input.AlasanPerubahan != nil {
+		updates["alasan_perubahan"] = *input.AlasanPerubahan
+	}
+	if _, ok := fields["category_id"]; ok {
+		if input.CategoryID != nil {
+			var category models.Category
+			if err := database.DB.First(&category, *input.CategoryID).Error; err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Category ID %d not found", *input.CategoryID)})
+			}
+		}
+		updates["category_id"] = input.CategoryID
+	}
+
+	merchantPrices := []*float64{&barang.HargaPedagang1, &barang.HargaPedagang2, &barang.HargaPedagang3}
+	merchantChanged := false
+	for i, value := range []*float64{input.HargaPedagang1, input.HargaPedagang2, input.HargaPedagang3} {
+		if value == nil {
+			continue
+		}
+		*merchantPrices[i] = roundPrice(*value)
+		updates[fmt.Sprintf("harga_pedagang%d", i+1)] = *merchantPrices[i]
+		merchantChanged = true
+	}
+	updates["updated_by"] = currentOfficerID(c)
+
+	err = database.WithTransaction(func(tx *gorm.DB) error {
+		if merchantChanged {
+			newPrice := averageMerchantPrices(avgMode, barang.HargaPedagang1, barang.HargaPedagang2, barang.HargaPedagang3)
+			if !pricesEqual(newPrice, barang.HargaSekarang) {
+				history := models.BarangHistory{
+					BarangID:       barang.IdBarang,
+					HargaPedagang1: barang.HargaPedagang1,
+					HargaPedagang2: barang.HargaPedagang2,
+					HargaPedagang3: barang.HargaPedagang3,
+					HargaSekarang:  barang.HargaSekarang,
+					TanggalUpdate:  time.Now(),
+				}
+				if err := tx.Create(&history).Error; err != nil {
+					return fiber.NewError(500, "Failed to save price history")
+				}
+
+				updates["harga_sebelumnya"] = barang.HargaSekarang
+				updates["harga_sekarang"] = newPrice
+				updates["tanggal_update"] = time.Now().UTC()
+			}
+		}
+
+		if err := tx.Model(&models.Barang{}).Where("id_barang = ?", barang.IdBarang).Updates(updates).Error; err != nil {
+			return fiber.NewError(500, "Failed to update barang")
+		}
+
+		// Sync with price table
+		if err := SyncBarangWithPrice(barang.IdBarang, tx); err != nil {
+			return fiber.NewError(500, fmt.Sprintf("Failed to sync with price: %v", err))
+		}
+		return tx.First(&barang, "id_barang = ?", barang.IdBarang).Error
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to update barang")
+	}
+
+	return c.JSON(barang)
+}
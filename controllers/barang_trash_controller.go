@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TrashedBarang adalah barang di trash beserta waktu penghapusannya
+type TrashedBarang struct {
+	models.Barang
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// GetBarangTrash menampilkan barang yang sudah di-soft delete, terbaru dihapus dulu, dengan pagination
+func GetBarangTrash(c *fiber.Ctx) error {
+	pagination := parsePagination(c, 20)
+	query := database.DB.Unscoped().Model(&models.Barang{}).Where("deleted_at IS NOT NULL").Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung data barang"})
+	}
+
+	var barang []models.Barang
+	if err := query.
+		Preload("Category").
+		Order("deleted_at DESC, id_barang DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset()).
+		Find(&barang).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data barang"})
+	}
+
+	rows := make([]TrashedBarang, 0, len(barang))
+	for _, b := range barang {
+		rows = append(rows, TrashedBarang{Barang: b, DeletedAt: b.DeletedAt.Time})
+	}
+
+	meta := writePagination(c, pagination, total)
+	meta["data"] = rows
+	return c.JSON(meta)
+}
+
+// RestoreBarang mengembalikan barang dari trash bersama harga yang ikut dihapus oleh DeleteBarang
+// (nama sama dan deleted_at sama persis)
+func RestoreBarang(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var barang models.Barang
+	err := database.WithTransaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&barang, "id_barang = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(404, "Barang yang dihapus dengan ID tersebut tidak ditemukan")
+			}
+			return txError(500, "Gagal mengambil barang", err)
+		}
+
+		if err := tx.Unscoped().Model(&models.Price{}).
+			Where("name_key = ? AND deleted_at = ?", barang.NameKey, barang.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return txError(500, "Gagal memulihkan harga terkait", err)
+		}
+		if err := tx.Unscoped().Model(&barang).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return txError(500, "Gagal memulihkan barang", err)
+		}
+		return tx.Preload("Category").First(&barang, "id_barang = ?", barang.IdBarang).Error
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Gagal memulihkan barang")
+	}
+
+	return c.JSON(fiber.Map{"message": "Barang berhasil dipulihkan", "barang": barang})
+}
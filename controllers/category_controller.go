@@ -2,16 +2,18 @@ package controllers
 
 import (
 	"backend/database"
+	"backend/logger"
 	"backend/models"
-	"log"
+	"errors"
 	"strconv"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// Ambil semua kategori
+// Ambil semua kategori, dengan ?tree=true subkategori disusun di dalam children induknya
 func GetCategories(c *fiber.Ctx) error {
 	var categories []models.Category
 	if err := database.DB.
@@ -19,9 +21,75 @@ func GetCategories(c *fiber.Ctx) error {
 		Find(&categories).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch categories"})
 	}
+	if c.QueryBool("tree") {
+		return c.JSON(buildCategoryTree(categories))
+	}
 	return c.JSON(categories)
 }
 
+// buildCategoryTree menyusun daftar kategori datar menjadi pohon berdasarkan ParentID.
+// Kategori yang induknya tidak ada di daftar diperlakukan sebagai akar.
+func buildCategoryTree(categories []models.Category) []models.Category {
+	known := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+
+	children := make(map[uint][]models.Category)
+	roots := []models.Category{}
+	for _, category := range categories {
+		if category.ParentID != nil && known[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var attach func(category models.Category) models.Category
+	attach = func(category models.Category) models.Category {
+		category.Children = make([]models.Category, 0, len(children[category.ID]))
+		for _, child := range children[category.ID] {
+			category.Children = append(category.Children, attach(child))
+		}
+		return category
+	}
+
+	tree := make([]models.Category, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, attach(root))
+	}
+	return tree
+}
+
+// Batas kedalaman saat menelusuri induk kategori, melindungi dari data lama yang sudah berputar
+const maxCategoryDepth = 100
+
+var errCategoryParentNotFound = errors.New("kategori induk tidak ditemukan")
+
+// categoryParentCreatesCycle bernilai true bila parentID adalah kategori itu sendiri atau
+// salah satu keturunannya, sehingga memasangnya sebagai induk akan membentuk siklus
+func categoryParentCreatesCycle(db *gorm.DB, categoryID, parentID uint) (bool, error) {
+	current := parentID
+	for depth := 0; depth < maxCategoryDepth; depth++ {
+		if current == categoryID {
+			return true, nil
+		}
+
+		var category models.Category
+		if err := db.Select("id", "parent_id").First(&category, current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, errCategoryParentNotFound
+			}
+			return false, err
+		}
+		if category.ParentID == nil {
+			return false, nil
+		}
+		current = *category.ParentID
+	}
+	return true, nil
+}
+
 // Get categories by market
 func GetCategoriesByMarket(c *fiber.Ctx) error {
 	marketID, err := strconv.ParseUint(c.Params("market_id"), 10, 64)
@@ -60,6 +128,121 @@ func GetCategoriesByMarketID(c *fiber.Ctx) error {
 	return c.JSON(categories)
 }
 
+// GetMarketsByCategoryID menampilkan pasar yang terhubung dengan kategori lewat category_markets
+func GetMarketsByCategoryID(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var category models.Category
+	if err := database.DB.First(&category, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	var markets []models.Market
+	if err := database.DB.
+		Joins("JOIN category_markets ON markets.id = category_markets.market_id").
+		Where("category_markets.category_id = ?", category.ID).
+		Find(&markets).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	response := make([]MarketResponse, 0, len(markets))
+	for _, market := range markets {
+		response = append(response, MarketResponse{
+			ID:        market.ID,
+			Name:      market.Name,
+			Location:  market.Location,
+			ImageURL:  market.ImageURL,
+			Latitude:  market.Latitude,
+			Longitude: market.Longitude,
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// LinkCategoryToAllMarkets menghubungkan kategori ke semua pasar aktif yang belum terhubung
+func LinkCategoryToAllMarkets(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var category models.Category
+	if err := database.DB.First(&category, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	var added int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var marketIDs []uint
+		if err := tx.Model(&models.Market{}).
+			Where("id NOT IN (?)", tx.Model(&models.CategoryMarket{}).Select("market_id").Where("category_id = ?", category.ID)).
+			Pluck("id", &marketIDs).Error; err != nil {
+			return err
+		}
+
+		if len(marketIDs) == 0 {
+			return nil
+		}
+
+		links := make([]models.CategoryMarket, 0, len(marketIDs))
+		for _, marketID := range marketIDs {
+			links = append(links, models.CategoryMarket{CategoryID: category.ID, MarketID: marketID})
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links)
+		added = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		logger.FromCtx(c).Error("gagal menghubungkan kategori ke semua pasar", "category_id", category.ID, "error", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghubungkan kategori ke semua pasar"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Kategori berhasil dihubungkan ke semua pasar",
+		"added":   added,
+	})
+}
+
+// GetCategorySummaryByMarket menampilkan kategori yang terhubung dengan pasar beserta jumlah barangnya
+func GetCategorySummaryByMarket(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var market models.Market
+	if err := database.DB.First(&market, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(404).JSON(fiber.Map{"error": "Market not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	type CategorySummary struct {
+		ID          uint   `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		BarangCount int64  `json:"barang_count"`
+	}
+
+	// Barang tanpa market_id (data lama) dihitung lewat relasi kategori -> pasar
+	summaries := []CategorySummary{}
+	if err := database.DB.Model(&models.Category{}).
+		Select("categories.id, categories.name, categories.description, COUNT(barangs.id_barang) AS barang_count").
+		Joins("JOIN category_markets ON categories.id = category_markets.category_id").
+		Joins("LEFT JOIN barangs ON barangs.category_id = categories.id AND barangs.deleted_at IS NULL AND (barangs.market_id = ? OR barangs.market_id = 0)", market.ID).
+		Where("category_markets.market_id = ?", market.ID).
+		Group("categories.id, categories.name, categories.description").
+		Order("categories.name ASC").
+		Scan(&summaries).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+
+	return c.JSON(summaries)
+}
+
 // Ambil kategori berdasarkan ID
 func GetCategoryByID(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -85,8 +268,9 @@ func GetCategoryByID(c *fiber.Ctx) error {
 // Tambah kategori baru
 func CreateCategory(c *fiber.Ctx) error {
 	type CategoryInput struct {
-		Name        string `json:"name"`
+		Name        string `json:"name" validate:"required,notblank,max=255"`
 		Description string `json:"description"`
+		ParentID    *uint  `json:"parent_id"`
 		MarketIDs   []uint `json:"market_ids"`
 	}
 
@@ -94,6 +278,9 @@ func CreateCategory(c *fiber.Ctx) error {
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
+	if ok, err := validateInput(c, &input); !ok {
+		return err
+	}
 
 	// 🔴 Pindahkan validasi DUPLIKAT ke atas, sebelum INSERT!
 	var existing models.Category
@@ -105,10 +292,21 @@ func CreateCategory(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Error checking existing category"})
 	}
 
+	if input.ParentID != nil {
+		var parent models.Category
+		if err := database.DB.Select("id").First(&parent, *input.ParentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(400).JSON(fiber.Map{"error": "Kategori induk tidak ditemukan"})
+			}
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil kategori induk"})
+		}
+	}
+
 	// ✅ Jika aman, baru simpan kategori
 	category := models.Category{
 		Name:        input.Name,
 		Description: input.Description,
+		ParentID:    input.ParentID,
 	}
 
 	if err := database.DB.Create(&category).Error; err != nil {
@@ -130,8 +328,9 @@ func CreateCategory(c *fiber.Ctx) error {
 func UpdateCategory(c *fiber.Ctx) error {
 
 	type CategoryInput struct {
-		Name        string `json:"name"`
+		Name        string `json:"name" validate:"required,notblank,max=255"`
 		Description string `json:"description"`
+		ParentID    *uint  `json:"parent_id"`
 		MarketIDs   []uint `json:"market_ids"`
 	}
 
@@ -146,17 +345,34 @@ func UpdateCategory(c *fiber.Ctx) error {
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
+	if ok, err := validateInput(c, &input); !ok {
+		return err
+	}
+
+	// Induk baru tidak boleh kategori itu sendiri atau keturunannya
+	if input.ParentID != nil {
+		cycle, err := categoryParentCreatesCycle(database.DB, category.ID, *input.ParentID)
+		if errors.Is(err, errCategoryParentNotFound) {
+			return c.Status(400).JSON(fiber.Map{"error": "Kategori induk tidak ditemukan"})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa kategori induk"})
+		}
+		if cycle {
+			return c.Status(400).JSON(fiber.Map{"error": "Kategori induk tidak boleh kategori ini sendiri atau subkategorinya"})
+		}
+	}
 
 	category.Name = input.Name
 	category.Description = input.Description
+	category.ParentID = input.ParentID
 
 	if err := database.DB.Save(&category).Error; err != nil {
-		log.Printf("❌ Gagal menyimpan kategori ID %v: %v\n", category.ID, err) // ✅ log error nyata
+		logger.FromCtx(c).Error("gagal menyimpan kategori", "category_id", category.ID, "error", err)
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update category"})
 	}
 
-	log.Printf("📥 Parsed input: %+v", input)
-	log.Printf("✅ Parsed market IDs: %+v", input.MarketIDs)
+	logger.FromCtx(c).Debug("update kategori", "category_id", category.ID, "market_ids", input.MarketIDs)
 
 	// Hapus relasi lama, simpan ulang yang baru
 	database.DB.Where("category_id = ?", category.ID).Delete(&models.CategoryMarket{})
@@ -175,8 +391,6 @@ func UpdateCategory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Nama kategori sudah digunakan"})
 	}
 
-	log.Printf("📥 Raw body: %v", c.Body())
-
 	return c.JSON(category)
 }
 
@@ -214,6 +428,11 @@ func DeleteCategory(c *fiber.Ctx) error {
         return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus harga terkait"})
     }
 
+    // Subkategori naik menjadi kategori utama agar tidak menunjuk ke induk yang sudah dihapus
+    if err := database.DB.Model(&models.Category{}).Where("parent_id = ?", categoryID).Update("parent_id", nil).Error; err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Gagal melepas subkategori"})
+    }
+
     if err := database.DB.Delete(&models.Category{}, categoryID).Error; err != nil {
         return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus kategori"})
     }
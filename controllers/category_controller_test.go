@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestLinkCategoryToAllMarkets(t *testing.T) {
+	db := useTestDB(t)
+	baru, lama := seedMarketCatalog(t, db)
+	ketiga := models.Market{Name: "Pasar Ketiga", Location: "Pinggir"}
+	hapus := models.Market{Name: "Pasar Tutup", Location: "Pinggir"}
+	mustCreate(t, db, &ketiga, &hapus)
+	db.Delete(&hapus)
+
+	var sayur models.Category
+	if err := db.Where("name = ?", "Sayur").First(&sayur).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Post("/api/categories/:id/link-all-markets", LinkCategoryToAllMarkets)
+	link := func() float64 {
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/categories/"+strconv.FormatUint(uint64(sayur.ID), 10)+"/link-all-markets", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != 200 {
+			t.Fatalf("status = %d (%v)", resp.StatusCode, body)
+		}
+		added, _ := body["added"].(float64)
+		return added
+	}
+
+	// Sayur sudah ada di Pasar Baru, pasar yang dihapus dilewati
+	if added := link(); added != 2 {
+		t.Errorf("added = %v, want 2 (Pasar Lama dan Pasar Ketiga)", added)
+	}
+	if added := link(); added != 0 {
+		t.Errorf("panggilan kedua: added = %v, want 0", added)
+	}
+
+	var marketIDs []uint
+	db.Model(&models.CategoryMarket{}).Where("category_id = ?", sayur.ID).Order("market_id").Pluck("market_id", &marketIDs)
+	want := []uint{baru.ID, lama.ID, ketiga.ID}
+	if len(marketIDs) != len(want) {
+		t.Fatalf("market_ids = %v, want %v", marketIDs, want)
+	}
+	for i := range want {
+		if marketIDs[i] != want[i] {
+			t.Errorf("market_ids = %v, want %v", marketIDs, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	chartDefaultWidth  = 800
+	chartDefaultHeight = 400
+	chartMinWidth      = 200
+	chartMaxWidth      = 2000
+	chartMinHeight     = 150
+	chartMaxHeight     = 1200
+	chartPadding       = 40
+)
+
+var (
+	chartBackground = color.RGBA{255, 255, 255, 255}
+	chartAxis       = color.RGBA{120, 120, 120, 255}
+	chartGrid       = color.RGBA{230, 230, 230, 255}
+	chartLine       = color.RGBA{33, 150, 243, 255}
+	chartText       = color.RGBA{60, 60, 60, 255}
+)
+
+// GetPriceHistoryChart merender grafik garis current_price dari PriceHistory sebagai PNG
+func GetPriceHistoryChart(c *fiber.Ctx) error {
+	itemID := c.Params("item_id")
+
+	width := c.QueryInt("width", chartDefaultWidth)
+	height := c.QueryInt("height", chartDefaultHeight)
+	if width < chartMinWidth || width > chartMaxWidth || height < chartMinHeight || height > chartMaxHeight {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("width harus %d-%d dan height harus %d-%d", chartMinWidth, chartMaxWidth, chartMinHeight, chartMaxHeight),
+		})
+	}
+
+	dateRange, err := parseDateRange(c, "start_date", "end_date")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var histories []models.PriceHistory
+	if err := dateRange.Apply(database.DB.Where("item_id = ?", itemID), "created_at").
+		Order("created_at ASC, id ASC").
+		Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+
+	if len(histories) == 0 {
+		drawText(img, "NO DATA", width/2-len("NO DATA")*6, height/2-7, 2, chartText)
+	} else {
+		drawPriceChart(img, histories)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat gambar grafik"})
+	}
+
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Send(buf.Bytes())
+}
+
+func drawPriceChart(img *image.RGBA, histories []models.PriceHistory) {
+	bounds := img.Bounds()
+	left, right := chartPadding+20, bounds.Dx()-chartPadding
+	top, bottom := chartPadding, bounds.Dy()-chartPadding
+
+	minPrice, maxPrice := histories[0].CurrentPrice, histories[0].CurrentPrice
+	for _, h := range histories {
+		if h.CurrentPrice < minPrice {
+			minPrice = h.CurrentPrice
+		}
+		if h.CurrentPrice > maxPrice {
+			maxPrice = h.CurrentPrice
+		}
+	}
+	if pricesEqual(maxPrice, minPrice) {
+		maxPrice++
+		minPrice--
+	}
+
+	startTime := histories[0].CreatedAt
+	span := histories[len(histories)-1].CreatedAt.Sub(startTime).Seconds()
+
+	// Grid horizontal dan sumbu
+	for i := 0; i <= 4; i++ {
+		y := top + (bottom-top)*i/4
+		drawLine(img, left, y, right, y, chartGrid)
+	}
+	drawLine(img, left, top, left, bottom, chartAxis)
+	drawLine(img, left, bottom, right, bottom, chartAxis)
+	drawText(img, strconv.FormatFloat(maxPrice, 'f', 0, 64), 4, top-4, 1, chartText)
+	drawText(img, strconv.FormatFloat(minPrice, 'f', 0, 64), 4, bottom-4, 1, chartText)
+
+	point := func(i int) (int, int) {
+		x := left
+		if span > 0 {
+			x = left + int(histories[i].CreatedAt.Sub(startTime).Seconds()/span*float64(right-left))
+		} else if len(histories) > 1 {
+			x = left + (right-left)*i/(len(histories)-1)
+		}
+		y := bottom - int((histories[i].CurrentPrice-minPrice)/(maxPrice-minPrice)*float64(bottom-top))
+		return x, y
+	}
+
+	prevX, prevY := point(0)
+	fillRect(img, prevX-2, prevY-2, 5, 5, chartLine)
+	for i := 1; i < len(histories); i++ {
+		x, y := point(i)
+		drawLine(img, prevX, prevY, x, y, chartLine)
+		fillRect(img, x-2, y-2, 5, 5, chartLine)
+		prevX, prevY = x, y
+	}
+}
+
+// drawLine menggambar garis dengan algoritma Bresenham
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, col color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	errVal := dx + dy
+	for {
+		img.SetRGBA(x0, y0, col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * errVal
+		if e2 >= dy {
+			errVal += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			errVal += dx
+			y0 += sy
+		}
+	}
+}
+
+func fillRect(img *image.RGBA, x, y, w, h int, col color.RGBA) {
+	draw.Draw(img, image.Rect(x, y, x+w, y+h), &image.Uniform{col}, image.Point{}, draw.Src)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Font bitmap 5x7 sederhana untuk label angka dan placeholder "NO DATA"
+var chartGlyphs = map[rune][7]uint8{
+	'0': {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E},
+	'1': {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2': {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F},
+	'3': {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4': {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02},
+	'5': {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6': {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E},
+	'7': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E},
+	'9': {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+	'-': {0x00, 0x00, 0x00, 0x1F, 0x00, 0x00, 0x00},
+	'A': {0x0E, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'D': {0x1C, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1C},
+	'N': {0x11, 0x19, 0x15, 0x13, 0x11, 0x11, 0x11},
+	'O': {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'T': {0x1F, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+}
+
+func drawText(img *image.RGBA, text string, x, y, scale int, col color.RGBA) {
+	for _, r := range text {
+		glyph, ok := chartGlyphs[r]
+		if ok {
+			for row, bits := range glyph {
+				for column := 0; column < 5; column++ {
+					if bits&(1<<(4-column)) != 0 {
+						fillRect(img, x+column*scale, y+row*scale, scale, scale, col)
+					}
+				}
+			}
+		}
+		x += 6 * scale
+	}
+}
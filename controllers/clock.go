@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPriceResetHour adalah jam reset harga harian bila PRICE_RESET_HOUR kosong
+const DefaultPriceResetHour = 8
+
+// Zona waktu pasar dan jam reset harga harian, diisi ConfigureClock saat startup. Semua
+// batas hari (guard edit harian, pengelompokan histori per hari/minggu/bulan, parsing
+// tanggal dari query string) memakai zona ini, bukan zona waktu server.
+var (
+	appLocation    = time.Local
+	priceResetHour = DefaultPriceResetHour
+)
+
+// ConfigureClock membaca APP_TIMEZONE (nama IANA, mis. "Asia/Jakarta"; default zona waktu server)
+// dan PRICE_RESET_HOUR (0-23, default 8). Nilai yang tidak valid menghasilkan error.
+func ConfigureClock(getenv func(string) string) error {
+	location := time.Local
+	if name := strings.TrimSpace(getenv("APP_TIMEZONE")); name != "" {
+		loaded, err := time.LoadLocation(name)
+		if err != nil {
+			return fmt.Errorf("APP_TIMEZONE %q tidak dikenal: %w", name, err)
+		}
+		location = loaded
+	}
+
+	hour := DefaultPriceResetHour
+	if value := strings.TrimSpace(getenv("PRICE_RESET_HOUR")); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 23 {
+			return fmt.Errorf("PRICE_RESET_HOUR harus angka 0-23, dapat %q", value)
+		}
+		hour = parsed
+	}
+
+	appLocation = location
+	priceResetHour = hour
+	return nil
+}
+
+// startOfDay mengembalikan awal hari t di zona APP_TIMEZONE
+func startOfDay(t time.Time) time.Time {
+	t = t.In(appLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, appLocation)
+}
+
+// lastDailyReset mengembalikan jam reset harga terakhir (PRICE_RESET_HOUR di zona APP_TIMEZONE)
+// sebelum atau tepat pada now
+func lastDailyReset(now time.Time) time.Time {
+	now = now.In(appLocation)
+	reset := time.Date(now.Year(), now.Month(), now.Day(), priceResetHour, 0, 0, 0, appLocation)
+	if now.Before(reset) {
+		reset = reset.AddDate(0, 0, -1)
+	}
+	return reset
+}
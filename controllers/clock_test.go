@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// useClock memasang zona dan jam reset tetap selama test berjalan
+func useClock(t *testing.T, name string, resetHour int) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("tzdata %s tidak tersedia", name)
+	}
+	appLocation, priceResetHour = location, resetHour
+	t.Cleanup(func() { appLocation, priceResetHour = time.Local, DefaultPriceResetHour })
+	return location
+}
+
+func TestConfigureClock(t *testing.T) {
+	t.Cleanup(func() { appLocation, priceResetHour = time.Local, DefaultPriceResetHour })
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantZone string
+		wantHour int
+		wantErr  bool
+	}{
+		{"default", map[string]string{}, time.Local.String(), DefaultPriceResetHour, false},
+		{"zona dan jam kustom", map[string]string{"APP_TIMEZONE": "Asia/Jakarta", "PRICE_RESET_HOUR": "6"}, "Asia/Jakarta", 6, false},
+		{"jam nol", map[string]string{"PRICE_RESET_HOUR": "0"}, time.Local.String(), 0, false},
+		{"zona tidak dikenal", map[string]string{"APP_TIMEZONE": "Asia/Atlantis"}, "", 0, true},
+		{"jam di luar rentang", map[string]string{"PRICE_RESET_HOUR": "24"}, "", 0, true},
+		{"jam bukan angka", map[string]string{"PRICE_RESET_HOUR": "pagi"}, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appLocation, priceResetHour = time.UTC, 3
+			err := ConfigureClock(func(key string) string { return tt.env[key] })
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("err = nil, want error")
+				}
+				if appLocation != time.UTC || priceResetHour != 3 {
+					t.Error("konfigurasi lama tidak boleh berubah saat error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if appLocation.String() != tt.wantZone || priceResetHour != tt.wantHour {
+				t.Errorf("zona = %s, jam = %d, want %s, %d", appLocation, priceResetHour, tt.wantZone, tt.wantHour)
+			}
+		})
+	}
+}
+
+func TestLastDailyResetUsesAppTimezone(t *testing.T) {
+	jakarta := useClock(t, "Asia/Jakarta", 8)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"sebelum jam reset memakai reset kemarin", time.Date(2024, 5, 10, 7, 59, 0, 0, jakarta), time.Date(2024, 5, 9, 8, 0, 0, 0, jakarta)},
+		{"tepat jam reset", time.Date(2024, 5, 10, 8, 0, 0, 0, jakarta), time.Date(2024, 5, 10, 8, 0, 0, 0, jakarta)},
+		{"setelah jam reset", time.Date(2024, 5, 10, 23, 0, 0, 0, jakarta), time.Date(2024, 5, 10, 8, 0, 0, 0, jakarta)},
+		// 02:00 UTC sudah 09:00 WIB, jadi reset hari itu sudah lewat walau di UTC belum jam 8
+		{"waktu server UTC", time.Date(2024, 5, 10, 2, 0, 0, 0, time.UTC), time.Date(2024, 5, 10, 8, 0, 0, 0, jakarta)},
+		// 20:00 UTC tanggal 9 sudah tanggal 10 pukul 03:00 WIB, belum reset
+		{"tanggal UTC berbeda", time.Date(2024, 5, 9, 20, 0, 0, 0, time.UTC), time.Date(2024, 5, 9, 8, 0, 0, 0, jakarta)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastDailyReset(tt.now); !got.Equal(tt.want) {
+				t.Errorf("lastDailyReset(%s) = %s, want %s", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDailyEditBoundary(t *testing.T) {
+	jakarta := useClock(t, "Asia/Jakarta", 8)
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, jakarta)
+	reset := time.Date(2024, 5, 10, 8, 0, 0, 0, jakarta)
+
+	tests := []struct {
+		name       string
+		lastUpdate time.Time
+		wantErr    bool
+	}{
+		{"diubah kemarin", reset.AddDate(0, 0, -1).Add(time.Hour), false},
+		{"diubah tepat saat reset", reset, false},
+		{"diubah satu detik sebelum reset", reset.Add(-time.Second), false},
+		{"diubah satu detik setelah reset", reset.Add(time.Second), true},
+		{"diubah setelah reset, tersimpan dalam UTC", reset.Add(time.Minute).UTC(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDailyEdit(tt.lastUpdate, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error = %v", err, tt.wantErr)
+			}
+			// Reset berikutnya pukul 08:00 besok, 20 jam dari now
+			if err != nil && !strings.Contains(err.Error(), "20 jam") {
+				t.Errorf("pesan = %q, want menyebut 20 jam", err)
+			}
+		})
+	}
+}
+
+func TestStartOfDayUsesAppTimezone(t *testing.T) {
+	jakarta := useClock(t, "Asia/Jakarta", 8)
+
+	// 18:00 UTC tanggal 9 sudah tanggal 10 di WIB
+	got := startOfDay(time.Date(2024, 5, 9, 18, 0, 0, 0, time.UTC))
+	if want := time.Date(2024, 5, 10, 0, 0, 0, 0, jakarta); !got.Equal(want) {
+		t.Errorf("startOfDay = %s, want %s", got, want)
+	}
+}
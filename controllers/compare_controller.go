@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MarketPriceComparison struct {
+	MarketID        uint      `json:"market_id"`
+	MarketName      string    `json:"market_name"`
+	CurrentPrice    float64   `json:"current_price"`
+	Satuan          string    `json:"satuan"`
+	NormalizedPrice float64   `json:"normalized_price"`
+	Normalized      bool      `json:"normalized"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ComparePriceAcrossMarkets membandingkan harga satu komoditas di semua pasar. Harga
+// dinormalisasi ke satuan dasar yang sama (mis. per kg) sebelum diurutkan termurah dulu;
+// entri yang satuannya tidak bisa dinormalisasi ditandai dan diletakkan di akhir. Pasar yang
+// tidak menjual komoditas ini tidak ikut ditampilkan.
+func ComparePriceAcrossMarkets(c *fiber.Ctx) error {
+	itemName := strings.TrimSpace(c.Query("item_name"))
+	if itemName == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "item_name wajib diisi"})
+	}
+
+	var prices []models.Price
+	if err := database.DB.Preload("Market").
+		Where("LOWER(item_name) = LOWER(?)", itemName).
+		Order("updated_at DESC").
+		Find(&prices).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+	}
+
+	// Satuan diambil dari barang dengan nama yang sama, utamakan yang terikat ke pasar tersebut
+	var barangs []models.Barang
+	if err := database.DB.
+		Where("LOWER(nama) = LOWER(?)", itemName).
+		Find(&barangs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data barang"})
+	}
+	satuanByMarket := make(map[uint]string)
+	defaultSatuan := ""
+	for _, b := range barangs {
+		if b.MarketID != 0 {
+			satuanByMarket[b.MarketID] = b.Satuan
+		} else if defaultSatuan == "" {
+			defaultSatuan = b.Satuan
+		}
+	}
+
+	// Ambil harga terbaru untuk setiap pasar
+	seen := make(map[uint]bool)
+	comparisons := []MarketPriceComparison{}
+	baseUnitCount := make(map[string]int)
+	baseUnits := make([]string, 0, len(prices))
+	for _, p := range prices {
+		if seen[p.MarketID] || p.Market.ID == 0 {
+			continue
+		}
+		seen[p.MarketID] = true
+
+		satuan, ok := satuanByMarket[p.MarketID]
+		if !ok {
+			satuan = defaultSatuan
+		}
+
+		entry := MarketPriceComparison{
+			MarketID:     p.MarketID,
+			MarketName:   p.Market.Name,
+			CurrentPrice: p.CurrentPrice,
+			Satuan:       satuan,
+			UpdatedAt:    p.UpdatedAt,
+		}
+
+		baseUnit := ""
+		if normalized, unit, ok := normalizeUnitPrice(p.CurrentPrice, satuan); ok {
+			entry.NormalizedPrice = normalized
+			entry.Normalized = true
+			baseUnit = unit
+			baseUnitCount[unit]++
+		}
+
+		comparisons = append(comparisons, entry)
+		baseUnits = append(baseUnits, baseUnit)
+	}
+
+	// Satuan dasar yang paling banyak dipakai menjadi pembanding
+	commonUnit := ""
+	for unit, count := range baseUnitCount {
+		if count > baseUnitCount[commonUnit] || (count == baseUnitCount[commonUnit] && unit < commonUnit) {
+			commonUnit = unit
+		}
+	}
+	for i := range comparisons {
+		if comparisons[i].Normalized && baseUnits[i] != commonUnit {
+			comparisons[i].Normalized = false
+			comparisons[i].NormalizedPrice = 0
+		}
+	}
+
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		a, b := comparisons[i], comparisons[j]
+		if a.Normalized != b.Normalized {
+			return a.Normalized
+		}
+		if a.Normalized {
+			return a.NormalizedPrice < b.NormalizedPrice
+		}
+		return a.CurrentPrice < b.CurrentPrice
+	})
+
+	return c.JSON(fiber.Map{
+		"item_name": itemName,
+		"unit":      commonUnit,
+		"markets":   comparisons,
+		"summary":   comparePriceSummary(comparisons),
+	})
+}
+
+// PriceComparisonSummary merangkum harga termurah, termahal dan rata-rata antar pasar. Bila ada
+// harga yang bisa dinormalisasi, hanya harga ternormalisasi (Basis "normalized_price") yang
+// dihitung agar satuan yang berbeda tidak tercampur.
+type PriceComparisonSummary struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+	Basis string  `json:"basis"`
+}
+
+func comparePriceSummary(comparisons []MarketPriceComparison) PriceComparisonSummary {
+	summary := PriceComparisonSummary{Basis: "current_price"}
+	values := make([]float64, 0, len(comparisons))
+	for _, entry := range comparisons {
+		if entry.Normalized {
+			values = append(values, entry.NormalizedPrice)
+		}
+	}
+	if len(values) > 0 {
+		summary.Basis = "normalized_price"
+	} else {
+		for _, entry := range comparisons {
+			values = append(values, entry.CurrentPrice)
+		}
+	}
+	if len(values) == 0 {
+		return summary
+	}
+
+	summary.Count = len(values)
+	summary.Min, summary.Max = values[0], values[0]
+	sum := 0.0
+	for _, v := range values {
+		summary.Min = math.Min(summary.Min, v)
+		summary.Max = math.Max(summary.Max, v)
+		sum += v
+	}
+	summary.Avg = roundPrice(sum / float64(len(values)))
+	return summary
+}
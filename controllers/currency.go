@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"backend/models"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCurrency = "IDR"
+	defaultLocale   = "id-ID"
+)
+
+// currencySymbols memetakan kode mata uang ke simbol dan jumlah desimal yang lazim dipakai
+var currencySymbols = map[string]struct {
+	Symbol   string
+	Decimals int
+}{
+	"IDR": {"Rp", 0},
+	"USD": {"$", 2},
+	"SGD": {"S$", 2},
+	"MYR": {"RM", 2},
+}
+
+// localeSeparators memetakan locale ke pemisah ribuan dan desimal
+var localeSeparators = map[string]struct {
+	Thousands string
+	Decimal   string
+}{
+	"id-ID": {".", ","},
+	"en-US": {",", "."},
+	"en-SG": {",", "."},
+	"ms-MY": {",", "."},
+}
+
+func isSupportedCurrency(currency string) bool {
+	_, ok := currencySymbols[currency]
+	return ok
+}
+
+func isSupportedLocale(locale string) bool {
+	_, ok := localeSeparators[locale]
+	return ok
+}
+
+// formatCurrency memformat nilai sesuai mata uang dan locale pasar, mis. "Rp 15.000" atau "$ 1,250.50".
+// Desimal hanya ditampilkan bila nilainya tidak bulat atau mata uangnya memang memakai desimal.
+func formatCurrency(value float64, currency, locale string) string {
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currencySymbols[defaultCurrency]
+	}
+	separators, ok := localeSeparators[locale]
+	if !ok {
+		separators = localeSeparators[defaultLocale]
+	}
+
+	decimals := symbol.Decimals
+	if decimals == 0 && !pricesEqual(value, math.Round(value)) {
+		decimals = 2
+	}
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	formatted := strconv.FormatFloat(value, 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(formatted, ".")
+
+	var sb strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteString(separators.Thousands)
+		}
+		sb.WriteRune(digit)
+	}
+	if fraction != "" {
+		sb.WriteString(separators.Decimal)
+		sb.WriteString(fraction)
+	}
+
+	return sign + symbol.Symbol + " " + sb.String()
+}
+
+// applyPriceFormatting mengisi petunjuk mata uang dan locale dari pasar (Market harus sudah di-preload),
+// serta string harga terformat bila formatted bernilai true
+func applyPriceFormatting(prices []models.Price, formatted bool) {
+	for i := range prices {
+		p := &prices[i]
+		p.Currency = p.Market.Currency
+		if p.Currency == "" {
+			p.Currency = defaultCurrency
+		}
+		p.Locale = p.Market.Locale
+		if p.Locale == "" {
+			p.Locale = defaultLocale
+		}
+
+		if formatted {
+			p.FormattedInitialPrice = formatCurrency(p.InitialPrice, p.Currency, p.Locale)
+			p.FormattedCurrentPrice = formatCurrency(p.CurrentPrice, p.Currency, p.Locale)
+		}
+	}
+}
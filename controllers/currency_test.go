@@ -0,0 +1,31 @@
+package controllers
+
+import "testing"
+
+func TestFormatCurrency(t *testing.T) {
+	tests := []struct {
+		value    float64
+		currency string
+		locale   string
+		want     string
+	}{
+		{15000, "IDR", "id-ID", "Rp 15.000"},
+		{1250000, "IDR", "id-ID", "Rp 1.250.000"},
+		{999, "IDR", "id-ID", "Rp 999"},
+		{0, "IDR", "id-ID", "Rp 0"},
+		{15000.5, "IDR", "id-ID", "Rp 15.000,50"},
+		{-2500, "IDR", "id-ID", "-Rp 2.500"},
+		{1250.5, "USD", "en-US", "$ 1,250.50"},
+		{1000, "USD", "en-US", "$ 1,000.00"},
+		{1234567.891, "SGD", "en-SG", "S$ 1,234,567.89"},
+		{12.3, "MYR", "ms-MY", "RM 12.30"},
+		{1250.5, "USD", "id-ID", "$ 1.250,50"},
+		// Kode dan locale yang tidak dikenal jatuh ke default IDR dan id-ID
+		{15000, "XYZ", "xx-XX", "Rp 15.000"},
+	}
+	for _, tt := range tests {
+		if got := formatCurrency(tt.value, tt.currency, tt.locale); got != tt.want {
+			t.Errorf("formatCurrency(%v, %s, %s) = %q, want %q", tt.value, tt.currency, tt.locale, got, tt.want)
+		}
+	}
+}
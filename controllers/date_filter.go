@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Format tanggal yang diterima dari query string, dicoba berurutan
+var acceptedDateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"02-01-2006",
+	"02/01/2006",
+}
+
+// Layout tambahan bisa diberikan lewat env DATE_INPUT_LAYOUTS (dipisah koma, format Go)
+func init() {
+	for _, layout := range strings.Split(os.Getenv("DATE_INPUT_LAYOUTS"), ",") {
+		if layout = strings.TrimSpace(layout); layout != "" {
+			acceptedDateLayouts = append(acceptedDateLayouts, layout)
+		}
+	}
+}
+
+// DateRange adalah rentang tanggal yang sudah dinormalisasi: From inklusif, To eksklusif
+type DateRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// parseDate menerima tanggal dalam salah satu acceptedDateLayouts dan mengembalikan awal harinya
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range acceptedDateLayouts {
+		t, err := time.ParseInLocation(layout, value, appLocation)
+		if err == nil {
+			t = t.In(appLocation)
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, appLocation), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("format tanggal %q tidak dikenali, gunakan YYYY-MM-DD", value)
+}
+
+// parseTimestamp menerima waktu lengkap (RFC3339 atau "YYYY-MM-DD HH:MM:SS"), atau tanggal saja
+// yang dibaca sebagai awal hari tersebut
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, appLocation); err == nil {
+			return t, nil
+		}
+	}
+	return parseDate(value)
+}
+
+// parseDateRange membaca dua parameter tanggal dari query string. Tanggal akhir bersifat
+// inklusif sehingga To diset ke awal hari berikutnya.
+func parseDateRange(c *fiber.Ctx, startKey, endKey string) (DateRange, error) {
+	var dateRange DateRange
+
+	if value := c.Query(startKey); value != "" {
+		start, err := parseDate(value)
+		if err != nil {
+			return dateRange, fmt.Errorf("%s: %v", startKey, err)
+		}
+		dateRange.From = &start
+	}
+
+	if value := c.Query(endKey); value != "" {
+		end, err := parseDate(value)
+		if err != nil {
+			return dateRange, fmt.Errorf("%s: %v", endKey, err)
+		}
+		end = end.AddDate(0, 0, 1)
+		dateRange.To = &end
+	}
+
+	if dateRange.From != nil && dateRange.To != nil && !dateRange.From.Before(*dateRange.To) {
+		return dateRange, fmt.Errorf("%s tidak boleh setelah %s", startKey, endKey)
+	}
+
+	return dateRange, nil
+}
+
+// Apply menambahkan filter rentang tanggal pada kolom yang diberikan
+func (r DateRange) Apply(query *gorm.DB, column string) *gorm.DB {
+	if r.From != nil {
+		query = query.Where(column+" >= ?", *r.From)
+	}
+	if r.To != nil {
+		query = query.Where(column+" < ?", *r.To)
+	}
+	return query
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// useTestDB memasang database sqlite yang sudah dimigrasi sebagai database.DB selama test berjalan
+func useTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := dbtest.Open(t)
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return db
+}
+
+// mustCreate menyimpan data seed dan menghentikan test bila gagal
+func mustCreate(t *testing.T, db *gorm.DB, values ...any) {
+	t.Helper()
+	for _, value := range values {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("seed %T: %v", value, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type DigestItem struct {
+	ItemName      string  `json:"item_name"`
+	PreviousPrice float64 `json:"previous_price"`
+	CurrentPrice  float64 `json:"current_price"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// GetMarketDailyDigest merangkum perubahan harga satu pasar dalam satu hari,
+// dibentuk ringkas agar bisa dikirim lewat SMS atau notifikasi
+func GetMarketDailyDigest(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var market models.Market
+	if err := database.DB.First(&market, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Market not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data pasar"})
+	}
+
+	day := startOfDay(time.Now())
+	if value := c.Query("date"); value != "" {
+		date, err := parseDate(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		day = date
+	}
+
+	var histories []models.PriceHistory
+	if err := database.DB.
+		Where("market_id = ? AND created_at >= ? AND created_at < ?", market.ID, day, day.AddDate(0, 0, 1)).
+		Order("created_at ASC, id ASC").
+		Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+	}
+
+	// Harga awal diambil dari entri pertama hari itu, harga akhir dari entri terakhir
+	itemsByID := make(map[uint]*DigestItem)
+	var order []uint
+	for _, h := range histories {
+		item, ok := itemsByID[h.ItemID]
+		if !ok {
+			item = &DigestItem{ItemName: h.ItemName, PreviousPrice: h.InitialPrice}
+			itemsByID[h.ItemID] = item
+			order = append(order, h.ItemID)
+		}
+		item.CurrentPrice = h.CurrentPrice
+	}
+
+	var risers, fallers []DigestItem
+	unchanged := 0
+	totalChange := 0.0
+	for _, itemID := range order {
+		item := itemsByID[itemID]
+		item.ChangePercent = calculateChangePercent(item.PreviousPrice, item.CurrentPrice)
+		totalChange += item.ChangePercent
+
+		switch {
+		case item.ChangePercent > 0:
+			risers = append(risers, *item)
+		case item.ChangePercent < 0:
+			fallers = append(fallers, *item)
+		default:
+			unchanged++
+		}
+	}
+
+	sort.SliceStable(risers, func(i, j int) bool { return risers[i].ChangePercent > risers[j].ChangePercent })
+	sort.SliceStable(fallers, func(i, j int) bool { return fallers[i].ChangePercent < fallers[j].ChangePercent })
+
+	averageChange := 0.0
+	if len(order) > 0 {
+		averageChange = totalChange / float64(len(order))
+	}
+
+	topRisers := topDigestItems(risers, 3)
+	topFallers := topDigestItems(fallers, 3)
+
+	return c.JSON(fiber.Map{
+		"market_id":      market.ID,
+		"market":         market.Name,
+		"date":           day.Format("2006-01-02"),
+		"rose":           len(risers),
+		"fell":           len(fallers),
+		"unchanged":      unchanged,
+		"average_change": averageChange,
+		"top_risers":     topRisers,
+		"top_fallers":    topFallers,
+		"text":           digestText(market.Name, day, len(risers), len(fallers), unchanged, topRisers, topFallers),
+	})
+}
+
+func topDigestItems(items []DigestItem, n int) []DigestItem {
+	if len(items) > n {
+		items = items[:n]
+	}
+	if items == nil {
+		return []DigestItem{}
+	}
+	return items
+}
+
+// digestText menyusun ringkasan satu paragraf, mis.
+// "Pasar Baru 2025-05-12: 3 naik, 1 turun, 5 tetap. Naik: Cabai +12.5%. Turun: Beras -2.0%."
+func digestText(marketName string, day time.Time, rose, fell, unchanged int, risers, fallers []DigestItem) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s: %d naik, %d turun, %d tetap.", marketName, day.Format("2006-01-02"), rose, fell, unchanged)
+
+	format := func(label string, items []DigestItem) {
+		if len(items) == 0 {
+			return
+		}
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			parts = append(parts, fmt.Sprintf("%s %+.1f%%", item.ItemName, item.ChangePercent))
+		}
+		fmt.Fprintf(&sb, " %s: %s.", label, strings.Join(parts, ", "))
+	}
+	format("Naik", risers)
+	format("Turun", fallers)
+
+	return sb.String()
+}
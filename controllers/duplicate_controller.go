@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultNearDuplicateDistance = 2
+	maxNearDuplicateDistance     = 3
+)
+
+type DuplicatePrice struct {
+	ID       uint   `json:"id"`
+	ItemName string `json:"item_name"`
+	MarketID uint   `json:"market_id"`
+}
+
+type DuplicateBarang struct {
+	IdBarang uint64 `json:"id_barang"`
+	Nama     string `json:"nama"`
+	MarketID uint   `json:"market_id"`
+}
+
+type DuplicateGroup struct {
+	Names  []string          `json:"names"`
+	Prices []DuplicatePrice  `json:"prices"`
+	Barang []DuplicateBarang `json:"barang"`
+}
+
+// normalizeItemName menyamakan nama komoditas tanpa memperhatikan huruf besar dan spasi berlebih
+func normalizeItemName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// GetDuplicateCommodities mencari komoditas di Price dan Barang yang namanya bertabrakan setelah
+// dinormalisasi. Dengan ?mode=near, nama yang jarak edit-nya <= ?distance (default 2) ikut digabung.
+func GetDuplicateCommodities(c *fiber.Ctx) error {
+	mode := c.Query("mode", "exact")
+	if mode != "exact" && mode != "near" {
+		return c.Status(400).JSON(fiber.Map{"error": "mode harus exact atau near"})
+	}
+	distance := c.QueryInt("distance", defaultNearDuplicateDistance)
+	if distance < 1 || distance > maxNearDuplicateDistance {
+		return c.Status(400).JSON(fiber.Map{"error": "distance harus antara 1 dan 3"})
+	}
+
+	var prices []models.Price
+	if err := database.DB.Select("id, item_name, market_id").Find(&prices).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+	}
+	var barangs []models.Barang
+	if err := database.DB.Select("id_barang, nama, market_id").Find(&barangs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data barang"})
+	}
+
+	groups := make(map[string]*DuplicateGroup)
+	group := func(name string) *DuplicateGroup {
+		key := normalizeItemName(name)
+		g, ok := groups[key]
+		if !ok {
+			g = &DuplicateGroup{}
+			groups[key] = g
+		}
+		return g
+	}
+	for _, p := range prices {
+		g := group(p.ItemName)
+		g.Prices = append(g.Prices, DuplicatePrice{ID: p.ID, ItemName: p.ItemName, MarketID: p.MarketID})
+	}
+	for _, b := range barangs {
+		g := group(b.Nama)
+		g.Barang = append(g.Barang, DuplicateBarang{IdBarang: b.IdBarang, Nama: b.Nama, MarketID: b.MarketID})
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Mode near: gabungkan grup yang namanya mirip dengan union-find
+	parent := make(map[string]string, len(keys))
+	for _, key := range keys {
+		parent[key] = key
+	}
+	var find func(string) string
+	find = func(key string) string {
+		if parent[key] != key {
+			parent[key] = find(parent[key])
+		}
+		return parent[key]
+	}
+	if mode == "near" {
+		for i := 0; i < len(keys); i++ {
+			for j := i + 1; j < len(keys); j++ {
+				if levenshtein(keys[i], keys[j], distance) <= distance {
+					parent[find(keys[j])] = find(keys[i])
+				}
+			}
+		}
+	}
+
+	merged := make(map[string]*DuplicateGroup)
+	var rootOrder []string
+	for _, key := range keys {
+		root := find(key)
+		target, ok := merged[root]
+		if !ok {
+			target = &DuplicateGroup{}
+			merged[root] = target
+			rootOrder = append(rootOrder, root)
+		}
+		target.Prices = append(target.Prices, groups[key].Prices...)
+		target.Barang = append(target.Barang, groups[key].Barang...)
+	}
+
+	duplicates := []DuplicateGroup{}
+	for _, root := range rootOrder {
+		g := merged[root]
+		g.Names = distinctNames(g)
+		if isDuplicateGroup(g) {
+			if g.Prices == nil {
+				g.Prices = []DuplicatePrice{}
+			}
+			if g.Barang == nil {
+				g.Barang = []DuplicateBarang{}
+			}
+			duplicates = append(duplicates, *g)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"mode":   mode,
+		"total":  len(duplicates),
+		"groups": duplicates,
+	})
+}
+
+func distinctNames(g *DuplicateGroup) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, p := range g.Prices {
+		add(p.ItemName)
+	}
+	for _, b := range g.Barang {
+		add(b.Nama)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isDuplicateGroup bernilai true bila ejaan nama berbeda-beda, atau ada lebih dari satu
+// baris Price/Barang di pasar yang sama. Nama yang sama di pasar berbeda bukan duplikat.
+func isDuplicateGroup(g *DuplicateGroup) bool {
+	if len(g.Names) > 1 {
+		return true
+	}
+
+	priceMarkets := make(map[uint]bool)
+	for _, p := range g.Prices {
+		if priceMarkets[p.MarketID] {
+			return true
+		}
+		priceMarkets[p.MarketID] = true
+	}
+	barangMarkets := make(map[uint]bool)
+	for _, b := range g.Barang {
+		if barangMarkets[b.MarketID] {
+			return true
+		}
+		barangMarkets[b.MarketID] = true
+	}
+	return false
+}
+
+// levenshtein menghitung jarak edit antara dua string, berhenti lebih awal bila
+// selisih panjangnya saja sudah melebihi max
+func levenshtein(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
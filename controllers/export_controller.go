@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"backend/logger"
+	"backend/models"
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const exportBatchSize = 500
+
+var priceExportHeader = []string{"Nama Barang", "Pasar", "Kategori", "Harga Awal", "Harga Sekarang", "Perubahan (%)", "Diperbarui"}
+
+// ExportPrices mengunduh daftar harga dengan filter yang sama seperti GetPrices
+// (search, market_id, category_id, direction, range, start_date, end_date)
+// sebagai berkas CSV (?format=csv, default) atau Excel (?format=xlsx)
+func ExportPrices(c *fiber.Ctx) error {
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		return c.Status(400).JSON(fiber.Map{"error": "format harus csv atau xlsx"})
+	}
+
+	query, err := basePriceQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	query, err = applyPriceFilters(c, query)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	query = query.Preload("Market").Preload("Category")
+
+	filename := fmt.Sprintf("harga-%s.%s", time.Now().In(appLocation).Format("2006-01-02"), format)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "csv" {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		w := csv.NewWriter(c.Response().BodyWriter())
+		w.Write(priceExportHeader)
+		if err := eachExportPrice(query, func(p models.Price) error {
+			return w.Write(priceExportRecord(p))
+		}); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengekspor data harga"})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	log := logger.FromCtx(c)
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		x, err := newXLSXWriter(bw)
+		if err != nil {
+			log.Error("gagal membuat berkas xlsx", "error", err)
+			return
+		}
+		header := make([]any, len(priceExportHeader))
+		for i, h := range priceExportHeader {
+			header[i] = h
+		}
+		x.WriteRow(header...)
+		if err := eachExportPrice(query, func(p models.Price) error {
+			return x.WriteRow(p.ItemName, p.Market.Name, p.Category.Name, p.InitialPrice, p.CurrentPrice,
+				p.ChangePercent, p.UpdatedAt.Format(time.RFC3339))
+		}); err != nil {
+			log.Error("gagal mengekspor data harga", "error", err)
+		}
+		if err := x.Close(); err != nil {
+			log.Error("gagal menutup berkas xlsx", "error", err)
+		}
+	})
+	return nil
+}
+
+// eachExportPrice membaca harga per batch (urut id) agar ekspor besar tidak dimuat sekaligus
+func eachExportPrice(query *gorm.DB, fn func(models.Price) error) error {
+	var batch []models.Price
+	return query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, p := range batch {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func priceExportRecord(p models.Price) []string {
+	return []string{
+		p.ItemName,
+		p.Market.Name,
+		p.Category.Name,
+		strconv.FormatFloat(p.InitialPrice, 'f', -1, 64),
+		strconv.FormatFloat(p.CurrentPrice, 'f', -1, 64),
+		strconv.FormatFloat(p.ChangePercent, 'f', 2, 64),
+		p.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"archive/zip"
+	"backend/models"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportTestPrice() models.Price {
+	return models.Price{
+		ItemName:      `Cabai "Rawit", merah`,
+		Market:        models.Market{Name: "Pasar Baru"},
+		Category:      models.Category{Name: "Sayur"},
+		InitialPrice:  40000,
+		CurrentPrice:  45000.5,
+		ChangePercent: 12.50125,
+		UpdatedAt:     time.Date(2024, 5, 10, 8, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestPriceExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(priceExportHeader)
+	w.Write(priceExportRecord(exportTestPrice()))
+	w.Flush()
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records) = %d, want 2", len(records))
+	}
+	if !reflect.DeepEqual(records[0], priceExportHeader) {
+		t.Errorf("header = %v, want %v", records[0], priceExportHeader)
+	}
+	want := []string{`Cabai "Rawit", merah`, "Pasar Baru", "Sayur", "40000", "45000.5", "12.50", "2024-05-10T08:30:00Z"}
+	if !reflect.DeepEqual(records[1], want) {
+		t.Errorf("row = %v, want %v", records[1], want)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	x, err := newXLSXWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.WriteRow("Nama", "Harga")
+	x.WriteRow("Gula <pasir> & aren", 15000.25)
+	if err := x.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("berkas xlsx bukan zip yang valid: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	for _, part := range xlsxStaticParts {
+		if _, ok := files[part.name]; !ok {
+			t.Errorf("bagian %s tidak ada", part.name)
+		}
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<row><c t="inlineStr"><is><t>Nama</t></is></c><c t="inlineStr"><is><t>Harga</t></is></c></row>`,
+		`<t>Gula &lt;pasir&gt; &amp; aren</t>`,
+		`<c><v>15000.25</v></c>`,
+		`</sheetData></worksheet>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet tidak mengandung %s\n%s", want, sheet)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errInvalidImage = errors.New("berkas bukan gambar yang valid")
+
+// stripJPEGMetadata membuang segmen APP1 (EXIF/XMP) dan APP13 (IPTC) tanpa meng-encode ulang gambar
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errInvalidImage
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	i := 2
+	for i < len(data) {
+		if data[i] != 0xFF {
+			return nil, errInvalidImage
+		}
+		// Lewati byte pengisi 0xFF
+		for i+1 < len(data) && data[i+1] == 0xFF {
+			i++
+		}
+		if i+1 >= len(data) {
+			return nil, errInvalidImage
+		}
+		marker := data[i+1]
+
+		switch {
+		case marker == 0xDA || marker == 0xD9:
+			// Start of scan / end of image: sisanya data gambar
+			out.Write(data[i:])
+			return out.Bytes(), nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			out.Write(data[i : i+2])
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return nil, errInvalidImage
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:i+4]))
+		if end > len(data) {
+			return nil, errInvalidImage
+		}
+		if marker != 0xE1 && marker != 0xED {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	return nil, errInvalidImage
+}
+
+// Chunk PNG yang berisi metadata (EXIF dan teks)
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true}
+
+// stripPNGMetadata membuang chunk metadata PNG; chunk lain disalin apa adanya beserta CRC-nya
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if len(data) < len(signature) || string(data[:len(signature)]) != signature {
+		return nil, errInvalidImage
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:len(signature)])
+
+	i := len(signature)
+	for i+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		chunkType := string(data[i+4 : i+8])
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errInvalidImage
+		}
+		if !pngMetadataChunks[chunkType] {
+			out.Write(data[i:end])
+		}
+		i = end
+		if chunkType == "IEND" {
+			return out.Bytes(), nil
+		}
+	}
+	return nil, errInvalidImage
+}
+
+// stripWebPMetadata membuang chunk EXIF dan XMP dari container RIFF WebP dan
+// membersihkan flag-nya di chunk VP8X
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errInvalidImage
+	}
+
+	var body bytes.Buffer
+	hasImage := false
+	i := 12
+	for i+8 <= len(data) {
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		end := i + 8 + size + size%2
+		if size < 0 || i+8+size > len(data) {
+			return nil, errInvalidImage
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if size > 0 {
+				chunk[8] &^= 0x08 | 0x04
+			}
+			body.Write(chunk)
+		default:
+			if fourCC == "VP8 " || fourCC == "VP8L" {
+				hasImage = true
+			}
+			body.Write(data[i:end])
+		}
+		i = end
+	}
+	if !hasImage {
+		return nil, errInvalidImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, 12+body.Len()))
+	out.WriteString("RIFF")
+	binary.Write(out, binary.LittleEndian, uint32(4+body.Len()))
+	out.WriteString("WEBP")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
@@ -0,0 +1,399 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Kolom wajib pada file CSV import harga/barang
+var importColumns = []string{"item_name", "current_price", "reason", "market_id", "category_id"}
+
+type ImportRow struct {
+	Line         int      `json:"line"`
+	ItemName     string   `json:"item_name"`
+	CurrentPrice float64  `json:"current_price"`
+	Reason       string   `json:"reason"`
+	MarketID     uint     `json:"market_id"`
+	CategoryID   uint     `json:"category_id"`
+	Valid        bool     `json:"valid"`
+	Force        bool     `json:"force,omitempty"`  // Kolom opsional, lewati batas perubahan harga
+	Action       string   `json:"action,omitempty"` // "create" atau "update"
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// parseImportCSV membaca CSV import (BOM, field ber-quote dan baris kosong di akhir ditangani)
+// lalu mengembalikan baris mentah yang belum divalidasi terhadap database.
+func parseImportCSV(r io.Reader) ([]ImportRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file: %v", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("file CSV kosong")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("header CSV tidak valid: %v", err)
+	}
+
+	colIndex := make(map[string]int)
+	for i, col := range header {
+		colIndex[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	for _, col := range importColumns {
+		if _, ok := colIndex[col]; !ok {
+			return nil, fmt.Errorf("kolom %s tidak ditemukan di header", col)
+		}
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line := 0
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				line = parseErr.StartLine
+			}
+			rows = append(rows, ImportRow{Line: line, Errors: []string{fmt.Sprintf("format CSV tidak valid: %v", err)}})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		// Lewati baris kosong (mis. baris kosong di akhir file)
+		if len(strings.TrimSpace(strings.Join(record, ""))) == 0 {
+			continue
+		}
+
+		get := func(col string) string {
+			if i := colIndex[col]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := ImportRow{
+			Line:     line,
+			ItemName: get("item_name"),
+			Reason:   get("reason"),
+		}
+
+		if _, ok := colIndex["force"]; ok && get("force") != "" {
+			if force, err := strconv.ParseBool(get("force")); err != nil {
+				row.Errors = append(row.Errors, "force harus berupa true atau false")
+			} else {
+				row.Force = force
+			}
+		}
+
+		if row.ItemName == "" {
+			row.Errors = append(row.Errors, "item_name wajib diisi")
+		}
+
+		if price, err := strconv.ParseFloat(get("current_price"), 64); err != nil {
+			row.Errors = append(row.Errors, "current_price harus berupa angka")
+		} else if price < 0 {
+			row.Errors = append(row.Errors, "current_price tidak boleh negatif")
+		} else {
+			row.CurrentPrice = roundPrice(price)
+		}
+
+		if id, err := strconv.ParseUint(get("market_id"), 10, 64); err != nil || id == 0 {
+			row.Errors = append(row.Errors, "market_id tidak valid")
+		} else {
+			row.MarketID = uint(id)
+		}
+
+		if id, err := strconv.ParseUint(get("category_id"), 10, 64); err != nil || id == 0 {
+			row.Errors = append(row.Errors, "category_id tidak valid")
+		} else {
+			row.CategoryID = uint(id)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+type importKey struct {
+	ItemName string
+	MarketID uint
+}
+
+// validateImportRows mencocokkan setiap baris dengan data di database: market dan kategori
+// harus ada, dan barang yang sudah ada di pasar yang sama ditandai sebagai update.
+func validateImportRows(rows []ImportRow) error {
+	var marketIDs, categoryIDs []uint
+	var existing []importKey
+	if err := database.DB.Model(&models.Market{}).Pluck("id", &marketIDs).Error; err != nil {
+		return err
+	}
+	if err := database.DB.Model(&models.Category{}).Pluck("id", &categoryIDs).Error; err != nil {
+		return err
+	}
+	if err := database.DB.Model(&models.Price{}).Distinct("item_name", "market_id").Find(&existing).Error; err != nil {
+		return err
+	}
+
+	markets := make(map[uint]bool)
+	for _, id := range marketIDs {
+		markets[id] = true
+	}
+	categories := make(map[uint]bool)
+	for _, id := range categoryIDs {
+		categories[id] = true
+	}
+	known := make(map[importKey]bool)
+	for _, key := range existing {
+		known[key] = true
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.MarketID != 0 && !markets[row.MarketID] {
+			row.Errors = append(row.Errors, fmt.Sprintf("Market ID %d tidak ditemukan", row.MarketID))
+		}
+		if row.CategoryID != 0 && !categories[row.CategoryID] {
+			row.Errors = append(row.Errors, fmt.Sprintf("Category ID %d tidak ditemukan", row.CategoryID))
+		}
+
+		row.Valid = len(row.Errors) == 0
+		if !row.Valid {
+			continue
+		}
+
+		key := importKey{ItemName: row.ItemName, MarketID: row.MarketID}
+		if known[key] {
+			row.Action = "update"
+		} else {
+			row.Action = "create"
+			// Baris berikutnya dengan nama dan pasar yang sama akan meng-update baris ini
+			known[key] = true
+		}
+	}
+
+	return nil
+}
+
+// PreviewImport memvalidasi file CSV seperti import sungguhan tanpa menulis ke database
+func PreviewImport(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "File CSV wajib diunggah pada field 'file'"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Gagal membuka file"})
+	}
+	defer file.Close()
+
+	rows, err := parseImportCSV(file)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := validateImportRows(rows); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memvalidasi data import"})
+	}
+
+	valid, creates, updates := 0, 0, 0
+	for _, row := range rows {
+		if !row.Valid {
+			continue
+		}
+		valid++
+		if row.Action == "update" {
+			updates++
+		} else {
+			creates++
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"rows":    rows,
+		"total":   len(rows),
+		"valid":   valid,
+		"invalid": len(rows) - valid,
+		"new":     creates,
+		"updates": updates,
+	})
+}
+
+type ImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ImportPrices mengimpor harga dari file CSV dalam satu transaksi. Baris yang tidak valid atau
+// ditolak aturan edit harian / batas perubahan harga (kolom opsional force) dilewati dan
+// dilaporkan; baris valid membuat atau memperbarui harga beserta PriceHistory-nya.
+func ImportPrices(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "File CSV wajib diunggah pada field 'file'"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Gagal membuka file"})
+	}
+	defer file.Close()
+
+	rows, err := parseImportCSV(file)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := validateImportRows(rows); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memvalidasi data import"})
+	}
+	// Petugas hanya boleh mengimpor harga untuk pasarnya sendiri
+	for i := range rows {
+		if rows[i].Valid && !canWriteMarket(c, uint64(rows[i].MarketID)) {
+			rows[i].Valid = false
+			rows[i].Errors = append(rows[i].Errors, fmt.Sprintf("Market ID %d bukan pasar Anda", rows[i].MarketID))
+		}
+	}
+
+	imported, updated := 0, 0
+	importErrors := []ImportError{}
+
+	// Start transaction
+	tx := database.DB.Begin()
+
+	for _, row := range rows {
+		if !row.Valid {
+			importErrors = append(importErrors, ImportError{Line: row.Line, Message: strings.Join(row.Errors, "; ")})
+			continue
+		}
+
+		created, err := importPriceRow(tx, row, currentOfficerID(c))
+		// Baris yang ditolak aturan perubahan harga dilewati tanpa membatalkan import
+		var ruleErr priceRuleError
+		if errors.As(err, &ruleErr) {
+			importErrors = append(importErrors, ImportError{Line: row.Line, Message: ruleErr.Error()})
+			continue
+		}
+		if err != nil {
+			tx.Rollback()
+			return c.Status(500).JSON(fiber.Map{
+				"error": fmt.Sprintf("Gagal mengimpor baris %d: %v", row.Line, err),
+			})
+		}
+		if created {
+			imported++
+		} else {
+			updated++
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit transaction"})
+	}
+
+	return c.JSON(fiber.Map{
+		"imported": imported,
+		"updated":  updated,
+		"skipped":  len(importErrors),
+		"errors":   importErrors,
+	})
+}
+
+// importPriceRow membuat atau memperbarui harga untuk satu baris import, mengembalikan true bila dibuat baru.
+// officerID dicatat di histori sebagai petugas yang mengimpor.
+func importPriceRow(tx *gorm.DB, row ImportRow, officerID *uint64) (bool, error) {
+	now := time.Now().UTC()
+	recordHistory := true
+
+	var price models.Price
+	err := tx.Where("name_key = ? AND market_id = ?", models.NameKey(row.ItemName), row.MarketID).First(&price).Error
+	created := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !created {
+		return false, err
+	}
+
+	if created {
+		// Pakai item_id yang sama bila barang ini sudah ada di pasar lain, seperti CreatePrice
+		price.ItemID = sharedItemID(tx, row.ItemName)
+
+		price.ItemName = row.ItemName
+		price.MarketID = row.MarketID
+		price.CategoryID = row.CategoryID
+		price.InitialPrice = row.CurrentPrice
+		price.CurrentPrice = row.CurrentPrice
+		price.Reason = row.Reason
+		price.CreatedAt = now
+		price.UpdatedAt = now
+		price.CreatedBy = officerID
+		price.UpdatedBy = officerID
+
+		if err := tx.Create(&price).Error; err != nil {
+			return false, err
+		}
+		if err := ensureItemID(tx, &price); err != nil {
+			return false, err
+		}
+	} else {
+		if err := checkPriceUpdate(price, row.CurrentPrice, row.Force, time.Now()); err != nil {
+			return false, err
+		}
+		recordHistory = priceHistoryNeeded(price.CurrentPrice, row.CurrentPrice, price.Reason, row.Reason)
+		price.InitialPrice = price.CurrentPrice
+		price.CurrentPrice = row.CurrentPrice
+		price.ChangePercent = calculateChangePercent(price.InitialPrice, price.CurrentPrice)
+		price.CategoryID = row.CategoryID
+		price.Reason = row.Reason
+		price.UpdatedAt = now
+		price.UpdatedBy = officerID
+
+		if err := tx.Save(&price).Error; err != nil {
+			return false, err
+		}
+	}
+
+	if recordHistory {
+		history := models.PriceHistory{
+			ItemID:        price.ItemID,
+			ItemName:      price.ItemName,
+			InitialPrice:  price.InitialPrice,
+			CurrentPrice:  price.CurrentPrice,
+			Reason:        price.Reason,
+			MarketID:      price.MarketID,
+			CategoryID:    price.CategoryID,
+			ChangePercent: price.ChangePercent,
+			OfficerID:     officerID,
+			CreatedAt:     time.Now(),
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return false, err
+		}
+	}
+
+	if err := SyncPriceWithBarang(price.ID, tx); err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"backend/models"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var updateGolden = flag.Bool("update", false, "tulis ulang file golden di testdata")
+
+// normalizeLoginResponse mengganti token acak dengan placeholder lalu mengurutkan key JSON
+func normalizeLoginResponse(t *testing.T, raw []byte) []byte {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Fatalf("response bukan JSON: %v\n%s", err, raw)
+	}
+	if data, ok := body["data"].(map[string]interface{}); ok {
+		for _, key := range []string{"token", "refresh_token"} {
+			if value, _ := data[key].(string); value != "" {
+				data[key] = "<" + key + ">"
+			}
+		}
+	}
+	var normalized bytes.Buffer
+	encoder := json.NewEncoder(&normalized)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(body); err != nil {
+		t.Fatal(err)
+	}
+	return normalized.Bytes()
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden.json")
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("baca %s: %v (jalankan go test -update untuk membuatnya)", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("response berbeda dengan %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestLoginResponseGolden(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		market  models.Market
+		officer models.MarketOfficer
+	}{
+		{
+			name:    "login_full",
+			market:  models.Market{Name: "Pasar Baru", Location: "Jl. Merdeka 1", ImageURL: "https://cdn.example.com/pasar.jpg", Latitude: -6.2, Longitude: 106.8},
+			officer: models.MarketOfficer{Name: "Budi", Nik: "3201010101010001", Phone: "081234567890", ImageURL: "https://cdn.example.com/budi.jpg", Username: "budi", Role: models.OfficerRoleAdmin},
+		},
+		{
+			// Field opsional yang kosong dan koordinat 0 tidak dikirim
+			name:    "login_minimal",
+			market:  models.Market{Name: "Pasar Lama", Location: "Kota"},
+			officer: models.MarketOfficer{Name: "Siti", Nik: "3201010101010002", Username: "siti"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useTestDB(t)
+			mustCreate(t, db, &tt.market)
+			tt.officer.MarketID = uint64(tt.market.ID)
+			tt.officer.Password = string(hash)
+			tt.officer.IsActive = true
+			mustCreate(t, db, &tt.officer)
+
+			app := fiber.New()
+			app.Post("/auth/login", Login)
+			app.Post("/auth/refresh", RefreshToken)
+
+			req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(`{"username":"`+tt.officer.Username+`","password":"rahasia123"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d: %s", resp.StatusCode, raw)
+			}
+			assertGolden(t, tt.name, normalizeLoginResponse(t, raw))
+
+			// /auth/refresh mengirim bentuk yang sama, hanya pesannya berbeda
+			var login LoginResponse
+			json.Unmarshal(raw, &login)
+			req = httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(`{"refresh_token":"`+login.Data.RefreshToken+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err = app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, _ = io.ReadAll(resp.Body)
+			if resp.StatusCode != 200 {
+				t.Fatalf("refresh: status = %d: %s", resp.StatusCode, raw)
+			}
+			refreshed := strings.Replace(string(normalizeLoginResponse(t, raw)), `"Token diperbarui"`, `"Login berhasil"`, 1)
+			assertGolden(t, tt.name, []byte(refreshed))
+		})
+	}
+}
@@ -2,18 +2,21 @@ package controllers
 
 import (
 	"backend/database"
+	"backend/logger"
 	"backend/models"
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // Ambil semua pasar dengan opsi pencarian berdasarkan nama
 func GetMarkets(c *fiber.Ctx) error {
 	if database.DB == nil {
-		fmt.Println("Database connection is nil!")
+		logger.FromCtx(c).Error("koneksi database nil")
 		return c.Status(500).JSON(fiber.Map{"error": "Database connection error"})
 	}
 
@@ -34,10 +37,55 @@ func GetMarkets(c *fiber.Ctx) error {
 		})
 	}
 
+	// ?include_deleted=true (khusus admin) ikut menampilkan pasar yang sudah dihapus
+	if c.QueryBool("include_deleted") {
+		type MarketWithDeleted struct {
+			models.Market
+			DeletedAt *time.Time `json:"deleted_at"`
+		}
+
+		if err := query.Unscoped().Find(&markets).Error; err != nil {
+			logger.FromCtx(c).Error("gagal mengambil data pasar", "error", err)
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to retrieve markets"})
+		}
+
+		rows := make([]MarketWithDeleted, 0, len(markets))
+		for _, m := range markets {
+			row := MarketWithDeleted{Market: m}
+			if m.DeletedAt.Valid {
+				row.DeletedAt = &m.DeletedAt.Time
+			}
+			rows = append(rows, row)
+		}
+		return c.JSON(rows)
+	}
+
+	// Opsional: sertakan jumlah petugas per pasar lewat satu subquery yang di-group
+	if c.Query("include") == "officer_count" {
+		type MarketWithOfficerCount struct {
+			models.Market
+			OfficerCount int64 `json:"officer_count"`
+		}
+
+		var rows []MarketWithOfficerCount
+		if err := query.Model(&models.Market{}).
+			Select("markets.*, COALESCE(oc.officer_count, 0) AS officer_count").
+			Joins("LEFT JOIN (SELECT market_id, COUNT(*) AS officer_count FROM market_officers WHERE deleted_at IS NULL GROUP BY market_id) oc ON oc.market_id = markets.id").
+			Find(&rows).Error; err != nil {
+			logger.FromCtx(c).Error("gagal mengambil data pasar", "error", err)
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to retrieve markets"})
+		}
+
+		if len(rows) == 0 {
+			return c.JSON([]MarketWithOfficerCount{})
+		}
+		return c.JSON(rows)
+	}
+
 	// Ambil data dari database
 	result := query.Find(&markets)
 	if result.Error != nil {
-		fmt.Println("Database Error:", result.Error)
+		logger.FromCtx(c).Error("gagal mengambil data pasar", "error", result.Error)
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to retrieve markets"})
 	}
 
@@ -72,8 +120,15 @@ func CreateMarket(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
 
-	if market.Name == "" || market.Location == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Name and location are required"})
+	if ok, err := validateInput(c, market); !ok {
+		return err
+	}
+
+	if market.Currency != "" && !isSupportedCurrency(market.Currency) {
+		return c.Status(400).JSON(fiber.Map{"error": "Mata uang tidak didukung"})
+	}
+	if market.Locale != "" && !isSupportedLocale(market.Locale) {
+		return c.Status(400).JSON(fiber.Map{"error": "Locale tidak didukung"})
 	}
 
 	var existing models.Market
@@ -124,6 +179,22 @@ func UpdateMarket(c *fiber.Ctx) error {
 		market.ImageURL = updateData.ImageURL
 	}
 
+	if updateData.Currency != "" {
+		if !isSupportedCurrency(updateData.Currency) {
+			return c.Status(400).JSON(fiber.Map{"error": "Mata uang tidak didukung"})
+		}
+		market.Currency = updateData.Currency
+	}
+	if updateData.Locale != "" {
+		if !isSupportedLocale(updateData.Locale) {
+			return c.Status(400).JSON(fiber.Map{"error": "Locale tidak didukung"})
+		}
+		market.Locale = updateData.Locale
+	}
+	if ok, err := validateInput(c, &market); !ok {
+		return err
+	}
+
 	// Validasi jika nama baru sudah digunakan pasar lain
 var conflict models.Market
 if err := database.DB.
@@ -154,16 +225,16 @@ func UpdateMarketLocation(c *fiber.Ctx) error {
 
 	var input LocationUpdate
 	if err := c.BodyParser(&input); err != nil {
-		fmt.Println("❌ Body Parsing Error:", err)
+		logger.FromCtx(c).Warn("body lokasi pasar tidak valid", "error", err)
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid input format",
 		})
 	}
-	fmt.Println("✅ Received data:", input)
+	logger.FromCtx(c).Debug("update lokasi pasar diterima", "market_id", id, "latitude", input.Latitude, "longitude", input.Longitude)
 
 	// Validasi: Latitude dan Longitude tidak boleh nol
 	if input.Latitude == 0 || input.Longitude == 0 {
-		fmt.Println("❌ Invalid Latitude/Longitude values")
+		logger.FromCtx(c).Warn("latitude/longitude tidak valid", "market_id", id)
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
 			"error": "Latitude and Longitude are required",
 		})
@@ -172,12 +243,11 @@ func UpdateMarketLocation(c *fiber.Ctx) error {
 	// Cari pasar berdasarkan ID
 	var market models.Market
 	if err := database.DB.First(&market, id).Error; err != nil {
-		fmt.Println("Market Not Found in DB:", err)
+		logger.FromCtx(c).Warn("pasar tidak ditemukan", "market_id", id, "error", err)
 		return c.Status(http.StatusNotFound).JSON(fiber.Map{
 			"error": "Market not found",
 		})
 	}
-	fmt.Printf("Market Found: %+v\n", market) // Pastikan pasar ditemukan sebelum update
 
 	// Update koordinat pasar
 	market.Latitude = input.Latitude
@@ -188,20 +258,18 @@ func UpdateMarketLocation(c *fiber.Ctx) error {
 		Latitude:  input.Latitude,
 		Longitude: input.Longitude,
 	}).Error; err != nil {
-		fmt.Println("❌ Database Save Error:", err)
+		logger.FromCtx(c).Error("gagal menyimpan lokasi pasar", "market_id", id, "error", err)
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update market location",
 		})
 	}
 
-	fmt.Println("✅ Market Updated:", market)
-
 	err := database.DB.Model(&market).Updates(map[string]interface{}{
 		"latitude":  input.Latitude,
 		"longitude": input.Longitude,
 	}).Error
 	if err != nil {
-		fmt.Println("Database Update Error:", err)
+		logger.FromCtx(c).Error("gagal menyimpan lokasi pasar", "market_id", id, "error", err)
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update market location",
 		})
@@ -210,11 +278,9 @@ func UpdateMarketLocation(c *fiber.Ctx) error {
 	// Tambahkan log untuk memastikan perubahan tersimpan
 	rowsAffected := database.DB.RowsAffected
 	if rowsAffected == 0 {
-		fmt.Println("Warning: No rows affected, data might not be updated!")
+		logger.FromCtx(c).Warn("update lokasi pasar tidak mengubah baris apa pun", "market_id", id)
 	}
 
-	fmt.Printf("Received Update Request: ID=%s, Lat=%f, Lng=%f\n", id, input.Latitude, input.Longitude)
-
 	// Response sukses
 	return c.JSON(fiber.Map{
 		"message":   "Market location updated successfully",
@@ -225,16 +291,188 @@ func UpdateMarketLocation(c *fiber.Ctx) error {
 }
 
 // Hapus pasar berdasarkan ID
+// Penghapusan diblokir (409) jika masih ada petugas, barang atau harga di pasar tersebut. Dengan
+// ?cascade=true petugasnya ikut dinonaktifkan dan di-soft delete beserta sesinya, sedangkan barang
+// dan harga dibiarkan agar ikut kembali bila pasar dipulihkan lewat RestoreMarket.
 func DeleteMarket(c *fiber.Ctx) error {
 	id := c.Params("id")
+	cascade := c.QueryBool("cascade", false)
 
 	if database.DB == nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Database connection error"})
 	}
 
-	if err := database.DB.Delete(&models.Market{}, id).Error; err != nil {
+	var blockers fiber.Map
+	var officerIDs []uint64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var market models.Market
+		if err := tx.First(&market, id).Error; err != nil {
+			return err
+		}
+
+		deps, err := marketDependencies(tx, market.ID)
+		if err != nil {
+			return err
+		}
+		if len(deps) > 0 && !cascade {
+			blockers = deps
+			return errMarketHasDependencies
+		}
+
+		if err := tx.Model(&models.MarketOfficer{}).
+			Where("market_id = ?", market.ID).
+			Pluck("id", &officerIDs).Error; err != nil {
+			return err
+		}
+		if len(officerIDs) > 0 {
+			if err := tx.Model(&models.MarketOfficer{}).
+				Where("id IN ?", officerIDs).
+				Update("is_active", false).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", officerIDs).Delete(&models.MarketOfficer{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.RefreshToken{}).
+				Where("officer_id IN ? AND revoked = ?", officerIDs, false).
+				Update("revoked", true).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&market).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Market not found"})
+		}
+		if errors.Is(err, errMarketHasDependencies) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":    "Pasar masih dipakai, gunakan ?cascade=true untuk menonaktifkan petugasnya dan tetap menghapus",
+				"blockers": blockers,
+			})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete market"})
 	}
 
-	return c.JSON(fiber.Map{"message": "Market deleted successfully"})
+	// Token petugas yang ikut dihapus langsung dicabut
+	if len(officerIDs) > 0 {
+		var sessions []models.OfficerSession
+		database.DB.Where("officer_id IN ? AND revoked_at IS NULL", officerIDs).Find(&sessions)
+		for _, session := range sessions {
+			if err := revokeOfficerSession(session); err != nil {
+				logger.FromCtx(c).Error("gagal mencabut sesi petugas", "officer_id", session.OfficerID, "error", err)
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":          "Market deleted successfully",
+		"deleted_officers": len(officerIDs),
+	})
+}
+
+var errMarketHasDependencies = errors.New("market has dependent data")
+
+// marketDependencies menghitung petugas, barang dan harga yang masih merujuk ke pasar
+func marketDependencies(tx *gorm.DB, marketID uint) (fiber.Map, error) {
+	blockers := fiber.Map{}
+
+	for key, model := range map[string]interface{}{
+		"officers": &models.MarketOfficer{},
+		"barang":   &models.Barang{},
+		"prices":   &models.Price{},
+	} {
+		var count int64
+		if err := tx.Model(model).Where("market_id = ?", marketID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			blockers[key] = count
+		}
+	}
+
+	return blockers, nil
+}
+
+// RestoreMarket memulihkan pasar yang sudah di-soft delete
+func RestoreMarket(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	result := database.DB.Unscoped().
+		Model(&models.Market{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to restore market"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Pasar yang dihapus dengan ID tersebut tidak ditemukan"})
+	}
+
+	var market models.Market
+	if err := database.DB.First(&market, id).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data pasar"})
+	}
+	return c.JSON(fiber.Map{"message": "Market restored successfully", "market": market})
+}
+
+// GetMarketProfile mengembalikan data pasar beserta kategori, petugas, jumlah komoditas
+// dan waktu update harga terakhir dalam satu response
+func GetMarketProfile(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var market models.Market
+	if err := database.DB.First(&market, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Market not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data pasar"})
+	}
+
+	var categories []models.Category
+	if err := database.DB.
+		Joins("JOIN category_markets ON categories.id = category_markets.category_id").
+		Where("category_markets.market_id = ?", market.ID).
+		Find(&categories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil kategori pasar"})
+	}
+
+	var officers []models.MarketOfficer
+	if err := database.DB.Where("market_id = ?", market.ID).Find(&officers).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil petugas pasar"})
+	}
+
+	officerResponses := make([]OfficerResponse, 0, len(officers))
+	for _, officer := range officers {
+		officerResponses = append(officerResponses, *NewOfficerResponse(officer))
+	}
+
+	var stats struct {
+		TotalCommodities int64
+		LastPriceUpdate  *time.Time
+	}
+	if err := database.DB.Model(&models.Price{}).
+		Select("COUNT(DISTINCT item_name) AS total_commodities, MAX(updated_at) AS last_price_update").
+		Where("market_id = ?", market.ID).
+		Scan(&stats).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung komoditas pasar"})
+	}
+
+	return c.JSON(fiber.Map{
+		"market": MarketResponse{
+			ID:        market.ID,
+			Name:      market.Name,
+			Location:  market.Location,
+			ImageURL:  market.ImageURL,
+			Latitude:  market.Latitude,
+			Longitude: market.Longitude,
+		},
+		"categories":        categories,
+		"category_count":    len(categories),
+		"officers":          officerResponses,
+		"total_commodities": stats.TotalCommodities,
+		"last_price_update": stats.LastPriceUpdate,
+	})
 }
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// dashboardTotals adalah ringkasan agregat harga untuk dashboard
+type dashboardTotals struct {
+	TotalCommodities int64
+	TotalStockValue  float64
+	AverageChange    float64
+}
+
+// sumDashboardTotals menghitung jumlah komoditas unik, total nilai harga saat ini dan rata-rata
+// persentase perubahan dengan satu query agregat, tanpa memuat baris harga ke memori
+func sumDashboardTotals(query *gorm.DB) (dashboardTotals, error) {
+	var totals dashboardTotals
+	err := query.
+		Select("COUNT(DISTINCT item_name) AS total_commodities, COALESCE(SUM(current_price), 0) AS total_stock_value, " +
+			"COALESCE(AVG(change_percent), 0) AS average_change").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetMarketDashboard mengembalikan ringkasan dashboard satu pasar untuk petugasnya: total komoditas,
+// total nilai harga, rata-rata perubahan, serta movers naik dan turun sejak reset harian terakhir
+// (?limit= per arah, default 10, maksimal maxPageSize)
+func GetMarketDashboard(c *fiber.Ctx) error {
+	var market models.Market
+	if err := database.DB.First(&market, c.Params("market_id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Market not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data pasar"})
+	}
+
+	limit := c.QueryInt("limit", defaultMoversLimit)
+	if limit < 1 {
+		limit = defaultMoversLimit
+	}
+	limit = min(limit, maxPageSize)
+
+	totals, err := sumDashboardTotals(database.DB.Model(&models.Price{}).Where("market_id = ?", market.ID))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung ringkasan harga"})
+	}
+
+	now := time.Now()
+	from := lastDailyReset(now)
+	var histories []models.PriceHistory
+	if err := database.DB.
+		Where("market_id = ? AND created_at >= ? AND created_at < ?", market.ID, from, now).
+		Order("created_at ASC, id ASC").
+		Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+	}
+
+	return c.JSON(fiber.Map{
+		"market_id":         market.ID,
+		"market":            market.Name,
+		"total_commodities": totals.TotalCommodities,
+		"total_stock_value": roundPrice(totals.TotalStockValue),
+		"average_change":    roundPrice(totals.AverageChange),
+		"movers": fiber.Map{
+			"from": from,
+			"up":   rankPriceMovers(histories, "up", limit),
+			"down": rankPriceMovers(histories, "down", limit),
+		},
+	})
+}
@@ -2,32 +2,21 @@ package controllers
 
 import (
 	"backend/database"
+	"backend/logger"
+	"backend/middleware"
 	"backend/models"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/crypto/bcrypt"
 
 	"errors"
-	"log"
-	"os"
-	"time"
-
-	"github.com/golang-jwt/jwt/v4"
 
 	"gorm.io/gorm"
 )
 
-var jwtSecret = []byte(getJWTSecret())
-
-func getJWTSecret() string {
-	if os.Getenv("JWT_SECRET") != "" {
-		return os.Getenv("JWT_SECRET")
-	}
-	return "default-secret" // fallback
-}
-
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -40,30 +29,37 @@ type LoginResponse struct {
 }
 
 type LoginResponseData struct {
-	Officer *OfficerResponse `json:"officer"`
-	Token   string           `json:"token"`
-	Market  *MarketResponse  `json:"market"`
+	Officer      *OfficerResponse `json:"officer"`
+	Token        string           `json:"token"`
+	RefreshToken string           `json:"refresh_token"`
+	Market       *MarketResponse  `json:"market"`
 }
 
+// OfficerResponse adalah bentuk data petugas yang dikirim ke client, dipakai oleh Login,
+// /auth/refresh dan profil petugas. Field opsional yang kosong tidak dikirim.
 type OfficerResponse struct {
 	ID       uint64          `json:"id"`
 	Name     string          `json:"name"`
 	Username string          `json:"username"`
-	Nik      string          `json:"nik"`
-	Phone    string          `json:"phone"`
-	ImageURL string          `json:"image_url"`
+	Role     string          `json:"role"`
+	Nik      string          `json:"nik,omitempty"`
+	Phone    string          `json:"phone,omitempty"`
+	ImageURL string          `json:"image_url,omitempty"`
 	MarketID uint64          `json:"market_id"`
-	Market   *MarketResponse `json:"market"`
+	Market   *MarketResponse `json:"market,omitempty"`
 }
+
+// MarketResponse adalah ringkasan pasar, koordinat 0 berarti belum diisi sehingga tidak dikirim
 type MarketResponse struct {
 	ID        uint    `json:"id"`
 	Name      string  `json:"name"`
 	Location  string  `json:"location"`
-	ImageURL  string  `json:"image_url"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	ImageURL  string  `json:"image_url,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
 }
 
+// Login adalah satu-satunya handler login petugas pasar (POST /auth/login) untuk aplikasi mobile
 func Login(c *fiber.Ctx) error {
 	var req LoginRequest
 
@@ -85,11 +81,13 @@ func Login(c *fiber.Ctx) error {
 	result := database.DB.Preload("Market").Where("username = ?", req.Username).First(&officer)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.FromCtx(c).Warn("login gagal: officer tidak ditemukan", "username", req.Username)
 			return c.Status(http.StatusUnauthorized).JSON(LoginResponse{
 				Success: false,
 				Message: "Username atau password salah",
 			})
 		}
+		logger.FromCtx(c).Error("login gagal: query officer", "username", req.Username, "error", result.Error)
 		return c.Status(http.StatusInternalServerError).JSON(LoginResponse{
 			Success: false,
 			Message: "Terjadi kesalahan saat mengakses database",
@@ -112,57 +110,32 @@ func Login(c *fiber.Ctx) error {
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(officer.Password), []byte(req.Password)); err != nil {
+		logger.FromCtx(c).Warn("login gagal: password salah", "username", req.Username, "officer_id", officer.ID)
 		return c.Status(http.StatusUnauthorized).JSON(LoginResponse{
 			Success: false,
 			Message: "Username atau password salah",
 		})
 	}
 
-	// Generate JWT token
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := jwt.MapClaims{
-		"username":   officer.Username,
-		"officer_id": officer.ID,
-		"market_id":  officer.MarketID,
-		"exp":        expirationTime.Unix(),
-	}
-	log.Printf("Creating token for officer %s with market_id %d", officer.Username, officer.MarketID)
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
+	tokens, err := IssueOfficerTokens(c, database.DB, officer)
 	if err != nil {
-		log.Printf("Gagal membuat token: %v", err)
+		logger.FromCtx(c).Error("gagal membuat token", "error", err)
 		return c.Status(http.StatusInternalServerError).JSON(LoginResponse{
 			Success: false,
 			Message: "Gagal membuat token login",
 		})
 	}
 
-	officerResponse := &OfficerResponse{
-		ID:       officer.ID,
-		Name:     officer.Name,
-		Username: officer.Username,
-		Nik:      officer.Nik,
-		Phone:    officer.Phone,
-		ImageURL: officer.ImageURL,
-		MarketID: officer.MarketID,
-		Market: &MarketResponse{
-			ID:        officer.Market.ID,
-			Name:      officer.Market.Name,
-			Location:  officer.Market.Location,
-			ImageURL:  officer.Market.ImageURL,
-			Latitude:  officer.Market.Latitude,
-			Longitude: officer.Market.Longitude,
-		},
-	}
+	officerResponse := NewOfficerResponse(officer)
 
 	return c.JSON(LoginResponse{
 		Success: true,
 		Message: "Login berhasil",
 		Data: &LoginResponseData{
-			Officer: officerResponse,
-			Token:   tokenString,
-			Market:  officerResponse.Market,
+			Officer:      officerResponse,
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			Market:       officerResponse.Market,
 		},
 	})
 }
@@ -181,14 +154,21 @@ func ToggleOfficerStatus(c *fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	var officer models.MarketOfficer
-	result := database.DB.First(&officer, officerID)
+	// Toggle secara atomik di database agar tidak balapan dengan update lain pada petugas yang sama
+	result := database.DB.Model(&models.MarketOfficer{}).
+		Where("id = ?", officerID).
+		Update("is_active", gorm.Expr("NOT is_active"))
 	if result.Error != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal memperbarui status petugas"})
+	}
+	if result.RowsAffected == 0 {
 		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "Petugas tidak ditemukan"})
 	}
 
-	officer.IsActive = !officer.IsActive
-	database.DB.Save(&officer)
+	var officer models.MarketOfficer
+	if err := database.DB.Select("id", "is_active").First(&officer, officerID).Error; err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "Gagal membaca status petugas"})
+	}
 
 	return c.JSON(fiber.Map{"message": "Status petugas diperbarui", "is_active": officer.IsActive})
 }
@@ -203,12 +183,37 @@ func GetMarketOfficerByID(c *fiber.Ctx) error {
 	return c.JSON(officer)
 }
 
+// officerInput adalah body create/update petugas. MarketOfficer.Password memakai json:"-"
+// sehingga password dibaca lewat field terpisah dan tidak pernah ikut terkirim di response.
+type officerInput struct {
+	models.MarketOfficer
+	Password string `json:"password"`
+}
+
 // Create a new market officer
 func CreateMarketOfficer(c *fiber.Ctx) error {
-	var officer models.MarketOfficer
-	if err := c.BodyParser(&officer); err != nil {
+	var input officerInput
+	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
+	officer := input.MarketOfficer
+	if input.Password == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Password wajib diisi"})
+	}
+	if err := validatePasswordStrength(input.Password); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if officer.Role != "" && !isValidOfficerRole(officer.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "Role harus admin atau officer"})
+	}
+	// Hanya admin yang boleh menentukan role, selain itu petugas baru selalu petugas lapangan
+	if role, _ := c.Locals("role").(string); role != models.OfficerRoleAdmin || officer.Role == "" {
+		officer.Role = models.OfficerRoleOfficer
+	}
+	if ok, err := validateInput(c, &officer); !ok {
+		return err
+	}
 
 	// Check if MarketID exists
 	var market models.Market
@@ -218,19 +223,22 @@ func CreateMarketOfficer(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Market not found or deleted"})
 	}
 
-	// Tambahkan di awal sebelum `DB.Create(...)`
-	var existing models.MarketOfficer
-	if err := database.DB.Where("nik = ?", officer.Nik).First(&existing).Error; err == nil {
-		return c.Status(409).JSON(fiber.Map{"error": "NIK sudah digunakan."})
+	if conflict, err := officerConflict(officer); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa data petugas"})
+	} else if conflict != "" {
+		return c.Status(409).JSON(fiber.Map{"error": conflict})
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(officer.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengenkripsi password"})
 	}
 	officer.Password = string(hashedPassword)
 
 	if err := database.DB.Create(&officer).Error; err != nil {
+		if database.IsDuplicateKeyError(err) {
+			return c.Status(409).JSON(fiber.Map{"error": "NIK atau username sudah digunakan."})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to create officer"})
 	}
 
@@ -245,21 +253,40 @@ func UpdateMarketOfficer(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Market officer not found"})
 	}
 
-	updateData := new(models.MarketOfficer)
-	if err := c.BodyParser(updateData); err != nil {
+	var input officerInput
+	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
+	updateData := &input.MarketOfficer
 
 	officer.Name = updateData.Name
 	officer.Nik = updateData.Nik
 	officer.Phone = updateData.Phone
 	// officer.PhotoURL = updateData.PhotoURL
 	officer.MarketID = updateData.MarketID
-	officer.Password = updateData.Password
 	officer.Username = updateData.Username
 
-	if updateData.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updateData.Password), bcrypt.DefaultCost)
+	if updateData.Role != "" {
+		if !isValidOfficerRole(updateData.Role) {
+			return c.Status(400).JSON(fiber.Map{"error": "Role harus admin atau officer"})
+		}
+		officer.Role = updateData.Role
+	}
+	if ok, err := validateInput(c, &officer); !ok {
+		return err
+	}
+	if conflict, err := officerConflict(officer); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa data petugas"})
+	} else if conflict != "" {
+		return c.Status(409).JSON(fiber.Map{"error": conflict})
+	}
+
+	// Password hanya diganti bila dikirim, hash lama dibiarkan bila field password kosong
+	if input.Password != "" {
+		if err := validatePasswordStrength(input.Password); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengenkripsi password"})
 		}
@@ -267,6 +294,9 @@ func UpdateMarketOfficer(c *fiber.Ctx) error {
 	}
 
 	if err := database.DB.Save(&officer).Error; err != nil {
+		if database.IsDuplicateKeyError(err) {
+			return c.Status(409).JSON(fiber.Map{"error": "NIK atau username sudah digunakan."})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update officer"})
 	}
 
@@ -274,10 +304,172 @@ func UpdateMarketOfficer(c *fiber.Ctx) error {
 }
 
 // Delete market officer
+// Penghapusan diblokir (409) jika petugas masih tercatat sebagai penulis data lain. Dengan
+// ?reassign_to=<id> semua referensi dipindahkan ke petugas tersebut, dengan ?force=true
+// referensinya dikosongkan (NULL). Petugas di-soft delete, NIK dan username-nya tetap terpakai.
 func DeleteMarketOfficer(c *fiber.Ctx) error {
 	id := c.Params("id")
-	if err := database.DB.Delete(&models.MarketOfficer{}, id).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete officer"})
+	force := c.QueryBool("force", false)
+
+	var reassignTo *uint64
+	if value := c.Query("reassign_to"); value != "" {
+		targetID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "reassign_to tidak valid"})
+		}
+		reassignTo = &targetID
+	}
+
+	var blockers fiber.Map
+	var sessions []models.OfficerSession
+	err := database.WithTransaction(func(tx *gorm.DB) error {
+		var officer models.MarketOfficer
+		if err := tx.First(&officer, id).Error; err != nil {
+			return err
+		}
+
+		if reassignTo != nil {
+			if *reassignTo == officer.ID {
+				return fiber.NewError(400, "reassign_to tidak boleh petugas yang sama")
+			}
+			var target models.MarketOfficer
+			if err := tx.Select("id").First(&target, *reassignTo).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(400, "Petugas tujuan reassign_to tidak ditemukan")
+				}
+				return err
+			}
+		} else {
+			deps, err := officerDependencies(tx, officer)
+			if err != nil {
+				return err
+			}
+			if len(deps) > 0 && !force {
+				blockers = deps
+				return errOfficerHasDependencies
+			}
+		}
+
+		if err := releaseOfficerReferences(tx, officer.ID, reassignTo); err != nil {
+			return err
+		}
+
+		// Semua sesi dan refresh token petugas dicabut bersama penghapusannya
+		if err := tx.Where("officer_id = ? AND revoked_at IS NULL", officer.ID).Find(&sessions).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.OfficerSession{}).
+			Where("officer_id = ? AND revoked_at IS NULL", officer.ID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RefreshToken{}).
+			Where("officer_id = ? AND revoked = ?", officer.ID, false).
+			Update("revoked", true).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&officer).Error
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Market officer not found"})
+		}
+		if errors.Is(err, errOfficerHasDependencies) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":    "Petugas masih memiliki data terkait, gunakan ?reassign_to=<id> atau ?force=true untuk tetap menghapus",
+				"blockers": blockers,
+			})
+		}
+		return txErrorResponse(c, err, "Failed to delete officer")
 	}
+
+	// Access token yang masih berlaku langsung ditolak oleh JWTMiddleware
+	for _, session := range sessions {
+		if err := middleware.RevokeToken(session.JTI, session.ExpiresAt); err != nil {
+			logger.FromCtx(c).Error("gagal mencabut token petugas", "officer_id", session.OfficerID, "error", err)
+		}
+	}
+
 	return c.JSON(fiber.Map{"message": "Market officer deleted successfully"})
 }
+
+var errOfficerHasDependencies = errors.New("officer has dependent data")
+
+// officerReferences adalah kolom di tabel lain yang menyimpan id petugas sebagai penulis data
+var officerReferences = []struct {
+	model  any
+	column string
+}{
+	{&models.PriceHistory{}, "officer_id"},
+	{&models.PriceConfirmation{}, "officer_id"},
+	{&models.Price{}, "created_by"},
+	{&models.Price{}, "updated_by"},
+	{&models.Barang{}, "created_by"},
+	{&models.Barang{}, "updated_by"},
+}
+
+// officerDependencies menghitung histori harga dan konfirmasi harga yang ditulis petugas
+func officerDependencies(tx *gorm.DB, officer models.MarketOfficer) (fiber.Map, error) {
+	blockers := fiber.Map{}
+
+	var priceHistories int64
+	if err := tx.Model(&models.PriceHistory{}).
+		Where("officer_id = ?", officer.ID).
+		Count(&priceHistories).Error; err != nil {
+		return nil, err
+	}
+	if priceHistories > 0 {
+		blockers["price_histories"] = priceHistories
+	}
+
+	var priceConfirmations int64
+	if err := tx.Model(&models.PriceConfirmation{}).
+		Where("officer_id = ?", officer.ID).
+		Count(&priceConfirmations).Error; err != nil {
+		return nil, err
+	}
+	if priceConfirmations > 0 {
+		blockers["price_confirmations"] = priceConfirmations
+	}
+
+	return blockers, nil
+}
+
+// releaseOfficerReferences memindahkan semua referensi ke petugas lain (reassignTo), atau
+// mengosongkannya bila reassignTo nil. UpdateColumn dipakai agar updated_at harga dan barang
+// tidak berubah, karena updated_at dipakai aturan edit harian.
+func releaseOfficerReferences(tx *gorm.DB, officerID uint64, reassignTo *uint64) error {
+	for _, ref := range officerReferences {
+		if err := tx.Unscoped().Model(ref.model).
+			Where(ref.column+" = ?", officerID).
+			UpdateColumn(ref.column, reassignTo).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// officerConflict mengembalikan pesan 409 bila NIK atau username sudah dipakai petugas lain.
+// Petugas yang sudah dihapus ikut diperiksa karena unique index tetap berlaku untuk baris soft delete.
+func officerConflict(officer models.MarketOfficer) (string, error) {
+	var others []models.MarketOfficer
+	if err := database.DB.Unscoped().
+		Select("id", "nik", "username", "deleted_at").
+		Where("(nik = ? OR username = ?) AND id <> ?", officer.Nik, officer.Username, officer.ID).
+		Find(&others).Error; err != nil {
+		return "", err
+	}
+	for _, other := range others {
+		field := "Username"
+		if other.Nik == officer.Nik {
+			field = "NIK"
+		}
+		if other.DeletedAt.Valid {
+			return field + " sudah digunakan oleh petugas yang telah dihapus.", nil
+		}
+		return field + " sudah digunakan.", nil
+	}
+	return "", nil
+}
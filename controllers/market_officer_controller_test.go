@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func officerAdminApp() *fiber.App {
+	app := fiber.New()
+	asAdmin := func(c *fiber.Ctx) error {
+		c.Locals("role", models.OfficerRoleAdmin)
+		return c.Next()
+	}
+	app.Post("/api/market-officers", asAdmin, CreateMarketOfficer)
+	app.Put("/api/market-officers/:id", asAdmin, UpdateMarketOfficer)
+	app.Delete("/api/market-officers/:id", asAdmin, DeleteMarketOfficer)
+	return app
+}
+
+// seedOfficers membuat satu pasar dengan dua petugas; Budi sudah menulis histori dan harga
+func seedOfficers(t *testing.T, db *gorm.DB) (budi, sari models.MarketOfficer, price models.Price) {
+	t.Helper()
+	market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	mustCreate(t, db, &market)
+	budi = models.MarketOfficer{Name: "Budi", Nik: "3201010101010001", Username: "budi", MarketID: uint64(market.ID), IsActive: true}
+	sari = models.MarketOfficer{Name: "Sari", Nik: "3201010101010002", Username: "sari", MarketID: uint64(market.ID), IsActive: true}
+	mustCreate(t, db, &budi, &sari)
+
+	price = models.Price{ItemName: "Beras", InitialPrice: 12000, CurrentPrice: 12500, MarketID: market.ID, CreatedBy: &budi.ID, UpdatedBy: &budi.ID}
+	mustCreate(t, db, &price)
+	mustCreate(t, db,
+		&models.PriceHistory{ItemID: price.ID, ItemName: "Beras", CurrentPrice: 12500, MarketID: market.ID, OfficerID: &budi.ID},
+		&models.OfficerSession{JTI: "sesi-budi", OfficerID: budi.ID, IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)},
+	)
+	if _, err := createRefreshToken(db, budi.ID, "sesi-budi"); err != nil {
+		t.Fatal(err)
+	}
+	return budi, sari, price
+}
+
+func deleteOfficer(t *testing.T, app *fiber.App, id uint64, query string) (int, map[string]any) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/market-officers/"+strconv.FormatUint(id, 10)+query, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]any
+	json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body
+}
+
+func TestDeleteMarketOfficerBlockedByHistory(t *testing.T) {
+	db := useTestDB(t)
+	budi, _, _ := seedOfficers(t, db)
+
+	status, body := deleteOfficer(t, officerAdminApp(), budi.ID, "")
+	if status != fiber.StatusConflict {
+		t.Fatalf("status = %d, want 409 (%v)", status, body)
+	}
+	blockers, _ := body["blockers"].(map[string]any)
+	if blockers["price_histories"] != float64(1) {
+		t.Errorf("blockers = %v, want price_histories 1", body["blockers"])
+	}
+
+	var count int64
+	db.Model(&models.MarketOfficer{}).Where("id = ?", budi.ID).Count(&count)
+	if count != 1 {
+		t.Error("petugas terhapus padahal penghapusan diblokir")
+	}
+}
+
+func TestDeleteMarketOfficerForceClearsReferences(t *testing.T) {
+	db := useTestDB(t)
+	budi, _, price := seedOfficers(t, db)
+
+	if status, body := deleteOfficer(t, officerAdminApp(), budi.ID, "?force=true"); status != 200 {
+		t.Fatalf("status = %d, want 200 (%v)", status, body)
+	}
+
+	var officer models.MarketOfficer
+	if err := db.Unscoped().First(&officer, budi.ID).Error; err != nil || !officer.DeletedAt.Valid {
+		t.Errorf("petugas harus di-soft delete, err = %v, deleted_at = %v", err, officer.DeletedAt)
+	}
+	var history models.PriceHistory
+	db.First(&history)
+	if history.OfficerID != nil {
+		t.Errorf("price_histories.officer_id = %d, want NULL", *history.OfficerID)
+	}
+	var stored models.Price
+	db.First(&stored, price.ID)
+	if stored.CreatedBy != nil || stored.UpdatedBy != nil {
+		t.Errorf("prices.created_by/updated_by = %v/%v, want NULL", stored.CreatedBy, stored.UpdatedBy)
+	}
+	if !stored.UpdatedAt.Equal(price.UpdatedAt) {
+		t.Errorf("prices.updated_at berubah dari %v menjadi %v", price.UpdatedAt, stored.UpdatedAt)
+	}
+
+	var activeSessions, activeTokens int64
+	db.Model(&models.OfficerSession{}).Where("officer_id = ? AND revoked_at IS NULL", budi.ID).Count(&activeSessions)
+	db.Model(&models.RefreshToken{}).Where("officer_id = ? AND revoked = ?", budi.ID, false).Count(&activeTokens)
+	if activeSessions != 0 || activeTokens != 0 {
+		t.Errorf("sesi aktif = %d, refresh token aktif = %d, want 0", activeSessions, activeTokens)
+	}
+}
+
+func TestDeleteMarketOfficerReassignsReferences(t *testing.T) {
+	db := useTestDB(t)
+	budi, sari, price := seedOfficers(t, db)
+	app := officerAdminApp()
+
+	if status, _ := deleteOfficer(t, app, budi.ID, "?reassign_to="+strconv.FormatUint(budi.ID, 10)); status != 400 {
+		t.Errorf("reassign ke petugas yang sama: status = %d, want 400", status)
+	}
+	if status, _ := deleteOfficer(t, app, budi.ID, "?reassign_to=999"); status != 400 {
+		t.Errorf("reassign ke petugas yang tidak ada: status = %d, want 400", status)
+	}
+
+	if status, body := deleteOfficer(t, app, budi.ID, "?reassign_to="+strconv.FormatUint(sari.ID, 10)); status != 200 {
+		t.Fatalf("status = %d, want 200 (%v)", status, body)
+	}
+	var history models.PriceHistory
+	db.First(&history)
+	if history.OfficerID == nil || *history.OfficerID != sari.ID {
+		t.Errorf("price_histories.officer_id = %v, want %d", history.OfficerID, sari.ID)
+	}
+	var stored models.Price
+	db.First(&stored, price.ID)
+	if stored.CreatedBy == nil || *stored.CreatedBy != sari.ID {
+		t.Errorf("prices.created_by = %v, want %d", stored.CreatedBy, sari.ID)
+	}
+}
+
+// Laporan sengketa ditulis lewat endpoint publik, sehingga tidak boleh bisa memblokir penghapusan
+func TestDeleteMarketOfficerIgnoresDisputes(t *testing.T) {
+	db := useTestDB(t)
+	_, sari, price := seedOfficers(t, db)
+	mustCreate(t, db, &models.PriceDispute{PriceID: price.ID, ReportedBy: sari.Username, Reason: "harga salah", Status: models.DisputeStatusOpen})
+
+	if status, body := deleteOfficer(t, officerAdminApp(), sari.ID, ""); status != 200 {
+		t.Errorf("status = %d, want 200 (%v)", status, body)
+	}
+}
+
+func TestCreateMarketOfficerRejectsDeletedOfficerIdentity(t *testing.T) {
+	db := useTestDB(t)
+	budi, sari, _ := seedOfficers(t, db)
+	app := officerAdminApp()
+
+	if status, _ := deleteOfficer(t, app, budi.ID, "?force=true"); status != 200 {
+		t.Fatalf("hapus petugas: status = %d", status)
+	}
+
+	tests := []struct {
+		name     string
+		nik      string
+		username string
+		want     string
+	}{
+		{"NIK petugas yang dihapus", budi.Nik, "budi2", "NIK sudah digunakan oleh petugas yang telah dihapus."},
+		{"username petugas yang dihapus", "3201010101010009", budi.Username, "Username sudah digunakan oleh petugas yang telah dihapus."},
+		{"username petugas aktif", "3201010101010009", sari.Username, "Username sudah digunakan."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"name":"Baru","nik":"` + tt.nik + `","username":"` + tt.username + `","password":"rahasia123","market_id":` + strconv.FormatUint(budi.MarketID, 10) + `}`
+			req := httptest.NewRequest("POST", "/api/market-officers", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]any
+			json.NewDecoder(resp.Body).Decode(&got)
+			if resp.StatusCode != fiber.StatusConflict || got["error"] != tt.want {
+				t.Errorf("status = %d, error = %v, want 409 %q", resp.StatusCode, got["error"], tt.want)
+			}
+		})
+	}
+}
+
+func putOfficer(t *testing.T, app *fiber.App, id uint64, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest("PUT", "/api/market-officers/"+strconv.FormatUint(id, 10), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(raw)
+}
+
+func TestUpdateMarketOfficerPassword(t *testing.T) {
+	db := useTestDB(t)
+	budi, _, _ := seedOfficers(t, db)
+	oldHash, _ := bcrypt.GenerateFromPassword([]byte("lama12345"), bcrypt.MinCost)
+	db.Model(&models.MarketOfficer{}).Where("id = ?", budi.ID).UpdateColumn("password", string(oldHash))
+	app := officerAdminApp()
+	fields := `"name":"Budi","nik":"` + budi.Nik + `","username":"budi","market_id":` + strconv.FormatUint(budi.MarketID, 10)
+
+	storedHash := func() string {
+		var officer models.MarketOfficer
+		if err := db.First(&officer, budi.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		return officer.Password
+	}
+
+	status, body := putOfficer(t, app, budi.ID, `{`+fields+`}`)
+	if status != 200 || storedHash() != string(oldHash) {
+		t.Fatalf("tanpa password: status = %d, hash lama harus tetap (%s)", status, body)
+	}
+	if strings.Contains(body, "password") {
+		t.Errorf("response memuat password: %s", body)
+	}
+
+	status, body = putOfficer(t, app, budi.ID, `{`+fields+`,"password":"baru12345"}`)
+	if status != 200 {
+		t.Fatalf("dengan password: status = %d (%s)", status, body)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(storedHash()), []byte("baru12345")); err != nil {
+		t.Errorf("hash baru tidak cocok dengan password baru: %v", err)
+	}
+
+	if status, _ := putOfficer(t, app, budi.ID, `{"password":`); status != 400 {
+		t.Errorf("body rusak: status = %d, want 400", status)
+	}
+}
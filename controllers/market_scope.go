@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// canWriteMarket mengizinkan admin mengubah data pasar mana pun, sedangkan petugas hanya
+// pasar sesuai claim market_id di tokennya. Dipakai setelah JWTMiddleware atau JWTAdminMiddleware.
+func canWriteMarket(c *fiber.Ctx, marketID uint64) bool {
+	if role, _ := c.Locals("role").(string); role == models.OfficerRoleAdmin {
+		return true
+	}
+	ownMarketID, ok := c.Locals("market_id").(uint64)
+	return ok && ownMarketID == marketID
+}
+
+// currentOfficerID mengembalikan officer_id dari token petugas untuk dicatat di histori harga,
+// atau nil bila request tidak berasal dari petugas
+func currentOfficerID(c *fiber.Ctx) *uint64 {
+	if officerID, ok := c.Locals("officer_id").(uint64); ok && officerID != 0 {
+		return &officerID
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Batas jumlah hari dalam satu matriks agar response tetap wajar untuk heatmap
+const matrixMaxDays = 366
+
+type MatrixCell struct {
+	MarketID uint     `json:"market_id"`
+	Price    *float64 `json:"price"`
+	Carried  bool     `json:"carried"`
+}
+
+type MatrixRow struct {
+	Date  string       `json:"date"`
+	Cells []MatrixCell `json:"cells"`
+}
+
+// GetPriceMatrix menampilkan harga satu komoditas di setiap pasar untuk setiap hari (tanggal x pasar).
+// Hari tanpa laporan diisi dengan harga terakhir sebelumnya dan ditandai carried.
+func GetPriceMatrix(c *fiber.Ctx) error {
+	itemID := c.Params("item_id")
+
+	dateRange, err := parseDateRange(c, "start_date", "end_date")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var histories []models.PriceHistory
+	if err := dateRange.Apply(database.DB.Where("item_id = ?", itemID), "created_at").
+		Order("created_at ASC, id ASC").
+		Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+	}
+
+	// Harga terakhir sebelum tanggal awal menjadi nilai awal yang dibawa ke depan
+	var before []models.PriceHistory
+	if dateRange.From != nil {
+		latest := database.DB.Model(&models.PriceHistory{}).
+			Select("market_id, MAX(created_at) AS last_at").
+			Where("item_id = ? AND created_at < ?", itemID, *dateRange.From).
+			Group("market_id")
+		if err := database.DB.Table("price_histories AS ph").
+			Select("ph.*").
+			Joins("JOIN (?) AS l ON l.market_id = ph.market_id AND l.last_at = ph.created_at", latest).
+			Where("ph.item_id = ?", itemID).
+			Order("ph.id ASC").
+			Find(&before).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+		}
+	}
+
+	if len(histories) == 0 && len(before) == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Histori harga tidak ditemukan"})
+	}
+
+	// Tentukan rentang hari: dari parameter, atau dari data yang ada
+	today := startOfDay(time.Now())
+	var start, end time.Time
+	if dateRange.From != nil {
+		start = *dateRange.From
+	} else {
+		start = startOfDay(histories[0].CreatedAt)
+	}
+	if dateRange.To != nil {
+		end = dateRange.To.AddDate(0, 0, -1)
+	} else if len(histories) > 0 {
+		end = startOfDay(histories[len(histories)-1].CreatedAt)
+	} else {
+		end = today
+	}
+	if end.After(today) {
+		end = today
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > matrixMaxDays {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Rentang tanggal maksimal %d hari", matrixMaxDays)})
+	}
+
+	// Harga per pasar per hari, entri terakhir di hari itu yang dipakai
+	itemName := ""
+	lastKnown := make(map[uint]float64)
+	marketSet := make(map[uint]bool)
+	for _, h := range before {
+		lastKnown[h.MarketID] = h.CurrentPrice
+		marketSet[h.MarketID] = true
+		itemName = h.ItemName
+	}
+	dailyPrices := make(map[string]map[uint]float64)
+	for _, h := range histories {
+		day := startOfDay(h.CreatedAt).Format("2006-01-02")
+		if dailyPrices[day] == nil {
+			dailyPrices[day] = make(map[uint]float64)
+		}
+		dailyPrices[day][h.MarketID] = h.CurrentPrice
+		marketSet[h.MarketID] = true
+		itemName = h.ItemName
+	}
+
+	marketIDs := make([]uint, 0, len(marketSet))
+	for id := range marketSet {
+		marketIDs = append(marketIDs, id)
+	}
+	sort.Slice(marketIDs, func(i, j int) bool { return marketIDs[i] < marketIDs[j] })
+
+	var markets []models.Market
+	if err := database.DB.Where("id IN ?", marketIDs).Order("id ASC").Find(&markets).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data pasar"})
+	}
+	marketNames := make(map[uint]string, len(markets))
+	for _, m := range markets {
+		marketNames[m.ID] = m.Name
+	}
+	marketList := make([]fiber.Map, 0, len(marketIDs))
+	for _, id := range marketIDs {
+		marketList = append(marketList, fiber.Map{"market_id": id, "market_name": marketNames[id]})
+	}
+
+	rows := []MatrixRow{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		row := MatrixRow{Date: key, Cells: make([]MatrixCell, 0, len(marketIDs))}
+		for _, marketID := range marketIDs {
+			cell := MatrixCell{MarketID: marketID}
+			if price, ok := dailyPrices[key][marketID]; ok {
+				lastKnown[marketID] = price
+				cell.Price = &price
+			} else if price, ok := lastKnown[marketID]; ok {
+				cell.Price = &price
+				cell.Carried = true
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		rows = append(rows, row)
+	}
+
+	return c.JSON(fiber.Map{
+		"item_id":   itemID,
+		"item_name": itemName,
+		"markets":   marketList,
+		"rows":      rows,
+	})
+}
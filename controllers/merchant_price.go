@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Mode rata-rata harga pedagang: "all" membagi dengan 3 seperti sebelumnya, "nonzero" hanya
+// merata-ratakan pedagang yang disurvei (harga > 0). Bila semua harga 0, hasilnya 0.
+const (
+	avgModeAll     = "all"
+	avgModeNonZero = "nonzero"
+)
+
+func parseAvgMode(c *fiber.Ctx) (string, error) {
+	switch mode := c.Query("avg_mode", avgModeAll); mode {
+	case avgModeAll, avgModeNonZero:
+		return mode, nil
+	default:
+		return "", errors.New("avg_mode harus all atau nonzero")
+	}
+}
+
+func validateMerchantPrices(prices ...float64) error {
+	for _, price := range prices {
+		if price < 0 {
+			return errors.New("harga pedagang tidak boleh negatif")
+		}
+	}
+	return nil
+}
+
+// averageMerchantPrices menghitung harga sekarang dari harga pedagang sesuai mode
+func averageMerchantPrices(mode string, prices ...float64) float64 {
+	var sum float64
+	count := 0
+	for _, price := range prices {
+		if mode == avgModeNonZero && price == 0 {
+			continue
+		}
+		sum += price
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return roundPrice(sum / float64(count))
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAverageMerchantPrices(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		prices []float64
+		want   float64
+	}{
+		{"all membagi tiga", avgModeAll, []float64{10000, 0, 20000}, 10000},
+		{"nonzero melewati pedagang yang tidak disurvei", avgModeNonZero, []float64{10000, 0, 20000}, 15000},
+		{"nonzero satu pedagang", avgModeNonZero, []float64{0, 12500, 0}, 12500},
+		{"nonzero semua nol", avgModeNonZero, []float64{0, 0, 0}, 0},
+		{"all semua nol", avgModeAll, []float64{0, 0, 0}, 0},
+		{"hasil dibulatkan", avgModeAll, []float64{10000, 10000, 10001}, 10000.33},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := averageMerchantPrices(tt.mode, tt.prices...); got != tt.want {
+				t.Errorf("averageMerchantPrices(%s, %v) = %v, want %v", tt.mode, tt.prices, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMerchantPrices(t *testing.T) {
+	if err := validateMerchantPrices(0, 10000, 20000); err != nil {
+		t.Errorf("harga valid ditolak: %v", err)
+	}
+	if err := validateMerchantPrices(10000, -1, 20000); err == nil {
+		t.Error("harga negatif harus ditolak")
+	}
+}
+
+func TestCreateBarangAvgMode(t *testing.T) {
+	db := useTestDB(t)
+	market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	mustCreate(t, db, &market)
+	category := models.Category{Name: "Sayur"}
+	mustCreate(t, db, &category)
+
+	app := fiber.New()
+	app.Post("/api/barang", func(c *fiber.Ctx) error {
+		c.Locals("role", models.OfficerRoleAdmin)
+		return c.Next()
+	}, CreateBarang)
+
+	tests := []struct {
+		name       string
+		query      string
+		prices     [3]float64
+		wantStatus int
+		wantPrice  float64
+	}{
+		{"default all", "", [3]float64{10000, 0, 20000}, 201, 10000},
+		{"nonzero", "?avg_mode=nonzero", [3]float64{10000, 0, 20000}, 201, 15000},
+		{"avg_mode tidak dikenal", "?avg_mode=median", [3]float64{10000, 0, 20000}, 400, 0},
+		{"harga negatif", "", [3]float64{10000, -5000, 20000}, 400, 0},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"nama":"Barang %d","category_id":%d,"market_id":%d,"harga_pedagang1":%v,"harga_pedagang2":%v,"harga_pedagang3":%v}`,
+				i, category.ID, market.ID, tt.prices[0], tt.prices[1], tt.prices[2])
+			req := httptest.NewRequest("POST", "/api/barang"+tt.query, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != 201 {
+				return
+			}
+			var barang models.Barang
+			json.NewDecoder(resp.Body).Decode(&barang)
+			if barang.HargaSekarang != tt.wantPrice {
+				t.Errorf("harga_sekarang = %v, want %v", barang.HargaSekarang, tt.wantPrice)
+			}
+		})
+	}
+}
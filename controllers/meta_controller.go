@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Batas kategori harga untuk filter ?range= di GetPrices
+const (
+	priceRangeMurahMax = 10000
+	priceRangeMahalMin = 50000
+)
+
+// Alasan perubahan harga yang disarankan untuk dropdown di aplikasi
+var priceChangeReasons = []string{
+	"Pasokan berkurang",
+	"Pasokan melimpah",
+	"Permintaan meningkat",
+	"Permintaan menurun",
+	"Harga dari distributor berubah",
+	"Biaya transportasi berubah",
+	"Lainnya",
+}
+
+// GetMeta mengembalikan nilai enum yang dipakai client agar tidak di-hardcode
+func GetMeta(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"direction": []string{"naik", "turun"},
+		"range": []fiber.Map{
+			{"value": "murah", "max": priceRangeMurahMax},
+			{"value": "sedang", "min": priceRangeMurahMax, "max": priceRangeMahalMin},
+			{"value": "mahal", "min": priceRangeMahalMin},
+		},
+		"reasons":          priceChangeReasons,
+		"dispute_statuses": []string{models.DisputeStatusOpen, models.DisputeStatusResolved},
+	})
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Jumlah item default pada daftar movers
+const defaultMoversLimit = 10
+
+type PriceMover struct {
+	ItemID        uint    `json:"item_id"`
+	ItemName      string  `json:"item_name"`
+	MarketID      uint    `json:"market_id"`
+	InitialPrice  float64 `json:"initial_price"`
+	CurrentPrice  float64 `json:"current_price"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// GetPriceMovers menampilkan item dengan perubahan harga terbesar: ?direction=up|down (default up),
+// ?limit= (default 10, maksimal maxPageSize), ?market_id= dan ?from=&to= (default sejak reset harian
+// terakhir sampai sekarang). Perubahan dihitung dari PriceHistory, harga awal dari entri pertama
+// dalam rentang dan harga akhir dari entri terakhir. Item dengan harga awal 0 diabaikan.
+func GetPriceMovers(c *fiber.Ctx) error {
+	direction := c.Query("direction", "up")
+	if direction != "up" && direction != "down" {
+		return c.Status(400).JSON(fiber.Map{"error": "direction harus up atau down"})
+	}
+
+	limit := c.QueryInt("limit", defaultMoversLimit)
+	if limit < 1 {
+		limit = defaultMoversLimit
+	}
+	limit = min(limit, maxPageSize)
+
+	now := time.Now()
+	from, to := lastDailyReset(now), now
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseTimestamp(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "from: " + err.Error()})
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseTimestamp(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "to: " + err.Error()})
+		}
+		to = parsed
+	}
+	if !from.Before(to) {
+		return c.Status(400).JSON(fiber.Map{"error": "from harus sebelum to"})
+	}
+
+	query := database.DB.Where("created_at >= ? AND created_at < ?", from, to)
+	if value := c.Query("market_id"); value != "" {
+		marketID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "market_id tidak valid"})
+		}
+		query = query.Where("market_id = ?", marketID)
+	}
+
+	var histories []models.PriceHistory
+	if err := query.Order("created_at ASC, id ASC").Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+	}
+
+	return c.JSON(fiber.Map{
+		"direction": direction,
+		"from":      from,
+		"to":        to,
+		"items":     rankPriceMovers(histories, direction, limit),
+	})
+}
+
+// rankPriceMovers merangkum histori (terurut naik) per item lalu mengambil limit item dengan
+// perubahan terbesar ke arah direction
+func rankPriceMovers(histories []models.PriceHistory, direction string, limit int) []PriceMover {
+	moversByID := make(map[uint]*PriceMover)
+	var order []uint
+	for _, h := range histories {
+		mover, ok := moversByID[h.ItemID]
+		if !ok {
+			mover = &PriceMover{ItemID: h.ItemID, ItemName: h.ItemName, MarketID: h.MarketID, InitialPrice: h.InitialPrice}
+			moversByID[h.ItemID] = mover
+			order = append(order, h.ItemID)
+		}
+		mover.CurrentPrice = h.CurrentPrice
+	}
+
+	movers := []PriceMover{}
+	for _, itemID := range order {
+		mover := moversByID[itemID]
+		if mover.InitialPrice == 0 {
+			continue
+		}
+		mover.ChangePercent = calculateChangePercent(mover.InitialPrice, mover.CurrentPrice)
+		if (direction == "up" && mover.ChangePercent > 0) || (direction == "down" && mover.ChangePercent < 0) {
+			movers = append(movers, *mover)
+		}
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool {
+		if direction == "down" {
+			return movers[i].ChangePercent < movers[j].ChangePercent
+		}
+		return movers[i].ChangePercent > movers[j].ChangePercent
+	})
+	if len(movers) > limit {
+		movers = movers[:limit]
+	}
+	return movers
+}
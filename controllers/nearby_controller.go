@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	earthRadiusKm         = 6371.0
+	defaultNearbyRadiusKm = 10.0
+)
+
+type NearbyMarket struct {
+	models.Market
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// haversineKm menghitung jarak great-circle antara dua koordinat dalam kilometer
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// GetNearbyMarkets menampilkan pasar dalam radius_km (default 10) dari lat/lng, terdekat lebih dulu.
+// Kandidat disaring dulu di SQL dengan bounding box, lalu jarak pastinya dihitung dengan Haversine.
+func GetNearbyMarkets(c *fiber.Ctx) error {
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return c.Status(400).JSON(fiber.Map{"error": "lat harus berupa angka antara -90 dan 90"})
+	}
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return c.Status(400).JSON(fiber.Map{"error": "lng harus berupa angka antara -180 dan 180"})
+	}
+	radius := defaultNearbyRadiusKm
+	if value := c.Query("radius_km"); value != "" {
+		radius, err = strconv.ParseFloat(value, 64)
+		if err != nil || radius <= 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "radius_km harus lebih dari 0"})
+		}
+	}
+
+	// Bounding box kasar; batas bujur dilewati bila melewati kutub atau garis 180°
+	latDelta := radius / earthRadiusKm * 180 / math.Pi
+	query := database.DB.Where("latitude BETWEEN ? AND ?", lat-latDelta, lat+latDelta)
+	if cosLat := math.Cos(lat * math.Pi / 180); lat+latDelta < 90 && lat-latDelta > -90 && cosLat > 0 {
+		lngDelta := latDelta / cosLat
+		if lng-lngDelta >= -180 && lng+lngDelta <= 180 {
+			query = query.Where("longitude BETWEEN ? AND ?", lng-lngDelta, lng+lngDelta)
+		}
+	}
+	// Pasar yang belum diisi lokasinya tersimpan di 0,0
+	query = query.Where("NOT (latitude = 0 AND longitude = 0)")
+
+	var markets []models.Market
+	if err := query.Find(&markets).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data pasar"})
+	}
+
+	nearby := make([]NearbyMarket, 0, len(markets))
+	for _, m := range markets {
+		distance := haversineKm(lat, lng, m.Latitude, m.Longitude)
+		if distance <= radius {
+			nearby = append(nearby, NearbyMarket{Market: m, DistanceKm: math.Round(distance*100) / 100})
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].DistanceKm < nearby[j].DistanceKm })
+
+	return c.JSON(nearby)
+}
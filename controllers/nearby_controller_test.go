@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want, tolerance        float64
+	}{
+		{"titik yang sama", -6.2, 106.8, -6.2, 106.8, 0, 0.0001},
+		{"Monas ke Bundaran HI", -6.1754, 106.8272, -6.1950, 106.8230, 2.23, 0.05},
+		{"Jakarta ke Bandung", -6.2088, 106.8456, -6.9175, 107.6191, 116.3, 1},
+		{"Jakarta ke Surabaya", -6.2088, 106.8456, -7.2575, 112.7521, 663.3, 2},
+		{"satu derajat bujur di khatulistiwa", 0, 0, 0, 1, 111.19, 0.01},
+		{"melewati garis 180°", 0, 179.5, 0, -179.5, 111.19, 0.01},
+		{"kutub ke kutub", 90, 0, -90, 0, math.Pi * earthRadiusKm, 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := haversineKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("haversineKm = %.4f, want %.4f ± %.4f", got, tt.want, tt.tolerance)
+			}
+			if back := haversineKm(tt.lat2, tt.lng2, tt.lat1, tt.lng1); math.Abs(back-got) > 1e-9 {
+				t.Errorf("jarak tidak simetris: %.6f vs %.6f", got, back)
+			}
+		})
+	}
+}
+
+func TestGetNearbyMarketsValidatesQuery(t *testing.T) {
+	app := fiber.New()
+	app.Get("/markets/nearby", GetNearbyMarkets)
+
+	for _, query := range []string{
+		"",
+		"?lat=abc&lng=106.8",
+		"?lat=-91&lng=106.8",
+		"?lat=-6.2&lng=181",
+		"?lat=-6.2&lng=106.8&radius_km=0",
+		"?lat=-6.2&lng=106.8&radius_km=-3",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/markets/nearby"+query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400", query, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetNewCommodities menampilkan komoditas yang pertama kali tercatat pada tanggal tertentu
+// (default hari ini), yaitu yang entri PriceHistory paling awalnya jatuh di tanggal tersebut
+func GetNewCommodities(c *fiber.Ctx) error {
+	day := startOfDay(time.Now())
+	if value := c.Query("date"); value != "" {
+		date, err := parseDate(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		day = date
+	}
+
+	// Entri paling awal untuk setiap item, dibatasi ke satu pasar bila market_id diisi
+	firstSeen := database.DB.Model(&models.PriceHistory{}).
+		Select("item_id, MIN(created_at) AS first_seen").
+		Group("item_id")
+	if marketID := c.Query("market_id"); marketID != "" {
+		firstSeen = firstSeen.Where("market_id = ?", marketID)
+	}
+	firstSeen = firstSeen.Having("MIN(created_at) >= ? AND MIN(created_at) < ?", day, day.AddDate(0, 0, 1))
+
+	var histories []models.PriceHistory
+	query := database.DB.Table("price_histories AS ph").
+		Select("ph.*").
+		Joins("JOIN (?) AS f ON f.item_id = ph.item_id AND f.first_seen = ph.created_at", firstSeen)
+	if marketID := c.Query("market_id"); marketID != "" {
+		query = query.Where("ph.market_id = ?", marketID)
+	}
+	if err := query.Order("ph.created_at ASC, ph.id ASC").Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data komoditas baru"})
+	}
+
+	// Satu item bisa punya beberapa entri dengan created_at yang sama, ambil yang pertama
+	seen := make(map[uint]bool)
+	commodities := []fiber.Map{}
+	for _, h := range histories {
+		if seen[h.ItemID] {
+			continue
+		}
+		seen[h.ItemID] = true
+
+		commodities = append(commodities, fiber.Map{
+			"item_id":           h.ItemID,
+			"item_name":         h.ItemName,
+			"market_id":         h.MarketID,
+			"category_id":       h.CategoryID,
+			"first_price":       h.CurrentPrice,
+			"first_reported_at": h.CreatedAt,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"date":        day.Format("2006-01-02"),
+		"total":       len(commodities),
+		"commodities": commodities,
+	})
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetOfficerPriceChanges menampilkan histori perubahan harga yang dilakukan satu petugas, terbaru
+// dulu, dengan filter ?from=&to= (YYYY-MM-DD) dan pagination. Histori sebelum kolom officer_id
+// ada tidak memiliki petugas sehingga tidak ikut tampil.
+func GetOfficerPriceChanges(c *fiber.Ctx) error {
+	officerID, ok, err := officerIDParam(c)
+	if !ok {
+		return err
+	}
+
+	dateRange, err := parseDateRange(c, "from", "to")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	pagination := parsePagination(c, 50)
+
+	query := dateRange.Apply(database.DB.Model(&models.PriceHistory{}).Where("officer_id = ?", officerID), "created_at")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung histori harga"})
+	}
+
+	var histories []models.PriceHistory
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset()).
+		Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+	}
+
+	meta := writePagination(c, pagination, total)
+	meta["data"] = histories
+	return c.JSON(meta)
+}
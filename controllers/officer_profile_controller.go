@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// loadCurrentOfficer mengambil officer dari klaim officer_id beserta pasarnya. Bila ok bernilai
+// false, response error sudah ditulis dan err harus langsung dikembalikan oleh handler.
+func loadCurrentOfficer(c *fiber.Ctx) (officer models.MarketOfficer, ok bool, err error) {
+	officerID, _ := c.Locals("officer_id").(uint64)
+	if officerID == 0 {
+		return officer, false, c.Status(401).JSON(fiber.Map{"error": "Token tidak memuat officer_id"})
+	}
+	if dbErr := database.DB.Preload("Market").First(&officer, officerID).Error; dbErr != nil {
+		return officer, false, c.Status(404).JSON(fiber.Map{"error": "Officer not found"})
+	}
+	return officer, true, nil
+}
+
+// GetMyProfile menampilkan profil officer yang sedang login
+func GetMyProfile(c *fiber.Ctx) error {
+	officer, ok, err := loadCurrentOfficer(c)
+	if !ok {
+		return err
+	}
+	return c.JSON(NewOfficerResponse(officer))
+}
+
+// UpdateMyProfile memperbarui nama, telepon dan foto officer yang sedang login.
+// Field lain (username, pasar, role, dst.) diabaikan walaupun dikirim.
+func UpdateMyProfile(c *fiber.Ctx) error {
+	officer, ok, err := loadCurrentOfficer(c)
+	if !ok {
+		return err
+	}
+
+	var input struct {
+		Name     *string `json:"name"`
+		Phone    *string `json:"phone" validate:"omitempty,phone"`
+		ImageURL *string `json:"image_url"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if input.Phone != nil {
+		*input.Phone = strings.TrimSpace(*input.Phone)
+	}
+	if ok, err := validateInput(c, &input); !ok {
+		return err
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Nama tidak boleh kosong"})
+		}
+		updates["name"] = name
+		officer.Name = name
+	}
+	if input.Phone != nil {
+		updates["phone"] = *input.Phone
+		officer.Phone = *input.Phone
+	}
+	if input.ImageURL != nil {
+		updates["image_url"] = strings.TrimSpace(*input.ImageURL)
+		officer.ImageURL = strings.TrimSpace(*input.ImageURL)
+	}
+
+	if len(updates) > 0 {
+		if err := database.DB.Model(&models.MarketOfficer{}).Where("id = ?", officer.ID).Updates(updates).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memperbarui profil"})
+		}
+	}
+
+	return c.JSON(NewOfficerResponse(officer))
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultMaxPageSize adalah batas atas limit per halaman, bisa diubah lewat env MAX_PAGE_SIZE
+const DefaultMaxPageSize = 100
+
+var maxPageSize = DefaultMaxPageSize
+
+func init() {
+	if value, err := strconv.Atoi(os.Getenv("MAX_PAGE_SIZE")); err == nil && value > 0 {
+		maxPageSize = value
+	}
+}
+
+// Pagination menyimpan parameter page/limit yang sudah dinormalisasi dari query string.
+// Capped bernilai true bila limit yang diminta melebihi maxPageSize.
+type Pagination struct {
+	Page   int
+	Limit  int
+	Capped bool
+}
+
+// parsePagination membaca ?page= dan ?limit= dengan nilai default bila kosong atau tidak valid
+func parsePagination(c *fiber.Ctx, defaultLimit int) Pagination {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", defaultLimit)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultLimit
+	}
+
+	capped := false
+	if limit > maxPageSize {
+		limit = maxPageSize
+		capped = true
+	}
+
+	return Pagination{Page: page, Limit: limit, Capped: capped}
+}
+
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+func (p Pagination) TotalPages(total int64) int {
+	return int((total + int64(p.Limit) - 1) / int64(p.Limit))
+}
+
+// writePagination menambahkan header Link (RFC 5988) untuk first/prev/next/last
+// dan mengembalikan metadata pagination untuk body response.
+func writePagination(c *fiber.Ctx, p Pagination, total int64) fiber.Map {
+	totalPages := p.TotalPages(total)
+	lastPage := totalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	links := []string{
+		paginationLink(c, 1, "first"),
+	}
+	if p.Page > 1 {
+		prev := p.Page - 1
+		if prev > lastPage {
+			prev = lastPage
+		}
+		links = append(links, paginationLink(c, prev, "prev"))
+	}
+	if p.Page < totalPages {
+		links = append(links, paginationLink(c, p.Page+1, "next"))
+	}
+	links = append(links, paginationLink(c, lastPage, "last"))
+
+	c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Set("X-Page-Limit", strconv.Itoa(p.Limit))
+
+	return fiber.Map{
+		"page":          p.Page,
+		"limit":         p.Limit,
+		"limit_capped":  p.Capped,
+		"max_page_size": maxPageSize,
+		"total":         total,
+		"total_pages":   totalPages,
+	}
+}
+
+func paginationLink(c *fiber.Ctx, page int, rel string) string {
+	u, err := url.Parse(c.OriginalURL())
+	if err != nil {
+		u = &url.URL{Path: c.Path()}
+	}
+
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	u.RawQuery = query.Encode()
+
+	return fmt.Sprintf("<%s%s>; rel=\"%s\"", c.BaseURL(), u.RequestURI(), rel)
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParsePagination(t *testing.T) {
+	previous := maxPageSize
+	maxPageSize = 100
+	t.Cleanup(func() { maxPageSize = previous })
+
+	tests := []struct {
+		query string
+		want  Pagination
+	}{
+		{"", Pagination{Page: 1, Limit: 20}},
+		{"?page=3&limit=50", Pagination{Page: 3, Limit: 50}},
+		{"?page=0&limit=-5", Pagination{Page: 1, Limit: 20}},
+		{"?page=abc&limit=xyz", Pagination{Page: 1, Limit: 20}},
+		{"?limit=100", Pagination{Page: 1, Limit: 100}},
+		{"?limit=100000", Pagination{Page: 1, Limit: 100, Capped: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			app := fiber.New()
+			var got Pagination
+			app.Get("/", func(c *fiber.Ctx) error {
+				got = parsePagination(c, 20)
+				return nil
+			})
+			if _, err := app.Test(httptest.NewRequest("GET", "/"+tt.query, nil)); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("parsePagination = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePaginationReportsCappedLimit(t *testing.T) {
+	previous := maxPageSize
+	maxPageSize = 10
+	t.Cleanup(func() { maxPageSize = previous })
+
+	app := fiber.New()
+	app.Get("/items", func(c *fiber.Ctx) error {
+		return c.JSON(writePagination(c, parsePagination(c, 5), 35))
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/items?limit=1000&page=2", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		t.Fatal(err)
+	}
+
+	if meta["limit"] != float64(10) || meta["limit_capped"] != true || meta["max_page_size"] != float64(10) {
+		t.Errorf("meta = %v, want limit 10 yang dibatasi max_page_size 10", meta)
+	}
+	if meta["total_pages"] != float64(4) {
+		t.Errorf("total_pages = %v, want 4", meta["total_pages"])
+	}
+	if got := resp.Header.Get("X-Page-Limit"); got != "10" {
+		t.Errorf("X-Page-Limit = %q, want 10", got)
+	}
+	link := resp.Header.Get("Link")
+	for _, rel := range []string{`rel="first"`, `rel="prev"`, `rel="next"`, `rel="last"`} {
+		if !strings.Contains(link, rel) {
+			t.Errorf("Link tidak mengandung %s: %s", rel, link)
+		}
+	}
+	if !strings.Contains(link, "page=4") {
+		t.Errorf("Link last harus page=4: %s", link)
+	}
+}
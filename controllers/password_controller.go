@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+// validatePasswordStrength mewajibkan minimal 8 karakter yang memuat huruf dan angka
+func validatePasswordStrength(password string) error {
+	if len(password) < minPasswordLength {
+		return errors.New("password baru minimal 8 karakter")
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return errors.New("password baru harus mengandung huruf dan angka")
+	}
+	return nil
+}
+
+// ChangeMyPassword mengganti password officer yang sedang login. Officer diambil dari klaim
+// officer_id di token, bukan dari path, sehingga tidak bisa mengganti password officer lain.
+func ChangeMyPassword(c *fiber.Ctx) error {
+	officerID, _ := c.Locals("officer_id").(uint64)
+	if officerID == 0 {
+		return c.Status(401).JSON(fiber.Map{"error": "Token tidak memuat officer_id"})
+	}
+
+	var req struct {
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.OldPassword == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "old_password dan new_password wajib diisi"})
+	}
+
+	var officer models.MarketOfficer
+	if err := database.DB.First(&officer, officerID).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Officer not found"})
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(officer.Password), []byte(req.OldPassword)); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Password lama salah"})
+	}
+	if err := validatePasswordStrength(req.NewPassword); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.NewPassword == req.OldPassword {
+		return c.Status(400).JSON(fiber.Map{"error": "Password baru harus berbeda dari password lama"})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to hash password"})
+	}
+	if err := database.DB.Model(&officer).Update("password", string(hashedPassword)).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan password"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Password berhasil diubah"})
+}
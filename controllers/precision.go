@@ -0,0 +1,32 @@
+package controllers
+
+import "math"
+
+// Semua harga dan persentase disimpan dengan 2 angka desimal. Perbandingan harga memakai
+// toleransi priceEpsilon agar noise floating point (mis. 17000.000000002) tidak dianggap
+// sebagai perubahan harga.
+const priceEpsilon = 0.005
+
+func roundPrice(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// pricesEqual membandingkan dua harga dengan toleransi priceEpsilon, jangan pakai == atau !=
+// untuk harga karena mis. 0.1+0.2 tidak persis sama dengan 0.3
+func pricesEqual(a, b float64) bool {
+	return math.Abs(a-b) < priceEpsilon
+}
+
+// calculateChangePercent menghitung persentase perubahan harga, 0 bila harga awal 0
+func calculateChangePercent(initial, current float64) float64 {
+	if initial <= 0 {
+		return 0
+	}
+	return roundPrice(((current - initial) / initial) * 100)
+}
+
+// priceHistoryNeeded bernilai false bila harga tidak berubah dan alasannya sama, agar histori
+// tidak terisi baris datar yang membuat grafik dashboard mendatar
+func priceHistoryNeeded(previousPrice, newPrice float64, previousReason, newReason string) bool {
+	return !pricesEqual(previousPrice, newPrice) || previousReason != newReason
+}
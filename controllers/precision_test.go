@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+)
+
+// Variabel, bukan konstanta, agar penjumlahan terjadi saat runtime di float64 (konstanta
+// dihitung presisi tak terbatas oleh compiler sehingga 0.1+0.2 persis 0.3)
+var pointOne, pointTwo = 0.1, 0.2
+
+func TestRoundPrice(t *testing.T) {
+	tests := []struct {
+		value, want float64
+	}{
+		{pointOne + pointTwo, 0.3},
+		{17000.000000002, 17000},
+		{12345.675, 12345.68},
+		{12345.674, 12345.67},
+		{-2.555, -2.56},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := roundPrice(tt.value); got != tt.want {
+			t.Errorf("roundPrice(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPricesEqual(t *testing.T) {
+	if pointOne+pointTwo == 0.3 {
+		t.Fatal("0.1+0.2 pada float64 seharusnya tidak persis 0.3")
+	}
+	tests := []struct {
+		a, b float64
+		want bool
+	}{
+		{pointOne + pointTwo, 0.3, true},
+		{17000.000000002, 17000, true},
+		{15000, 15000.004, true},
+		{15000, 15000.01, false},
+		{15000, 16000, false},
+	}
+	for _, tt := range tests {
+		if got := pricesEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("pricesEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateChangePercent(t *testing.T) {
+	tests := []struct {
+		initial, current, want float64
+	}{
+		{10000, 12500, 25},
+		{12000, 9000, -25},
+		{3, 4, 33.33},
+		{15000, 15000, 0},
+		{0, 15000, 0},
+		{-5, 15000, 0},
+		{0.3, pointOne + pointTwo, 0},
+	}
+	for _, tt := range tests {
+		got := calculateChangePercent(tt.initial, tt.current)
+		if got != tt.want || math.IsNaN(got) || math.IsInf(got, 0) {
+			t.Errorf("calculateChangePercent(%v, %v) = %v, want %v", tt.initial, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestPriceHistoryNeeded(t *testing.T) {
+	tests := []struct {
+		name                      string
+		previousPrice, newPrice   float64
+		previousReason, newReason string
+		want                      bool
+	}{
+		{"harga dan alasan sama", 15000, 15000, "stok aman", "stok aman", false},
+		{"noise floating point", 0.3, pointOne + pointTwo, "", "", false},
+		{"harga berubah", 15000, 16000, "", "", true},
+		{"alasan berubah", 15000, 15000, "", "panen raya", true},
+	}
+	for _, tt := range tests {
+		if got := priceHistoryNeeded(tt.previousPrice, tt.newPrice, tt.previousReason, tt.newReason); got != tt.want {
+			t.Errorf("%s: priceHistoryNeeded = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
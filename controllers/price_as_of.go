@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type historyKey struct {
+	ItemID   uint
+	MarketID uint
+}
+
+// pricesAsOf mengganti nilai harga saat ini dengan entri PriceHistory terakhir yang tercatat
+// sebelum akhir hari asOf. Komoditas yang belum punya histori pada tanggal itu tidak dikembalikan.
+func pricesAsOf(query *gorm.DB, asOf time.Time) ([]models.Price, error) {
+	cutoff := asOf.AddDate(0, 0, 1)
+
+	// Harga yang dihapus setelah tanggal tersebut tetap ikut, karena saat itu masih ada
+	var prices []models.Price
+	if err := query.Unscoped().
+		Where("prices.deleted_at IS NULL OR prices.deleted_at >= ?", cutoff).
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	if len(prices) == 0 {
+		return prices, nil
+	}
+
+	itemIDs := make([]uint, 0, len(prices))
+	for _, p := range prices {
+		itemIDs = append(itemIDs, p.ItemID)
+	}
+
+	// Entri terakhir <= tanggal untuk setiap pasangan item dan pasar
+	latest := database.DB.Model(&models.PriceHistory{}).
+		Select("item_id, market_id, MAX(created_at) AS last_at").
+		Where("created_at < ? AND item_id IN ?", cutoff, itemIDs).
+		Group("item_id, market_id")
+
+	var histories []models.PriceHistory
+	if err := database.DB.Table("price_histories AS ph").
+		Select("ph.*").
+		Joins("JOIN (?) AS l ON l.item_id = ph.item_id AND l.market_id = ph.market_id AND l.last_at = ph.created_at", latest).
+		Order("ph.id ASC").
+		Find(&histories).Error; err != nil {
+		return nil, err
+	}
+
+	// Bila ada beberapa entri dengan created_at yang sama, id terbesar yang dipakai
+	lastByKey := make(map[historyKey]models.PriceHistory, len(histories))
+	for _, h := range histories {
+		lastByKey[historyKey{h.ItemID, h.MarketID}] = h
+	}
+
+	result := make([]models.Price, 0, len(prices))
+	for _, p := range prices {
+		h, ok := lastByKey[historyKey{p.ItemID, p.MarketID}]
+		if !ok {
+			continue
+		}
+		p.InitialPrice = h.InitialPrice
+		p.CurrentPrice = h.CurrentPrice
+		p.ChangePercent = h.ChangePercent
+		p.Reason = h.Reason
+		p.UpdatedAt = h.CreatedAt
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// matchesPriceFilters menerapkan filter direction dan range pada nilai yang sudah dihitung di memori
+func matchesPriceFilters(p models.Price, direction, priceRange string) bool {
+	switch direction {
+	case "naik":
+		if !(p.CurrentPrice > p.InitialPrice) {
+			return false
+		}
+	case "turun":
+		if !(p.CurrentPrice < p.InitialPrice) {
+			return false
+		}
+	}
+
+	switch priceRange {
+	case "murah":
+		return p.CurrentPrice < priceRangeMurahMax
+	case "sedang":
+		return p.CurrentPrice >= priceRangeMurahMax && p.CurrentPrice <= priceRangeMahalMin
+	case "mahal":
+		return p.CurrentPrice > priceRangeMahalMin
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Batas jumlah item dalam satu request bulk
+const maxBulkPriceItems = 500
+
+type BulkPriceItem struct {
+	ItemID       uint    `json:"item_id"`
+	MarketID     uint    `json:"market_id"`
+	CurrentPrice float64 `json:"current_price"`
+	Reason       string  `json:"reason"`
+	Force        bool    `json:"force"` // Lewati batas perubahan harga untuk item ini
+}
+
+type BulkPriceResult struct {
+	Index   int           `json:"index"`
+	ItemID  uint          `json:"item_id"`
+	Success bool          `json:"success"`
+	Error   string        `json:"error,omitempty"`
+	Price   *models.Price `json:"price,omitempty"`
+}
+
+// errBulkItemFailed membatalkan transaksi bulk mode atomic saat ada item yang gagal
+var errBulkItemFailed = errors.New("bulk item failed")
+
+// BulkUpdatePrices memperbarui banyak harga sekaligus (mis. survei harian satu pasar) dalam satu
+// transaksi. Secara default item yang gagal dilewati lewat savepoint dan dilaporkan per item;
+// dengan ?atomic=true satu kegagalan membatalkan seluruh perubahan.
+func BulkUpdatePrices(c *fiber.Ctx) error {
+	atomic := c.QueryBool("atomic", false)
+
+	var items []BulkPriceItem
+	if err := c.BodyParser(&items); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Body harus berupa array item harga"})
+	}
+	if len(items) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Minimal satu item harga"})
+	}
+	if len(items) > maxBulkPriceItems {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Maksimal %d item per request", maxBulkPriceItems)})
+	}
+
+	var results []BulkPriceResult
+	failed := 0
+
+	// Hasil disusun ulang dari awal pada setiap percobaan, jadi aman diulang saat deadlock
+	err := database.WithRetry(txMaxAttempts, func() error {
+		results = make([]BulkPriceResult, len(items))
+		failed = 0
+		return database.WithTransaction(func(tx *gorm.DB) error {
+			for i, item := range items {
+				results[i] = BulkPriceResult{Index: i, ItemID: item.ItemID}
+
+				savepoint := fmt.Sprintf("bulk_item_%d", i)
+				if !atomic {
+					if err := tx.SavePoint(savepoint).Error; err != nil {
+						return err
+					}
+				}
+
+				price, err := applyBulkPriceItem(c, tx, item)
+				if err != nil {
+					failed++
+					results[i].Error = err.Error()
+					if atomic {
+						results = results[:i+1]
+						return errBulkItemFailed
+					}
+					if err := tx.RollbackTo(savepoint).Error; err != nil {
+						return err
+					}
+					continue
+				}
+
+				results[i].Success = true
+				results[i].Price = price
+			}
+			return nil
+		})
+	})
+	if errors.Is(err, errBulkItemFailed) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Semua perubahan dibatalkan karena ada item yang gagal",
+			"atomic":  true,
+			"results": results,
+		})
+	}
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to commit transaction")
+	}
+
+	return c.JSON(fiber.Map{
+		"atomic":    atomic,
+		"total":     len(items),
+		"succeeded": len(items) - failed,
+		"failed":    failed,
+		"results":   results,
+	})
+}
+
+// applyBulkPriceItem menerapkan satu item: update harga, tulis PriceHistory, sinkron ke barang.
+// Harga milik pasar lain ditolak kecuali untuk admin, dan aturan edit harian serta batas perubahan
+// harga sama dengan UpdatePrice.
+func applyBulkPriceItem(c *fiber.Ctx, tx *gorm.DB, item BulkPriceItem) (*models.Price, error) {
+	if item.ItemID == 0 {
+		return nil, errors.New("item_id wajib diisi")
+	}
+	if item.CurrentPrice <= 0 {
+		return nil, errors.New("current_price harus lebih dari 0")
+	}
+
+	query := tx.Where("item_id = ?", item.ItemID)
+	if item.MarketID != 0 {
+		query = query.Where("market_id = ?", item.MarketID)
+	}
+	var prices []models.Price
+	if err := query.Limit(2).Find(&prices).Error; err != nil {
+		return nil, errors.New("gagal mengambil data harga")
+	}
+	switch len(prices) {
+	case 0:
+		return nil, errors.New("harga tidak ditemukan")
+	case 2:
+		return nil, errors.New("item_id ada di beberapa pasar, sertakan market_id")
+	}
+	price := prices[0]
+	if !canWriteMarket(c, uint64(price.MarketID)) {
+		return nil, errors.New("harga milik pasar lain, akses ditolak")
+	}
+	if err := checkPriceUpdate(price, roundPrice(item.CurrentPrice), item.Force, time.Now()); err != nil {
+		return nil, err
+	}
+
+	previousReason := price.Reason
+	price.InitialPrice = price.CurrentPrice
+	price.CurrentPrice = roundPrice(item.CurrentPrice)
+	price.ChangePercent = calculateChangePercent(price.InitialPrice, price.CurrentPrice)
+	price.Reason = item.Reason
+	price.UpdatedAt = time.Now().UTC()
+	price.UpdatedBy = currentOfficerID(c)
+
+	if err := tx.Save(&price).Error; err != nil {
+		return nil, errors.New("gagal menyimpan harga")
+	}
+
+	if priceHistoryNeeded(price.InitialPrice, price.CurrentPrice, previousReason, price.Reason) {
+		history := models.PriceHistory{
+			ItemID:        price.ItemID,
+			ItemName:      price.ItemName,
+			InitialPrice:  price.InitialPrice,
+			CurrentPrice:  price.CurrentPrice,
+			Reason:        price.Reason,
+			MarketID:      price.MarketID,
+			CategoryID:    price.CategoryID,
+			ChangePercent: price.ChangePercent,
+			OfficerID:     currentOfficerID(c),
+			CreatedAt:     time.Now(),
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return nil, errors.New("gagal menyimpan histori harga")
+		}
+	}
+
+	if err := SyncPriceWithBarang(price.ID, tx); err != nil {
+		return nil, fmt.Errorf("gagal sinkron dengan barang: %v", err)
+	}
+
+	return &price, nil
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ConfirmPriceUnchanged mencatat bahwa petugas sudah mengecek harga hari ini dan harganya
+// tidak berubah, tanpa mengubah data harga maupun menambah PriceHistory. Konfirmasi kedua
+// oleh petugas yang sama di hari yang sama mengembalikan konfirmasi yang sudah ada.
+func ConfirmPriceUnchanged(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var price models.Price
+	if err := database.DB.First(&price, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Price not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+	}
+
+	officerID, _ := c.Locals("officer_id").(uint64)
+	marketID, _ := c.Locals("market_id").(uint64)
+	username, _ := c.Locals("username").(string)
+
+	if uint64(price.MarketID) != marketID {
+		return c.Status(403).JSON(fiber.Map{"error": "Akses ditolak untuk market ini"})
+	}
+
+	now := time.Now()
+	today := startOfDay(now)
+
+	var existing models.PriceConfirmation
+	err := database.DB.
+		Where("price_id = ? AND officer_id = ? AND confirmed_at >= ? AND confirmed_at < ?", price.ID, officerID, today, today.AddDate(0, 0, 1)).
+		First(&existing).Error
+	if err == nil {
+		return c.JSON(existing)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa konfirmasi harga"})
+	}
+
+	confirmation := models.PriceConfirmation{
+		PriceID:     price.ID,
+		ItemID:      price.ItemID,
+		MarketID:    price.MarketID,
+		OfficerID:   &officerID,
+		Username:    username,
+		Price:       price.CurrentPrice,
+		ConfirmedAt: now,
+	}
+	if err := database.DB.Create(&confirmation).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan konfirmasi harga"})
+	}
+
+	return c.Status(201).JSON(confirmation)
+}
@@ -2,25 +2,74 @@ package controllers
 
 import (
 	"backend/database"
+	"backend/logger"
 	"backend/models"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"fmt"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
-func GetPrices(c *fiber.Ctx) error {
-	marketID := c.Query("market_id")
-	categoryID := c.Query("category_id")
+// priceSortOrders adalah whitelist nilai ?sort= untuk GetPrices. Klausa ORDER BY hanya diambil
+// dari map ini, input user tidak pernah disisipkan langsung ke SQL.
+var priceSortOrders = map[string]struct {
+	Clause string
+	Less   func(a, b models.Price) bool
+}{
+	"price_asc":    {"current_price ASC, id ASC", func(a, b models.Price) bool { return a.CurrentPrice < b.CurrentPrice }},
+	"price_desc":   {"current_price DESC, id ASC", func(a, b models.Price) bool { return a.CurrentPrice > b.CurrentPrice }},
+	"change_asc":   {"change_percent ASC, id ASC", func(a, b models.Price) bool { return a.ChangePercent < b.ChangePercent }},
+	"change_desc":  {"change_percent DESC, id ASC", func(a, b models.Price) bool { return a.ChangePercent > b.ChangePercent }},
+	"name_asc":     {"item_name ASC, id ASC", func(a, b models.Price) bool { return a.ItemName < b.ItemName }},
+	"name_desc":    {"item_name DESC, id ASC", func(a, b models.Price) bool { return a.ItemName > b.ItemName }},
+	"updated_desc": {"updated_at DESC, id DESC", func(a, b models.Price) bool { return a.UpdatedAt.After(b.UpdatedAt) }},
+}
 
-	var prices []models.Price
-	query := database.DB.Preload("Market").Preload("Category")
+// basePriceQuery menerapkan filter search, market_id dan category_id dari query string.
+// market_id dan category_id boleh berisi beberapa ID dipisah koma, mis. market_id=1,2,3.
+func basePriceQuery(c *fiber.Ctx) (*gorm.DB, error) {
+	query := database.DB.Model(&models.Price{})
 
 	if search := c.Query("search"); search != "" {
 		query = query.Where("item_name LIKE ?", "%"+search+"%")
 	}
+	for _, column := range []string{"market_id", "category_id"} {
+		value := c.Query(column)
+		if value == "" {
+			continue
+		}
+		ids, err := parseIDList(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", column, err)
+		}
+		query = query.Where(column+" IN ?", ids)
+	}
+	return query, nil
+}
+
+// parseIDList membaca daftar ID dipisah koma, setiap ID harus bilangan bulat positif
+func parseIDList(value string) ([]uint64, error) {
+	parts := strings.Split(value, ",")
+	ids := make([]uint64, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("%q bukan ID yang valid", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
 
+// applyPriceFilters menerapkan filter direction, range dan rentang tanggal pada nilai harga saat ini
+func applyPriceFilters(c *fiber.Ctx, query *gorm.DB) (*gorm.DB, error) {
 	switch c.Query("direction") {
 	case "naik":
 		query = query.Where("current_price > initial_price")
@@ -30,125 +79,206 @@ func GetPrices(c *fiber.Ctx) error {
 
 	switch c.Query("range") {
 	case "murah":
-		query = query.Where("current_price < ?", 10000)
+		query = query.Where("current_price < ?", priceRangeMurahMax)
 	case "sedang":
-		query = query.Where("current_price BETWEEN ? AND ?", 10000, 50000)
+		query = query.Where("current_price BETWEEN ? AND ?", priceRangeMurahMax, priceRangeMahalMin)
 	case "mahal":
-		query = query.Where("current_price > ?", 50000)
+		query = query.Where("current_price > ?", priceRangeMahalMin)
 	}
 
-	if marketID != "" {
-		query = query.Where("market_id = ?", marketID)
-	}
-	if categoryID != "" {
-		query = query.Where("category_id = ?", categoryID)
+	dateRange, err := parseDateRange(c, "start_date", "end_date")
+	if err != nil {
+		return nil, err
 	}
+	return dateRange.Apply(query, "updated_at"), nil
+}
 
-	startDate := c.Query("start_date")
-	endDate := c.Query("end_date")
+func GetPrices(c *fiber.Ctx) error {
+	pagination := parsePagination(c, 20)
 
-	if startDate != "" && endDate != "" {
-		query = query.Where("updated_at BETWEEN ? AND ?", startDate+" 00:00:00", endDate+" 23:59:59")
+	sortOrder, ok := priceSortOrders[c.Query("sort", "updated_desc")]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "sort harus salah satu dari price_asc, price_desc, change_asc, change_desc, name_asc, name_desc, updated_desc",
+		})
 	}
 
-	if err := query.Find(&prices).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+	var prices []models.Price
+	query, err := basePriceQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	fmt.Printf("✅ Jumlah data harga: %d\n", len(prices))
+	// ?as_of=<tanggal> mengembalikan nilai terakhir dari PriceHistory per tanggal tersebut
+	if value := c.Query("as_of"); value != "" {
+		if c.Query("start_date") != "" || c.Query("end_date") != "" {
+			return c.Status(400).JSON(fiber.Map{"error": "as_of tidak bisa digabung dengan start_date/end_date"})
+		}
+		asOf, err := parseDate(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 
-	return c.JSON(prices)
-}
-func GetPriceByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var price models.Price
-	if err := database.DB.First(&price, id).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Price not found"})
-	}
+		historical, err := pricesAsOf(query.Preload("Market").Preload("Category"), asOf)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+		}
 
-	var prices []models.Price
+		direction, priceRange := c.Query("direction"), c.Query("range")
+		prices = make([]models.Price, 0, len(historical))
+		for _, p := range historical {
+			if matchesPriceFilters(p, direction, priceRange) {
+				prices = append(prices, p)
+			}
+		}
 
-	startDate := c.Query("start_date")
-	endDate := c.Query("end_date")
+		// Nilai historis dihitung di memori, jadi diurutkan dan dipotong per halaman setelah filter
+		sort.SliceStable(prices, func(i, j int) bool { return sortOrder.Less(prices[i], prices[j]) })
+		total := int64(len(prices))
+		start := min(pagination.Offset(), len(prices))
+		end := min(start+pagination.Limit, len(prices))
+		prices = prices[start:end]
 
-	query := database.DB.Model(&models.Price{})
-
-	if startDate != "" && endDate != "" {
-		query = query.Where("updated_at BETWEEN ? AND ?", startDate+" 00:00:00", endDate+" 23:59:59")
+		applyPriceFormatting(prices, c.QueryBool("formatted"))
+		meta := writePagination(c, pagination, total)
+		meta["data"] = prices
+		return c.JSON(meta)
 	}
 
-	if err := query.Find(&prices).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+	query, err = applyPriceFilters(c, query)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	query = query.Session(&gorm.Session{})
 
-	return c.JSON(price)
-}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung data harga"})
+	}
 
-func CreatePrice(c *fiber.Ctx) error {
-	var price models.Price
-	if err := c.BodyParser(&price); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid input", "detail": err.Error()})
+	if err := query.
+		Preload("Market").
+		Preload("Category").
+		Order(sortOrder.Clause).
+		Limit(pagination.Limit).
+		Offset(pagination.Offset()).
+		Find(&prices).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
 	}
 
-	// Start transaction
-	tx := database.DB.Begin()
+	logger.FromCtx(c).Debug("data harga diambil", "count", len(prices), "total", total)
 
-	// 🔍 Cek apakah sudah pernah ada barang dengan nama yang sama
-	var existingItem models.Price
-	if err := tx.Where("item_name = ?", price.ItemName).First(&existingItem).Error; err == nil {
-		// Barang sudah pernah diinput → pakai item_id yang sama
-		price.ItemID = existingItem.ItemID
-	} else {
-		// Barang belum pernah diinput → item_id = ID baru
-		var lastItem models.Price
-		tx.Order("item_id DESC").First(&lastItem)
-		price.ItemID = lastItem.ItemID + 1
+	applyPriceFormatting(prices, c.QueryBool("formatted"))
+	meta := writePagination(c, pagination, total)
+	meta["data"] = prices
+	return c.JSON(meta)
+}
+func GetPriceByID(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var price models.Price
+	if err := database.DB.Preload("Market").First(&price, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Price not found"})
+		}
+		logger.FromCtx(c).Error("gagal mengambil harga", "price_id", id, "error", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
 	}
 
-	// 💡 Hitung persentase perubahan harga dengan aman
-	if price.InitialPrice > 0 {
-		price.ChangePercent = ((price.CurrentPrice - price.InitialPrice) / price.InitialPrice) * 100
-	} else {
-		price.ChangePercent = 0 // Untuk harga awal 0, set persentase perubahan ke 0
-	}
+	formatted := []models.Price{price}
+	applyPriceFormatting(formatted, c.QueryBool("formatted"))
+	return c.JSON(formatted[0])
+}
 
-	if err := tx.Create(&price).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to create price"})
+func CreatePrice(c *fiber.Ctx) error {
+	var input priceInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input", "detail": err.Error()})
 	}
+	price, options := input.Price, input.priceChangeOptions
+	if ok, err := validateInput(c, &price); !ok {
+		return err
+	}
+	price.InitialPrice = roundPrice(price.InitialPrice)
+	price.CurrentPrice = roundPrice(price.CurrentPrice)
+	if err := checkPriceChange(price.InitialPrice, price.CurrentPrice, options.Force); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Petugas hanya boleh menambah harga untuk pasarnya sendiri
+	if !canWriteMarket(c, uint64(price.MarketID)) {
+		return c.Status(403).JSON(fiber.Map{"error": "Akses ditolak untuk market ini"})
+	}
+
+	created := false
+	err := database.WithTransaction(func(tx *gorm.DB) error {
+		// 🔍 Barang yang sudah ada di pasar lain memakai item_id yang sama, barang baru diberi
+		// item_id dari ID barisnya sendiri setelah disimpan
+		price.ID = 0
+		price.ItemID = sharedItemID(tx, price.ItemName)
+
+		// 💡 Hitung persentase perubahan harga dengan aman
+		price.ChangePercent = calculateChangePercent(price.InitialPrice, price.CurrentPrice)
+
+		// Kolom audit selalu dari token, nilai dari body diabaikan
+		price.CreatedBy = currentOfficerID(c)
+		price.UpdatedBy = price.CreatedBy
+
+		// Barang yang sudah ada di pasar ini diperbarui, bukan diduplikasi, sehingga tunduk pada
+		// batas edit sekali sehari yang sama dengan UpdatePrice
+		var existing models.Price
+		existingErr := tx.Where("name_key = ? AND market_id = ?", models.NameKey(price.ItemName), price.MarketID).First(&existing).Error
+		if existingErr == nil {
+			if err := checkDailyEdit(existing.UpdatedAt, time.Now()); err != nil {
+				return fiber.NewError(403, err.Error())
+			}
+		} else if !errors.Is(existingErr, gorm.ErrRecordNotFound) {
+			return txError(500, "Failed to create price", existingErr)
+		}
 
-	// Tambahkan histori
-	history := models.PriceHistory{
-		ItemID:        price.ItemID,
-		ItemName:      price.ItemName,
-		InitialPrice:  price.InitialPrice,
-		CurrentPrice:  price.CurrentPrice,
-		Reason:        price.Reason,
-		MarketID:      price.MarketID,
-		CategoryID:    price.CategoryID,
-		ChangePercent: price.ChangePercent,
-		CreatedAt:     time.Now(),
-	}
-	if err := tx.Create(&history).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to create price history"})
-	}
+		var err error
+		created, err = upsertPrice(tx, &price)
+		if err != nil {
+			return txError(500, "Failed to create price", err)
+		}
+		if !created {
+			if err := checkPriceChange(price.InitialPrice, price.CurrentPrice, options.Force); err != nil {
+				return fiber.NewError(400, err.Error())
+			}
+		}
 
-	price.CreatedAt = time.Now().UTC()
-    price.UpdatedAt = time.Now().UTC()
+		// Tambahkan histori; pada update, histori hanya ditulis bila harga atau alasan berubah
+		if created || priceHistoryNeeded(price.InitialPrice, price.CurrentPrice, existing.Reason, price.Reason) {
+			history := models.PriceHistory{
+				ItemID:        price.ItemID,
+				ItemName:      price.ItemName,
+				InitialPrice:  price.InitialPrice,
+				CurrentPrice:  price.CurrentPrice,
+				Reason:        price.Reason,
+				MarketID:      price.MarketID,
+				CategoryID:    price.CategoryID,
+				ChangePercent: price.ChangePercent,
+				OfficerID:     currentOfficerID(c),
+				CreatedAt:     time.Now(),
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return fiber.NewError(500, "Failed to create price history")
+			}
+		}
 
-	// Sync with barang table
-	if err := SyncPriceWithBarang(price.ID, tx); err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to sync with barang: %v", err)})
+		// Sync with barang table
+		if err := SyncPriceWithBarang(price.ID, tx); err != nil {
+			return fiber.NewError(500, fmt.Sprintf("Failed to sync with barang: %v", err))
+		}
+		return nil
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to commit transaction")
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit transaction"})
+	if !created {
+		logger.FromCtx(c).Info("harga yang sudah ada diperbarui", "price_id", price.ID, "item_id", price.ItemID, "market_id", price.MarketID)
+		return c.JSON(price)
 	}
-
-	fmt.Printf("✅ Harga baru ditambahkan: %+v\n", price)
+	logger.FromCtx(c).Info("harga baru ditambahkan", "price_id", price.ID, "item_id", price.ItemID, "market_id", price.MarketID)
 
 	return c.Status(201).JSON(price)
 }
@@ -158,73 +288,75 @@ func UpdatePrice(c *fiber.Ctx) error {
 	id := c.Params("id")
 	var price models.Price
 
-	now := time.Now()
-	resetTime := time.Date(now.Year(), now.Month(), now.Day(), 8, 0, 0, 0, now.Location())
-
-	var lastUpdate time.Time = price.UpdatedAt
-	if lastUpdate.After(resetTime) && now.Before(resetTime.Add(24*time.Hour)) {
-		jamTersisa := 24 - now.Sub(resetTime).Hours()
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": fmt.Sprintf("Data hanya bisa diedit sekali sehari. Coba lagi dalam %.0f jam.", jamTersisa),
-		})
-	}
-
 	if err := database.DB.First(&price, id).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Price not found"})
 	}
+	if !canWriteMarket(c, uint64(price.MarketID)) {
+		return c.Status(403).JSON(fiber.Map{"error": "Akses ditolak untuk market ini"})
+	}
 
-	var input models.Price
+	// Harga hanya bisa diedit sekali per hari, dihitung sejak jam reset (PRICE_RESET_HOUR di APP_TIMEZONE)
+	if err := checkDailyEdit(price.UpdatedAt, time.Now()); err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var input priceInput
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
+	if ok, err := validateInput(c, &input.Price); !ok {
+		return err
+	}
+	if err := checkPriceChange(price.CurrentPrice, roundPrice(input.CurrentPrice), input.Force); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
 
-	// Start transaction
-	tx := database.DB.Begin()
-
+	previousReason := price.Reason
 	price.ItemName = input.ItemName
 	price.Reason = input.Reason
 
 	price.InitialPrice = price.CurrentPrice
-	price.CurrentPrice = input.CurrentPrice
+	price.CurrentPrice = roundPrice(input.CurrentPrice)
 	price.UpdatedAt = time.Now().UTC()
+	price.UpdatedBy = currentOfficerID(c)
 
-	if price.InitialPrice > 0 {
-		price.ChangePercent = ((price.CurrentPrice - price.InitialPrice) / price.InitialPrice) * 100
-	} else {
-		price.ChangePercent = 0 // Untuk harga awal 0, set persentase perubahan ke 0
-	}
-
-	if err := tx.Save(&price).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update price"})
-	}
+	price.ChangePercent = calculateChangePercent(price.InitialPrice, price.CurrentPrice)
 
-	// Tambahkan histori
-	history := models.PriceHistory{
-		ItemID:        price.ItemID,
-		ItemName:      price.ItemName,
-		InitialPrice:  price.InitialPrice,
-		CurrentPrice:  price.CurrentPrice,
-		Reason:        price.Reason,
-		MarketID:      price.MarketID,
-		CategoryID:    price.CategoryID,
-		ChangePercent: price.ChangePercent,
-		CreatedAt:     time.Now(),
-	}
-	if err := tx.Create(&history).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to create price history"})
-	}
+	err := database.WithTransaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&price).Error; err != nil {
+			if database.IsDuplicateKeyError(err) {
+				return fiber.NewError(409, "Harga untuk barang dengan nama ini sudah ada di pasar tersebut")
+			}
+			return fiber.NewError(500, "Failed to update price")
+		}
 
-	// Sync with barang table
-	if err := SyncPriceWithBarang(price.ID, tx); err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to sync with barang: %v", err)})
-	}
+		// Tambahkan histori, kecuali harga dan alasannya tidak berubah
+		if priceHistoryNeeded(price.InitialPrice, price.CurrentPrice, previousReason, price.Reason) {
+			history := models.PriceHistory{
+				ItemID:        price.ItemID,
+				ItemName:      price.ItemName,
+				InitialPrice:  price.InitialPrice,
+				CurrentPrice:  price.CurrentPrice,
+				Reason:        price.Reason,
+				MarketID:      price.MarketID,
+				CategoryID:    price.CategoryID,
+				ChangePercent: price.ChangePercent,
+				OfficerID:     currentOfficerID(c),
+				CreatedAt:     time.Now(),
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return fiber.NewError(500, "Failed to create price history")
+			}
+		}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit transaction"})
+		// Sync with barang table
+		if err := SyncPriceWithBarang(price.ID, tx); err != nil {
+			return fiber.NewError(500, fmt.Sprintf("Failed to sync with barang: %v", err))
+		}
+		return nil
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to commit transaction")
 	}
 
 	return c.JSON(price)
@@ -233,46 +365,51 @@ func UpdatePrice(c *fiber.Ctx) error {
 func DeletePrice(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	// Start transaction
-	tx := database.DB.Begin()
-
-	// Find the price to get its name before deleting
-	var price models.Price
-	if err := tx.First(&price, id).Error; err == nil {
-		// Delete corresponding barang records if they exist only for this price
-		var count int64
-		tx.Model(&models.Price{}).Where("item_name = ? AND id != ?", price.ItemName, id).Count(&count)
-
-		if count == 0 {
-			// This is the only price record for this item, so we can delete the barang too
-			if err := tx.Where("nama = ?", price.ItemName).Delete(&models.Barang{}).Error; err != nil {
-				tx.Rollback()
-				return c.Status(500).JSON(fiber.Map{"error": "Failed to delete related barang", "detail": err.Error()})
+	// Hapus lintas tabel rawan deadlock saat ada penulisan bersamaan, jadi diulang bila perlu
+	err := database.WithRetry(txMaxAttempts, func() error {
+		return database.WithTransaction(func(tx *gorm.DB) error {
+			// Find the price to get its name before deleting
+			var price models.Price
+			if err := tx.First(&price, id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(404, "Price not found")
+				}
+				return txError(500, "Failed to fetch price", err)
 			}
-
-			// Delete barang history
-			if err := tx.Where("barang_id IN (SELECT id_barang FROM barangs WHERE nama = ?)", price.ItemName).Delete(&models.BarangHistory{}).Error; err != nil {
-				tx.Rollback()
-				return c.Status(500).JSON(fiber.Map{"error": "Failed to delete related barang history", "detail": err.Error()})
+			if !canWriteMarket(c, uint64(price.MarketID)) {
+				return fiber.NewError(403, "Akses ditolak untuk market ini")
 			}
-		}
-	}
 
-	// Delete price history
-	if err := tx.Where("item_id = ?", price.ItemID).Delete(&models.PriceHistory{}).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete price history", "detail": err.Error()})
-	}
+			// Delete corresponding barang records if they exist only for this price
+			var count int64
+			tx.Model(&models.Price{}).Where("name_key = ? AND id != ?", price.NameKey, id).Count(&count)
 
-	// Delete the price
-	if err := tx.Delete(&models.Price{}, id).Error; err != nil {
-		tx.Rollback()
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete price", "detail": err.Error()})
-	}
+			if count == 0 {
+				// This is the only price record for this item, so we can delete the barang too
+				if err := tx.Where("name_key = ?", price.NameKey).Delete(&models.Barang{}).Error; err != nil {
+					return txError(500, "Failed to delete related barang", err)
+				}
+
+				// Delete barang history
+				if err := tx.Where("barang_id IN (SELECT id_barang FROM barangs WHERE name_key = ?)", price.NameKey).Delete(&models.BarangHistory{}).Error; err != nil {
+					return txError(500, "Failed to delete related barang history", err)
+				}
+			}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to commit transaction"})
+			// Delete price history; item_id dipakai bersama lintas pasar, jadi dibatasi pasar harga ini
+			if err := tx.Where("item_id = ? AND market_id = ?", price.ItemID, price.MarketID).Delete(&models.PriceHistory{}).Error; err != nil {
+				return txError(500, "Failed to delete price history", err)
+			}
+
+			// Delete the price
+			if err := tx.Delete(&models.Price{}, id).Error; err != nil {
+				return txError(500, "Failed to delete price", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to commit transaction")
 	}
 
 	return c.JSON(fiber.Map{"message": "Price deleted successfully"})
@@ -298,35 +435,54 @@ func GetPriceHistory(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Harga tidak ditemukan untuk barang ini"})
 	}
 
+	// Lewati titik yang harganya sama dengan titik sebelumnya (dengan toleransi priceEpsilon)
 	var filteredPrices []models.Price
-	var lastPrice float64 = -1
-
 	for _, p := range prices {
-		if p.CurrentPrice != lastPrice {
+		if len(filteredPrices) == 0 || !pricesEqual(p.CurrentPrice, filteredPrices[len(filteredPrices)-1].CurrentPrice) {
 			filteredPrices = append(filteredPrices, p)
-			lastPrice = p.CurrentPrice
 		}
 	}
 
 	return c.JSON(filteredPrices)
 }
 
+// Jumlah default perubahan harga yang ditampilkan di dashboard
+const dashboardPriceChangesLimit = 50
+
+// GetDashboardData mengembalikan ringkasan dashboard. Total dihitung dengan agregat SQL,
+// sedangkan price_changes dibatasi ?limit= (default 50, maksimal maxPageSize) dan diurutkan
+// dengan ?sort= yang sama seperti GetPrices (mis. change_desc), default updated_desc.
 func GetDashboardData(c *fiber.Ctx) error {
-	var prices []models.Price
+	sortOrder, ok := priceSortOrders[c.Query("sort", "updated_desc")]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "sort harus salah satu dari price_asc, price_desc, change_asc, change_desc, name_asc, name_desc, updated_desc",
+		})
+	}
+	limit := c.QueryInt("limit", dashboardPriceChangesLimit)
+	if limit < 1 {
+		limit = dashboardPriceChangesLimit
+	}
+	limit = min(limit, maxPageSize)
 
-	if err := database.DB.Find(&prices).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+	// Total komoditas (item unik) dan total nilai harga saat ini
+	totals, err := sumDashboardTotals(database.DB.Model(&models.Price{}))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung ringkasan harga"})
 	}
 
-	// Total komoditas (item unik)
-	uniqueItems := make(map[string]bool)
-	totalStockValue := 0.0
-	var priceChanges []map[string]interface{}
+	var prices []models.Price
+	if err := database.DB.
+		Preload("Market").
+		Preload("Category").
+		Order(sortOrder.Clause).
+		Limit(limit).
+		Find(&prices).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+	}
 
+	priceChanges := make([]map[string]interface{}, 0, len(prices))
 	for _, p := range prices {
-		uniqueItems[p.ItemName] = true
-		totalStockValue += p.CurrentPrice
-
 		priceChanges = append(priceChanges, map[string]interface{}{
 			"item_name":      p.ItemName,
 			"initial_price":  p.InitialPrice,
@@ -339,14 +495,11 @@ func GetDashboardData(c *fiber.Ctx) error {
 		})
 	}
 
-	totalCommodities := len(uniqueItems)
-
 	return c.JSON(fiber.Map{
-		"total_commodities": totalCommodities,
-		"total_stock_value": totalStockValue,
+		"total_commodities": totals.TotalCommodities,
+		"total_stock_value": roundPrice(totals.TotalStockValue),
 		"price_changes":     priceChanges,
 	})
-
 }
 
 func GetPriceHistoryByItem(c *fiber.Ctx) error {
@@ -372,12 +525,17 @@ func GetPriceHistoryByCategory(c *fiber.Ctx) error {
 	var rawHistories []models.PriceHistory
 	if err := database.DB.
 		Where("category_id = ?", categoryID).
-		Order("created_at ASC").
+		Order("created_at ASC, id ASC").
 		Find(&rawHistories).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga berdasarkan kategori"})
 	}
 
-	// Filter: hanya ambil harga terakhir dari setiap item_id untuk setiap tanggal
+	return c.JSON(latestHistoryPerDay(rawHistories))
+}
+
+// latestHistoryPerDay hanya mengambil harga terakhir dari setiap item_id untuk setiap tanggal
+// (APP_TIMEZONE), diurutkan berdasarkan tanggal lalu item_id
+func latestHistoryPerDay(histories []models.PriceHistory) []models.PriceHistory {
 	type DateItemKey struct {
 		Date   string
 		ItemID uint
@@ -385,19 +543,32 @@ func GetPriceHistoryByCategory(c *fiber.Ctx) error {
 
 	latestPerDateItem := make(map[DateItemKey]models.PriceHistory)
 
-	for _, h := range rawHistories {
-		date := h.CreatedAt.Format("2006-01-02")
+	for _, h := range histories {
+		date := h.CreatedAt.In(appLocation).Format("2006-01-02")
 		key := DateItemKey{Date: date, ItemID: h.ItemID}
 
-		// Simpan hanya histori terakhir (karena urutan ASC, ini otomatis replace hingga yang terakhir)
-		latestPerDateItem[key] = h
+		// Simpan histori paling akhir per hari; tidak bergantung pada urutan hasil query
+		existing, ok := latestPerDateItem[key]
+		if !ok || h.CreatedAt.After(existing.CreatedAt) ||
+			(h.CreatedAt.Equal(existing.CreatedAt) && h.ID > existing.ID) {
+			latestPerDateItem[key] = h
+		}
 	}
 
-	// Gabungkan hasilnya menjadi slice
-	var filteredHistories []models.PriceHistory
+	// Gabungkan hasilnya menjadi slice, diurutkan berdasarkan tanggal lalu item_id
+	filteredHistories := make([]models.PriceHistory, 0, len(latestPerDateItem))
 	for _, h := range latestPerDateItem {
 		filteredHistories = append(filteredHistories, h)
 	}
 
-	return c.JSON(filteredHistories)
+	sort.Slice(filteredHistories, func(i, j int) bool {
+		a, b := filteredHistories[i], filteredHistories[j]
+		dateA, dateB := a.CreatedAt.In(appLocation).Format("2006-01-02"), b.CreatedAt.In(appLocation).Format("2006-01-02")
+		if dateA != dateB {
+			return dateA < dateB
+		}
+		return a.ItemID < b.ItemID
+	})
+
+	return filteredHistories
 }
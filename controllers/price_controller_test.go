@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestLatestHistoryPerDay(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("tzdata Asia/Jakarta tidak tersedia")
+	}
+	appLocation = jakarta
+	t.Cleanup(func() { appLocation = time.Local })
+
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	// Urutan sengaja diacak, hasil tidak boleh bergantung pada urutan query
+	histories := []models.PriceHistory{
+		{ID: 3, ItemID: 1, CurrentPrice: 12000, CreatedAt: at("2024-05-10T05:00:00Z")},
+		{ID: 1, ItemID: 1, CurrentPrice: 10000, CreatedAt: at("2024-05-10T01:00:00Z")},
+		{ID: 2, ItemID: 2, CurrentPrice: 5000, CreatedAt: at("2024-05-10T02:00:00Z")},
+		{ID: 5, ItemID: 2, CurrentPrice: 5600, CreatedAt: at("2024-05-10T02:00:00Z")},
+		{ID: 4, ItemID: 2, CurrentPrice: 5500, CreatedAt: at("2024-05-10T02:00:00Z")},
+		// 18:00 UTC tanggal 9 sudah tanggal 10 pukul 01:00 WIB, hari yang sama dengan entri di atas
+		{ID: 6, ItemID: 1, CurrentPrice: 9000, CreatedAt: at("2024-05-09T18:00:00Z")},
+		// 16:00 UTC tanggal 9 masih tanggal 9 pukul 23:00 WIB
+		{ID: 7, ItemID: 1, CurrentPrice: 8000, CreatedAt: at("2024-05-09T16:00:00Z")},
+	}
+
+	got := latestHistoryPerDay(histories)
+	wantIDs := []uint{7, 3, 5}
+	if len(got) != len(wantIDs) {
+		t.Fatalf("len = %d, want %d (%+v)", len(got), len(wantIDs), got)
+	}
+	for i, id := range wantIDs {
+		if got[i].ID != id {
+			t.Errorf("got[%d].ID = %d, want %d", i, got[i].ID, id)
+		}
+	}
+}
+
+func TestUpdatePriceReadsForceFromTheSameBody(t *testing.T) {
+	db := useTestDB(t)
+	market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	mustCreate(t, db, &market)
+	price := models.Price{ItemName: "Cabai", InitialPrice: 20000, CurrentPrice: 20000, MarketID: market.ID}
+	mustCreate(t, db, &price)
+	// Perubahan terakhir dua hari lalu sehingga batas edit harian tidak ikut menolak
+	db.Model(&price).UpdateColumn("updated_at", time.Now().AddDate(0, 0, -2))
+
+	app := fiber.New()
+	app.Put("/api/prices/:id", func(c *fiber.Ctx) error {
+		c.Locals("role", models.OfficerRoleAdmin)
+		return c.Next()
+	}, UpdatePrice)
+
+	put := func(body string) int {
+		req := httptest.NewRequest("PUT", "/api/prices/"+strconv.FormatUint(uint64(price.ID), 10), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := put(`{"item_name":"Cabai",`); status != 400 {
+		t.Errorf("body rusak: status = %d, want 400", status)
+	}
+	if status := put(`{"item_name":"Cabai","current_price":200000,"reason":"panen gagal"}`); status != 400 {
+		t.Errorf("tanpa force: status = %d, want 400", status)
+	}
+	if status := put(`{"item_name":"Cabai","current_price":200000,"reason":"panen gagal","force":true}`); status != 200 {
+		t.Errorf("dengan force: status = %d, want 200", status)
+	}
+}
+
+func TestGetPricesSort(t *testing.T) {
+	db := useTestDB(t)
+	market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	mustCreate(t, db, &market)
+	now := time.Now()
+	for _, p := range []models.Price{
+		{ItemName: "Cabai", CurrentPrice: 30000, ChangePercent: 5, UpdatedAt: now.Add(-time.Hour)},
+		{ItemName: "Bawang", CurrentPrice: 25000, ChangePercent: -2, UpdatedAt: now},
+		{ItemName: "Tomat", CurrentPrice: 8000, ChangePercent: 10, UpdatedAt: now.Add(-2 * time.Hour)},
+	} {
+		p.MarketID = market.ID
+		mustCreate(t, db, &p)
+	}
+
+	app := fiber.New()
+	app.Get("/api/prices", GetPrices)
+
+	get := func(query string) (int, []string) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/prices?"+query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body struct {
+			Data []models.Price `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		names := make([]string, len(body.Data))
+		for i, p := range body.Data {
+			names[i] = p.ItemName
+		}
+		return resp.StatusCode, names
+	}
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"Bawang", "Cabai", "Tomat"}},
+		{"price_asc", []string{"Tomat", "Bawang", "Cabai"}},
+		{"price_desc", []string{"Cabai", "Bawang", "Tomat"}},
+		{"change_asc", []string{"Bawang", "Cabai", "Tomat"}},
+		{"change_desc", []string{"Tomat", "Cabai", "Bawang"}},
+		{"name_asc", []string{"Bawang", "Cabai", "Tomat"}},
+		{"name_desc", []string{"Tomat", "Cabai", "Bawang"}},
+		{"updated_desc", []string{"Bawang", "Cabai", "Tomat"}},
+	}
+	for _, tt := range tests {
+		t.Run("sort="+tt.sort, func(t *testing.T) {
+			// sort kosong memakai default updated_desc
+			status, names := get("sort=" + tt.sort)
+			if status != 200 {
+				t.Fatalf("status = %d, want 200", status)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("urutan = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	for _, value := range []string{"id", "current_price", "price_asc;DROP TABLE prices", "(SELECT 1)"} {
+		t.Run("ditolak "+value, func(t *testing.T) {
+			if status, _ := get("sort=" + url.QueryEscape(value)); status != 400 {
+				t.Errorf("status = %d, want 400", status)
+			}
+		})
+	}
+	if !db.Migrator().HasTable(&models.Price{}) {
+		t.Error("tabel prices hilang")
+	}
+}
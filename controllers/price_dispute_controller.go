@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreatePriceDispute mencatat keberatan atas harga tertentu. Route ini dilindungi JWTMiddleware;
+// pelapor diambil dari username di token, bukan dari body, agar laporan tidak bisa dipalsukan.
+func CreatePriceDispute(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var price models.Price
+	if err := database.DB.First(&price, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Price not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data harga"})
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if input.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Alasan sengketa wajib diisi"})
+	}
+	reportedBy, _ := c.Locals("username").(string)
+
+	dispute := models.PriceDispute{
+		PriceID:    price.ID,
+		ReportedBy: reportedBy,
+		Reason:     input.Reason,
+		Status:     models.DisputeStatusOpen,
+	}
+	if err := database.DB.Create(&dispute).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan sengketa harga"})
+	}
+
+	return c.Status(201).JSON(dispute)
+}
+
+// GetPriceDisputes menampilkan daftar sengketa harga, bisa difilter dengan ?status=open|resolved
+func GetPriceDisputes(c *fiber.Ctx) error {
+	query := database.DB.Preload("Price").Order("created_at DESC")
+
+	switch status := c.Query("status"); status {
+	case "":
+	case models.DisputeStatusOpen, models.DisputeStatusResolved:
+		query = query.Where("status = ?", status)
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "Status harus open atau resolved"})
+	}
+
+	var disputes []models.PriceDispute
+	if err := query.Find(&disputes).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data sengketa harga"})
+	}
+
+	return c.JSON(disputes)
+}
+
+// ResolvePriceDispute menutup sengketa, opsional sekaligus mengoreksi harga yang disengketakan
+func ResolvePriceDispute(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var input struct {
+		Resolution     string   `json:"resolution"`
+		CorrectedPrice *float64 `json:"corrected_price"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+	}
+	if input.CorrectedPrice != nil && *input.CorrectedPrice < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Harga koreksi tidak boleh negatif"})
+	}
+
+	resolvedBy, _ := c.Locals("username").(string)
+
+	var dispute models.PriceDispute
+	err := database.WithRetry(txMaxAttempts, func() error {
+		return database.WithTransaction(func(tx *gorm.DB) error {
+			// Kunci baris sengketa agar dua admin yang menyelesaikan bersamaan tidak sama-sama
+			// lolos pemeriksaan status dan mengoreksi harga dua kali
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dispute, id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(404, "Sengketa tidak ditemukan")
+				}
+				return txError(500, "Gagal mengambil data sengketa", err)
+			}
+			if dispute.Status == models.DisputeStatusResolved {
+				return fiber.NewError(fiber.StatusConflict, "Sengketa sudah diselesaikan")
+			}
+
+			if input.CorrectedPrice != nil {
+				var price models.Price
+				if err := tx.First(&price, dispute.PriceID).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return fiber.NewError(404, "Price not found")
+					}
+					return txError(500, "Gagal mengambil data harga", err)
+				}
+
+				// Koreksi mengganti nilai yang salah, harga awal tetap dipertahankan
+				price.CurrentPrice = roundPrice(*input.CorrectedPrice)
+				price.ChangePercent = calculateChangePercent(price.InitialPrice, price.CurrentPrice)
+				price.Reason = fmt.Sprintf("Koreksi sengketa #%d", dispute.ID)
+				price.UpdatedAt = time.Now().UTC()
+				price.UpdatedBy = nil // Dikoreksi admin, bukan petugas
+
+				if err := tx.Save(&price).Error; err != nil {
+					return txError(500, "Failed to update price", err)
+				}
+
+				history := models.PriceHistory{
+					ItemID:        price.ItemID,
+					ItemName:      price.ItemName,
+					InitialPrice:  price.InitialPrice,
+					CurrentPrice:  price.CurrentPrice,
+					Reason:        price.Reason,
+					MarketID:      price.MarketID,
+					CategoryID:    price.CategoryID,
+					ChangePercent: price.ChangePercent,
+					CreatedAt:     time.Now(),
+				}
+				if err := tx.Create(&history).Error; err != nil {
+					return txError(500, "Failed to create price history", err)
+				}
+
+				if err := SyncPriceWithBarang(price.ID, tx); err != nil {
+					return fiber.NewError(500, fmt.Sprintf("Failed to sync with barang: %v", err))
+				}
+			}
+
+			now := time.Now().UTC()
+			dispute.Status = models.DisputeStatusResolved
+			dispute.Resolution = input.Resolution
+			dispute.ResolvedBy = resolvedBy
+			dispute.ResolvedAt = &now
+
+			if err := tx.Save(&dispute).Error; err != nil {
+				return txError(500, "Gagal menyelesaikan sengketa", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return txErrorResponse(c, err, "Failed to commit transaction")
+	}
+
+	return c.JSON(dispute)
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"backend/models"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// seedDisputedPrice membuat satu harga di pasar baru beserta sengketa terbuka atasnya
+func seedDisputedPrice(t *testing.T, db *gorm.DB) (models.Price, models.PriceDispute) {
+	t.Helper()
+	market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	mustCreate(t, db, &market)
+	price := models.Price{ItemName: "Cabai", InitialPrice: 20000, CurrentPrice: 200000, MarketID: market.ID}
+	mustCreate(t, db, &price)
+	dispute := models.PriceDispute{PriceID: price.ID, ReportedBy: "siti", Reason: "Salah ketik", Status: models.DisputeStatusOpen}
+	mustCreate(t, db, &dispute)
+	return price, dispute
+}
+
+// Pelapor selalu diambil dari token, reported_by di body diabaikan
+func TestCreatePriceDisputeUsesTokenUsername(t *testing.T) {
+	db := useTestDB(t)
+	price, _ := seedDisputedPrice(t, db)
+
+	app := fiber.New()
+	app.Post("/api/prices/:id/dispute", func(c *fiber.Ctx) error {
+		c.Locals("username", "budi")
+		return c.Next()
+	}, CreatePriceDispute)
+
+	post := func(body string) int {
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/prices/%d/dispute", price.ID), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	if got := post(`{"reported_by":"palsu"}`); got != 400 {
+		t.Errorf("tanpa alasan: status = %d, want 400", got)
+	}
+	if got := post(`{"reported_by":"palsu","reason":"Terlalu mahal"}`); got != 201 {
+		t.Fatalf("status = %d, want 201", got)
+	}
+
+	var stored models.PriceDispute
+	if err := db.Where("reason = ?", "Terlalu mahal").First(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.ReportedBy != "budi" {
+		t.Errorf("reported_by = %q, want budi dari token", stored.ReportedBy)
+	}
+}
+
+func TestResolvePriceDispute(t *testing.T) {
+	db := useTestDB(t)
+	price, dispute := seedDisputedPrice(t, db)
+
+	app := fiber.New()
+	app.Put("/api/prices/disputes/:id/resolve", func(c *fiber.Ctx) error {
+		c.Locals("username", "admin")
+		return c.Next()
+	}, ResolvePriceDispute)
+
+	resolve := func(id uint, body string) int {
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/prices/disputes/%d/resolve", id), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+	histories := func() int64 {
+		var count int64
+		db.Model(&models.PriceHistory{}).Where("item_id = ?", price.ItemID).Count(&count)
+		return count
+	}
+
+	if status := resolve(999, `{"resolution":"x"}`); status != 404 {
+		t.Errorf("sengketa tidak ada: status = %d, want 404", status)
+	}
+	if status := resolve(dispute.ID, `{"corrected_price":-1}`); status != 400 {
+		t.Errorf("harga negatif: status = %d, want 400", status)
+	}
+
+	if status := resolve(dispute.ID, `{"resolution":"Salah ketik","corrected_price":20000}`); status != 200 {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	var stored models.PriceDispute
+	db.First(&stored, dispute.ID)
+	if stored.Status != models.DisputeStatusResolved || stored.ResolvedBy != "admin" || stored.ResolvedAt == nil {
+		t.Errorf("sengketa = %+v, want resolved oleh admin", stored)
+	}
+	var corrected models.Price
+	db.First(&corrected, price.ID)
+	if corrected.CurrentPrice != 20000 || corrected.InitialPrice != 20000 {
+		t.Errorf("harga = %v -> %v, want 20000 -> 20000", corrected.InitialPrice, corrected.CurrentPrice)
+	}
+	if got := histories(); got != 1 {
+		t.Fatalf("histori = %d, want 1", got)
+	}
+
+	// Sengketa yang sudah selesai ditolak tanpa mengoreksi harga lagi
+	if status := resolve(dispute.ID, `{"resolution":"Ulang","corrected_price":1}`); status != fiber.StatusConflict {
+		t.Errorf("selesaikan ulang: status = %d, want 409", status)
+	}
+	db.First(&corrected, price.ID)
+	if corrected.CurrentPrice != 20000 || histories() != 1 {
+		t.Errorf("harga = %v, histori = %d setelah 409, want tidak berubah", corrected.CurrentPrice, histories())
+	}
+}
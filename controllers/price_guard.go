@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"backend/models"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxPriceChangePercent adalah batas perubahan harga sekali input, bisa diubah lewat env MAX_PRICE_CHANGE_PERCENT
+const DefaultMaxPriceChangePercent = 500.0
+
+var maxPriceChangePercent = DefaultMaxPriceChangePercent
+
+func init() {
+	if value, err := strconv.ParseFloat(os.Getenv("MAX_PRICE_CHANGE_PERCENT"), 64); err == nil && value > 0 {
+		maxPriceChangePercent = value
+	}
+}
+
+// priceChangeOptions dibaca dari body yang sama dengan data harga
+type priceChangeOptions struct {
+	Force bool `json:"force"`
+}
+
+// priceInput adalah body CreatePrice dan UpdatePrice, data harga beserta opsinya dibaca sekali
+type priceInput struct {
+	models.Price
+	priceChangeOptions
+}
+
+// checkPriceChange menolak perubahan harga yang melebihi maxPriceChangePercent (kemungkinan salah ketik),
+// kecuali force bernilai true
+func checkPriceChange(initial, current float64, force bool) error {
+	change := calculateChangePercent(initial, current)
+	if force || math.Abs(change) <= maxPriceChangePercent {
+		return nil
+	}
+	return fmt.Errorf("perubahan harga dari %s ke %s (%.2f%%) melebihi batas %.0f%%, kirim \"force\": true bila harga ini memang benar",
+		strconv.FormatFloat(initial, 'f', -1, 64), strconv.FormatFloat(current, 'f', -1, 64), change, maxPriceChangePercent)
+}
+
+// checkDailyEdit menolak perubahan harga yang sudah diubah sejak reset harian terakhir
+// (PRICE_RESET_HOUR di APP_TIMEZONE), karena harga hanya bisa diedit sekali per hari
+func checkDailyEdit(lastUpdate, now time.Time) error {
+	resetTime := lastDailyReset(now)
+	if !lastUpdate.After(resetTime) {
+		return nil
+	}
+	jamTersisa := resetTime.AddDate(0, 0, 1).Sub(now).Hours()
+	return fmt.Errorf("Data hanya bisa diedit sekali sehari. Coba lagi dalam %.0f jam.", math.Ceil(jamTersisa))
+}
+
+// priceRuleError adalah penolakan oleh aturan perubahan harga (bukan kegagalan database), sehingga
+// bulk dan import cukup melaporkannya per item lalu melanjutkan item berikutnya
+type priceRuleError struct{ error }
+
+// checkPriceUpdate menerapkan aturan yang sama dengan UpdatePrice pada harga yang sudah ada:
+// sekali edit per hari sejak reset harian, lalu batas perubahan harga kecuali force
+func checkPriceUpdate(price models.Price, newPrice float64, force bool, now time.Time) error {
+	if err := checkDailyEdit(price.UpdatedAt, now); err != nil {
+		return priceRuleError{err}
+	}
+	if err := checkPriceChange(price.CurrentPrice, newPrice, force); err != nil {
+		return priceRuleError{err}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"backend/models"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckPriceUpdate(t *testing.T) {
+	appLocation, priceResetHour = time.UTC, 8
+	t.Cleanup(func() { appLocation, priceResetHour = time.Local, DefaultPriceResetHour })
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		updatedAt time.Time
+		newPrice  float64
+		force     bool
+		wantErr   string
+	}{
+		{"diubah sebelum reset", time.Date(2024, 5, 10, 7, 59, 0, 0, time.UTC), 11000, false, ""},
+		{"sudah diubah sejak reset", time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC), 11000, false, "sekali sehari"},
+		{"perubahan terlalu besar", time.Date(2024, 5, 9, 12, 0, 0, 0, time.UTC), 100000, false, "melebihi batas"},
+		{"perubahan besar dengan force", time.Date(2024, 5, 9, 12, 0, 0, 0, time.UTC), 100000, true, ""},
+		{"force tidak melewati batas harian", time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC), 11000, true, "sekali sehari"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := models.Price{CurrentPrice: 10000, UpdatedAt: tt.updatedAt}
+			err := checkPriceUpdate(price, tt.newPrice, tt.force, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want mengandung %q", err, tt.wantErr)
+			}
+			var ruleErr priceRuleError
+			if !errors.As(err, &ruleErr) {
+				t.Errorf("err harus berupa priceRuleError agar bulk dan import melanjutkan item lain")
+			}
+		})
+	}
+}
+
+func TestParseImportCSVForceColumn(t *testing.T) {
+	csv := "item_name,current_price,reason,market_id,category_id,force\n" +
+		"Beras,12000,,1,1,true\n" +
+		"Gula,15000,,1,1,\n" +
+		"Cabai,50000,,1,1,mungkin\n"
+	rows, err := parseImportCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("len(rows) = %d, want 3", len(rows))
+	}
+	if !rows[0].Force || rows[1].Force {
+		t.Errorf("force = %v, %v, want true, false", rows[0].Force, rows[1].Force)
+	}
+	if len(rows[2].Errors) != 1 {
+		t.Errorf("errors baris 3 = %v, want satu error force", rows[2].Errors)
+	}
+}
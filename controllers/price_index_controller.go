@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BasketItem adalah komoditas dalam keranjang indeks beserta kuantitas tetapnya (bobot Laspeyres)
+type BasketItem struct {
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+}
+
+// Keranjang bahan pokok default untuk indeks harga
+var defaultPriceBasket = []BasketItem{
+	{Name: "Beras", Quantity: 10},
+	{Name: "Gula Pasir", Quantity: 2},
+	{Name: "Minyak Goreng", Quantity: 2},
+	{Name: "Telur Ayam", Quantity: 2},
+	{Name: "Daging Ayam", Quantity: 1},
+	{Name: "Cabai Merah", Quantity: 0.5},
+	{Name: "Bawang Merah", Quantity: 0.5},
+	{Name: "Bawang Putih", Quantity: 0.5},
+}
+
+// parseBasket membaca ?basket=Beras:10,Gula Pasir:2; kuantitas default 1
+func parseBasket(value string) ([]BasketItem, bool) {
+	var basket []BasketItem
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		item := BasketItem{Name: part, Quantity: 1}
+		if idx := strings.LastIndex(part, ":"); idx >= 0 {
+			quantity, err := strconv.ParseFloat(strings.TrimSpace(part[idx+1:]), 64)
+			if err != nil || quantity <= 0 {
+				return nil, false
+			}
+			item.Name = strings.TrimSpace(part[:idx])
+			item.Quantity = quantity
+		}
+		if item.Name == "" {
+			return nil, false
+		}
+		basket = append(basket, item)
+	}
+	return basket, len(basket) > 0
+}
+
+// GetPriceIndex menghitung indeks harga gaya Laspeyres (base = 100) dari PriceHistory:
+// sum(harga_sekarang * q) / sum(harga_dasar * q) * 100
+func GetPriceIndex(c *fiber.Ctx) error {
+	if c.Query("base_date") == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "base_date wajib diisi"})
+	}
+	baseDate, err := parseDate(c.Query("base_date"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	baseCutoff := baseDate.AddDate(0, 0, 1)
+
+	currentCutoff := time.Now()
+	if value := c.Query("date"); value != "" {
+		date, err := parseDate(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		currentCutoff = date.AddDate(0, 0, 1)
+	}
+	if baseDate.After(currentCutoff) {
+		return c.Status(400).JSON(fiber.Map{"error": "base_date tidak boleh setelah tanggal pembanding"})
+	}
+
+	basket := defaultPriceBasket
+	if value := c.Query("basket"); value != "" {
+		custom, ok := parseBasket(value)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Format basket tidak valid, gunakan nama:kuantitas"})
+		}
+		basket = custom
+	}
+
+	names := make([]string, 0, len(basket))
+	for _, item := range basket {
+		names = append(names, strings.ToLower(item.Name))
+	}
+
+	query := database.DB.
+		Where("LOWER(item_name) IN ?", names).
+		Where("created_at < ?", currentCutoff).
+		Order("created_at ASC, id ASC")
+	if marketID := c.Query("market_id"); marketID != "" {
+		query = query.Where("market_id = ?", marketID)
+	}
+
+	var histories []models.PriceHistory
+	if err := query.Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+	}
+
+	// Harga terakhir per komoditas per pasar, pada tanggal dasar dan saat ini
+	type itemMarket struct {
+		Name     string
+		MarketID uint
+	}
+	basePrices := make(map[itemMarket]float64)
+	currentPrices := make(map[itemMarket]float64)
+	for _, h := range histories {
+		key := itemMarket{Name: strings.ToLower(h.ItemName), MarketID: h.MarketID}
+		if h.CreatedAt.Before(baseCutoff) {
+			basePrices[key] = h.CurrentPrice
+		}
+		currentPrices[key] = h.CurrentPrice
+	}
+
+	// Rata-rata antar pasar, hanya untuk pasar yang punya harga dasar dan harga sekarang
+	average := func(name string) (float64, float64, bool) {
+		var baseSum, currentSum float64
+		var count int
+		for key, base := range basePrices {
+			if key.Name != name {
+				continue
+			}
+			baseSum += base
+			currentSum += currentPrices[key]
+			count++
+		}
+		if count == 0 {
+			return 0, 0, false
+		}
+		return baseSum / float64(count), currentSum / float64(count), true
+	}
+
+	var baseCost, currentCost float64
+	components := make([]fiber.Map, 0, len(basket))
+	missing := []string{}
+	for _, item := range basket {
+		basePrice, currentPrice, ok := average(strings.ToLower(item.Name))
+		if !ok || basePrice <= 0 {
+			missing = append(missing, item.Name)
+			continue
+		}
+
+		baseCost += basePrice * item.Quantity
+		currentCost += currentPrice * item.Quantity
+		components = append(components, fiber.Map{
+			"name":          item.Name,
+			"quantity":      item.Quantity,
+			"base_price":    basePrice,
+			"current_price": currentPrice,
+		})
+	}
+
+	if baseCost == 0 {
+		return c.Status(404).JSON(fiber.Map{
+			"error":   "Tidak ada data harga dasar untuk komoditas dalam keranjang",
+			"missing": missing,
+		})
+	}
+
+	index := currentCost / baseCost * 100
+
+	return c.JSON(fiber.Map{
+		"base_date":      baseDate.Format("2006-01-02"),
+		"index":          index,
+		"change_percent": index - 100,
+		"base_cost":      baseCost,
+		"current_cost":   currentCost,
+		"components":     components,
+		"missing":        missing,
+	})
+}
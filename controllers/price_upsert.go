@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sharedItemID mengembalikan item_id komoditas yang sama di pasar lain, atau 0 bila belum pernah ada
+func sharedItemID(tx *gorm.DB, name string) uint {
+	var existing models.Price
+	if err := tx.Where("name_key = ? AND item_id <> 0", models.NameKey(name)).First(&existing).Error; err != nil {
+		return 0
+	}
+	return existing.ItemID
+}
+
+// ensureItemID memberi item_id pada komoditas baru memakai ID baris harganya sendiri, sehingga
+// unik tanpa perlu mencari item_id terbesar yang rawan bentrok saat request bersamaan
+func ensureItemID(tx *gorm.DB, price *models.Price) error {
+	if price.ItemID != 0 {
+		return nil
+	}
+	if err := tx.Model(price).UpdateColumn("item_id", price.ID).Error; err != nil {
+		return err
+	}
+	price.ItemID = price.ID
+	return nil
+}
+
+// upsertPrice menyimpan harga baru, atau memperbarui baris yang sudah ada untuk (name_key, market_id)
+// lewat unique index PriceNameMarketIndex. Pada update, harga sekarang yang lama menjadi harga awal,
+// baris yang terhapus (soft delete) dihidupkan lagi, sedangkan item_id, created_at dan created_by
+// tidak diubah. Baris hasil akhir dibaca ulang ke price; created bernilai true bila baris baru.
+func upsertPrice(tx *gorm.DB, price *models.Price) (created bool, err error) {
+	// Urutan penting: MySQL menjalankan assignment dari kiri, jadi initial_price harus membaca
+	// current_price lama sebelum current_price ditimpa
+	assignments := clause.Set{
+		{Column: clause.Column{Name: "initial_price"}, Value: gorm.Expr("current_price")},
+		{Column: clause.Column{Name: "current_price"}, Value: price.CurrentPrice},
+		{Column: clause.Column{Name: "item_name"}, Value: price.ItemName},
+		{Column: clause.Column{Name: "reason"}, Value: price.Reason},
+		{Column: clause.Column{Name: "category_id"}, Value: price.CategoryID},
+		{Column: clause.Column{Name: "updated_by"}, Value: price.UpdatedBy},
+		{Column: clause.Column{Name: "updated_at"}, Value: time.Now().UTC()},
+		{Column: clause.Column{Name: "deleted_at"}, Value: nil},
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoUpdates: assignments}).Create(price)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	// MySQL melaporkan 1 baris untuk insert dan 2 untuk update
+	created = result.RowsAffected == 1
+
+	var saved models.Price
+	query := tx.Where("name_key = ? AND market_id = ?", price.NameKey, price.MarketID)
+	if created {
+		query = tx.Where("id = ?", price.ID)
+	}
+	if err := query.First(&saved).Error; err != nil {
+		return false, err
+	}
+	if !created {
+		saved.ChangePercent = calculateChangePercent(saved.InitialPrice, saved.CurrentPrice)
+		if err := tx.Model(&saved).UpdateColumn("change_percent", saved.ChangePercent).Error; err != nil {
+			return false, err
+		}
+	}
+	*price = saved
+	return created, ensureItemID(tx, price)
+}
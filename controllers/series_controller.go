@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Batas jumlah titik dalam satu deret agar response grafik tetap ringan
+const seriesMaxPoints = 366
+
+// Rentang default bila ?from tidak diisi
+const seriesDefaultDays = 30
+
+type SeriesPoint struct {
+	Date  string   `json:"date"`
+	Price *float64 `json:"price"`
+}
+
+// seriesInterval menentukan awal bucket untuk suatu waktu dan awal bucket berikutnya
+type seriesInterval struct {
+	start func(t time.Time) time.Time
+	next  func(t time.Time) time.Time
+}
+
+var seriesIntervals = map[string]seriesInterval{
+	"daily": {
+		start: startOfDay,
+		next:  func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+	},
+	"weekly": {
+		// Minggu dimulai hari Senin
+		start: func(t time.Time) time.Time {
+			day := startOfDay(t)
+			return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		},
+		next: func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+	},
+	"monthly": {
+		start: func(t time.Time) time.Time {
+			day := startOfDay(t)
+			return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, appLocation)
+		},
+		next: func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+	},
+}
+
+// GetBarangPriceSeries menampilkan harga barang sebagai deret waktu dengan jarak seragam untuk grafik
+// mobile: ?from=&to= (default 30 hari terakhir) dan ?interval=daily|weekly|monthly (default daily).
+// Histori diambil dari PriceHistory dengan nama barang dan pasar yang sama.
+func GetBarangPriceSeries(c *fiber.Ctx) error {
+	interval, ok := seriesIntervals[c.Query("interval", "daily")]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "interval harus salah satu dari daily, weekly, monthly"})
+	}
+
+	to := startOfDay(time.Now())
+	if value := c.Query("to"); value != "" {
+		parsed, err := parseDate(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "to: " + err.Error()})
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(seriesDefaultDays - 1))
+	if value := c.Query("from"); value != "" {
+		parsed, err := parseDate(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "from: " + err.Error()})
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		return c.Status(400).JSON(fiber.Map{"error": "from tidak boleh setelah to"})
+	}
+	if countSeriesBuckets(from, to, interval) > seriesMaxPoints {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Rentang terlalu panjang, maksimal %d titik", seriesMaxPoints)})
+	}
+
+	var barang models.Barang
+	if err := database.DB.First(&barang, "id_barang = ?", c.Params("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Barang not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data barang"})
+	}
+
+	// Satu query terurut sampai akhir rentang; histori sebelum from menjadi harga awal yang dibawa ke depan
+	query := database.DB.Where("item_name = ? AND created_at < ?", barang.Nama, to.AddDate(0, 0, 1))
+	if barang.MarketID != 0 {
+		query = query.Where("market_id = ?", barang.MarketID)
+	}
+	var histories []models.PriceHistory
+	if err := query.Order("created_at ASC, id ASC").Find(&histories).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal ambil histori harga"})
+	}
+
+	return c.JSON(bucketPriceSeries(histories, from, to, interval))
+}
+
+func countSeriesBuckets(from, to time.Time, interval seriesInterval) int {
+	count := 0
+	for bucket := interval.start(from); !bucket.After(to); bucket = interval.next(bucket) {
+		count++
+		if count > seriesMaxPoints {
+			break
+		}
+	}
+	return count
+}
+
+// bucketPriceSeries membagi histori (terurut naik) ke dalam bucket dari from sampai to. Perubahan
+// terakhir di dalam satu bucket yang dipakai, bucket kosong memakai harga terakhir sebelumnya,
+// dan bucket sebelum ada data sama sekali bernilai null.
+func bucketPriceSeries(histories []models.PriceHistory, from, to time.Time, interval seriesInterval) []SeriesPoint {
+	points := []SeriesPoint{}
+	var last *float64
+	i := 0
+	for bucket := interval.start(from); !bucket.After(to); bucket = interval.next(bucket) {
+		end := interval.next(bucket)
+		for i < len(histories) && histories[i].CreatedAt.Before(end) {
+			price := histories[i].CurrentPrice
+			last = &price
+			i++
+		}
+
+		point := SeriesPoint{Date: bucket.Format("2006-01-02")}
+		if last != nil {
+			price := *last
+			point.Price = &price
+		}
+		points = append(points, point)
+	}
+	return points
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestBucketPriceSeries(t *testing.T) {
+	appLocation = time.UTC
+	t.Cleanup(func() { appLocation = time.Local })
+
+	at := func(day, hour int) time.Time { return time.Date(2024, 5, day, hour, 0, 0, 0, time.UTC) }
+	history := func(day, hour int, price float64) models.PriceHistory {
+		return models.PriceHistory{CurrentPrice: price, CreatedAt: at(day, hour)}
+	}
+	price := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name      string
+		histories []models.PriceHistory
+		from, to  time.Time
+		interval  string
+		want      []SeriesPoint
+	}{
+		{
+			name:      "bucket sebelum ada data bernilai null",
+			histories: []models.PriceHistory{history(3, 9, 10000)},
+			from:      at(1, 0), to: at(3, 0), interval: "daily",
+			want: []SeriesPoint{{"2024-05-01", nil}, {"2024-05-02", nil}, {"2024-05-03", price(10000)}},
+		},
+		{
+			name:      "bucket kosong membawa harga terakhir",
+			histories: []models.PriceHistory{history(1, 9, 10000), history(3, 9, 12000)},
+			from:      at(1, 0), to: at(4, 0), interval: "daily",
+			want: []SeriesPoint{{"2024-05-01", price(10000)}, {"2024-05-02", price(10000)}, {"2024-05-03", price(12000)}, {"2024-05-04", price(12000)}},
+		},
+		{
+			name:      "perubahan terakhir dalam satu hari yang dipakai",
+			histories: []models.PriceHistory{history(1, 8, 10000), history(1, 12, 11000), history(1, 23, 10500)},
+			from:      at(1, 0), to: at(1, 0), interval: "daily",
+			want: []SeriesPoint{{"2024-05-01", price(10500)}},
+		},
+		{
+			name:      "histori sebelum from menjadi harga awal",
+			histories: []models.PriceHistory{history(1, 9, 9000), history(4, 9, 9500)},
+			from:      at(3, 0), to: at(4, 0), interval: "daily",
+			want: []SeriesPoint{{"2024-05-03", price(9000)}, {"2024-05-04", price(9500)}},
+		},
+		{
+			name:      "mingguan dimulai hari Senin",
+			histories: []models.PriceHistory{history(7, 9, 10000), history(9, 9, 11000), history(15, 9, 12000)},
+			from:      at(8, 0), to: at(20, 0), interval: "weekly",
+			want: []SeriesPoint{{"2024-05-06", price(11000)}, {"2024-05-13", price(12000)}, {"2024-05-20", price(12000)}},
+		},
+		{
+			name:      "bulanan",
+			histories: []models.PriceHistory{history(10, 9, 10000), {CurrentPrice: 13000, CreatedAt: time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)}},
+			from:      at(15, 0), to: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), interval: "monthly",
+			want: []SeriesPoint{{"2024-05-01", price(10000)}, {"2024-06-01", price(10000)}, {"2024-07-01", price(13000)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bucketPriceSeries(tt.histories, tt.from, tt.to, seriesIntervals[tt.interval])
+			if len(got) != len(tt.want) {
+				t.Fatalf("len = %d, want %d (%s)", len(got), len(tt.want), formatSeries(got))
+			}
+			if formatSeries(got) != formatSeries(tt.want) {
+				t.Errorf("got %s, want %s", formatSeries(got), formatSeries(tt.want))
+			}
+		})
+	}
+}
+
+func formatSeries(points []SeriesPoint) string {
+	encoded, _ := json.Marshal(points)
+	return string(encoded)
+}
+
+func TestGetBarangPriceSeries(t *testing.T) {
+	appLocation = time.UTC
+	t.Cleanup(func() { appLocation = time.Local })
+
+	db := useTestDB(t)
+	market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	other := models.Market{Name: "Pasar Lama", Location: "Kota"}
+	mustCreate(t, db, &market, &other)
+	barang := models.Barang{Nama: "Cabai", MarketID: market.ID}
+	mustCreate(t, db, &barang)
+	mustCreate(t, db,
+		&models.PriceHistory{ItemName: "Cabai", MarketID: market.ID, CurrentPrice: 30000, CreatedAt: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)},
+		&models.PriceHistory{ItemName: "Cabai", MarketID: market.ID, CurrentPrice: 32000, CreatedAt: time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)},
+		// Pasar lain dan barang lain tidak ikut dihitung
+		&models.PriceHistory{ItemName: "Cabai", MarketID: other.ID, CurrentPrice: 99000, CreatedAt: time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)},
+		&models.PriceHistory{ItemName: "Bawang", MarketID: market.ID, CurrentPrice: 1000, CreatedAt: time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)},
+	)
+
+	app := fiber.New()
+	app.Get("/api/barang/:id/series", GetBarangPriceSeries)
+	get := func(id uint64, query string) (int, []SeriesPoint) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/barang/"+strconv.FormatUint(id, 10)+"/series?"+query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var points []SeriesPoint
+		if resp.StatusCode == 200 {
+			json.NewDecoder(resp.Body).Decode(&points)
+		}
+		return resp.StatusCode, points
+	}
+
+	status, points := get(barang.IdBarang, "from=2024-05-01&to=2024-05-03")
+	if status != 200 {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	want := `[{"date":"2024-05-01","price":30000},{"date":"2024-05-02","price":30000},{"date":"2024-05-03","price":32000}]`
+	if got := formatSeries(points); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		name  string
+		id    uint64
+		query string
+		want  int
+	}{
+		{"interval tidak dikenal", barang.IdBarang, "interval=hourly", 400},
+		{"from setelah to", barang.IdBarang, "from=2024-05-03&to=2024-05-01", 400},
+		{"rentang terlalu panjang", barang.IdBarang, "from=2020-01-01&to=2024-05-01", 400},
+		{"tanggal tidak valid", barang.IdBarang, "from=kemarin", 400},
+		{"barang tidak ada", barang.IdBarang + 100, "", 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := get(tt.id, tt.query); status != tt.want {
+				t.Errorf("status = %d, want %d", status, tt.want)
+			}
+		})
+	}
+}
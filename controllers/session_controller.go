@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type SessionResponse struct {
+	JTI       string    `json:"jti"`
+	Device    string    `json:"device"`
+	IPAddress string    `json:"ip_address"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	Current   bool      `json:"current"`
+}
+
+// sessionDevice mengambil nama perangkat dari header X-Device-Name, atau User-Agent bila kosong
+func sessionDevice(c *fiber.Ctx) string {
+	device := strings.TrimSpace(c.Get("X-Device-Name"))
+	if device == "" {
+		device = c.Get(fiber.HeaderUserAgent)
+	}
+	if len(device) > 255 {
+		device = device[:255]
+	}
+	return device
+}
+
+func activeSessions(officerID uint64, currentJTI string) ([]SessionResponse, error) {
+	var sessions []models.OfficerSession
+	if err := database.DB.
+		Where("officer_id = ? AND revoked_at IS NULL AND expires_at > ?", officerID, time.Now()).
+		Order("last_seen DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		response = append(response, SessionResponse{
+			JTI:       s.JTI,
+			Device:    s.Device,
+			IPAddress: s.IPAddress,
+			IssuedAt:  s.IssuedAt,
+			ExpiresAt: s.ExpiresAt,
+			LastSeen:  s.LastSeen,
+			Current:   s.JTI == currentJTI,
+		})
+	}
+	return response, nil
+}
+
+// revokeSession mencabut satu sesi milik officer, token tersebut langsung ditolak oleh JWTMiddleware
+func revokeSession(c *fiber.Ctx, officerID uint64, jti string) error {
+	var session models.OfficerSession
+	if err := database.DB.
+		Where("officer_id = ? AND jti = ? AND revoked_at IS NULL", officerID, jti).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Sesi tidak ditemukan"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data sesi"})
+	}
+
+	if err := revokeOfficerSession(session); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut sesi"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Sesi berhasil dicabut", "jti": jti})
+}
+
+// revokeOfficerSession menandai sesi dicabut, mencabut refresh token yang terbit bersamanya,
+// dan memasukkan jti-nya ke daftar hitam token
+func revokeOfficerSession(session models.OfficerSession) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&session).Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("session_jti = ? AND revoked = ?", session.JTI, false).
+			Update("revoked", true).Error
+	})
+	if err != nil {
+		return err
+	}
+	return middleware.RevokeToken(session.JTI, session.ExpiresAt)
+}
+
+// Logout mencabut access token yang sedang dipakai, beserta refresh token bila dikirim di body
+func Logout(c *fiber.Ctx) error {
+	officerID, _ := c.Locals("officer_id").(uint64)
+	jti, _ := c.Locals("jti").(string)
+	if jti == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Token ini tidak mendukung logout, silakan login ulang"})
+	}
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+		}
+	}
+
+	var session models.OfficerSession
+	if err := database.DB.Where("jti = ?", jti).First(&session).Error; err != nil {
+		// Sesi tidak tercatat, tetap masukkan ke daftar hitam sampai batas umur token
+		if err := middleware.RevokeToken(jti, time.Now().Add(accessTokenTTL)); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal logout"})
+		}
+	} else if err := revokeOfficerSession(session); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal logout"})
+	}
+
+	if req.RefreshToken != "" {
+		database.DB.Model(&models.RefreshToken{}).
+			Where("officer_id = ? AND token_hash = ?", officerID, hashRefreshToken(req.RefreshToken)).
+			Update("revoked", true)
+	}
+
+	return c.JSON(fiber.Map{"message": "Logout berhasil"})
+}
+
+// GetMySessions menampilkan sesi aktif milik officer yang sedang login
+func GetMySessions(c *fiber.Ctx) error {
+	officerID, _ := c.Locals("officer_id").(uint64)
+	currentJTI, _ := c.Locals("jti").(string)
+
+	sessions, err := activeSessions(officerID, currentJTI)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data sesi"})
+	}
+	return c.JSON(sessions)
+}
+
+// RevokeMySession mencabut salah satu sesi milik officer yang sedang login (mis. perangkat yang hilang)
+func RevokeMySession(c *fiber.Ctx) error {
+	officerID, _ := c.Locals("officer_id").(uint64)
+	return revokeSession(c, officerID, c.Params("jti"))
+}
+
+// GetOfficerSessions menampilkan sesi aktif officer tertentu (khusus admin)
+func GetOfficerSessions(c *fiber.Ctx) error {
+	officerID, ok, err := officerIDParam(c)
+	if !ok {
+		return err
+	}
+
+	sessions, err := activeSessions(officerID, "")
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data sesi"})
+	}
+	return c.JSON(sessions)
+}
+
+// RevokeOfficerSession mencabut sesi officer tertentu (khusus admin)
+func RevokeOfficerSession(c *fiber.Ctx) error {
+	officerID, ok, err := officerIDParam(c)
+	if !ok {
+		return err
+	}
+	return revokeSession(c, officerID, c.Params("jti"))
+}
+
+// officerIDParam membaca :id dan memastikan officer-nya ada. Bila ok bernilai false,
+// response error sudah ditulis dan err harus langsung dikembalikan oleh handler.
+func officerIDParam(c *fiber.Ctx) (officerID uint64, ok bool, err error) {
+	officerID, parseErr := strconv.ParseUint(c.Params("id"), 10, 64)
+	if parseErr != nil {
+		return 0, false, c.Status(400).JSON(fiber.Map{"error": "ID officer tidak valid"})
+	}
+
+	var officer models.MarketOfficer
+	if dbErr := database.DB.Select("id").First(&officer, officerID).Error; dbErr != nil {
+		if errors.Is(dbErr, gorm.ErrRecordNotFound) {
+			return 0, false, c.Status(404).JSON(fiber.Map{"error": "Officer not found"})
+		}
+		return 0, false, c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data officer"})
+	}
+	return officerID, true, nil
+}
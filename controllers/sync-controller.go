@@ -6,202 +6,273 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// SyncBarangAndPrice synchronizes data between barang and price tables
-func SyncBarangAndPrice(c *fiber.Ctx) error {
-	// Get all barang items that need syncing
+// SyncCounts mencatat jumlah baris yang dibuat/diperbarui oleh satu sinkronisasi
+type SyncCounts struct {
+	PricesCreated int `json:"prices_created"`
+	PricesUpdated int `json:"prices_updated"`
+	BarangCreated int `json:"barang_created"`
+	BarangUpdated int `json:"barang_updated"`
+}
+
+// SyncOptions mengatur cakupan sinkronisasi. Since nil berarti sinkronisasi penuh; selain itu hanya
+// barang (tanggal_update) dan harga (updated_at) yang berubah setelah Since yang diproses.
+// UpdateWatermark menyimpan waktu mulai sinkronisasi ke SyncState bila berhasil.
+type SyncOptions struct {
+	Since           *time.Time
+	UpdateWatermark bool
+}
+
+// syncWatermark mengembalikan waktu sinkronisasi terakhir yang berhasil, nil bila belum pernah
+func syncWatermark() (*time.Time, error) {
+	var state models.SyncState
+	err := database.DB.Where("name = ?", models.SyncStateBarangPrice).Limit(1).Find(&state).Error
+	if err != nil || state.ID == 0 {
+		return nil, err
+	}
+	return &state.LastSyncedAt, nil
+}
+
+// syncPriceIsNewer menentukan pemenang konflik: true bila harga diperbarui setelah barang
+func syncPriceIsNewer(barang models.Barang, price models.Price) bool {
+	return price.UpdatedAt.After(barang.TanggalUpdate)
+}
+
+// runSync synchronizes data between barang and price tables in a single transaction.
+// counts hanya bermakna bila tidak ada error, karena error membatalkan seluruh transaksi.
+// Transaksi yang gagal karena deadlock atau lock wait timeout diulang dari awal.
+func runSync(opts SyncOptions, counts *SyncCounts) error {
+	return database.WithRetry(txMaxAttempts, func() error {
+		*counts = SyncCounts{}
+		return runSyncOnce(opts, counts)
+	})
+}
+
+func runSyncOnce(opts SyncOptions, counts *SyncCounts) error {
+	startedAt := time.Now()
+
+	// Get barang and price items that need syncing
+	barangQuery, priceQuery := database.DB, database.DB
+	if opts.Since != nil {
+		barangQuery = barangQuery.Where("tanggal_update > ?", *opts.Since)
+		priceQuery = priceQuery.Where("updated_at > ?", *opts.Since)
+	}
+
 	var barangItems []models.Barang
-	if err := database.DB.Find(&barangItems).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch barang items"})
+	if err := barangQuery.Find(&barangItems).Error; err != nil {
+		return fmt.Errorf("failed to fetch barang items: %w", err)
 	}
 
-	// Get all price items
 	var priceItems []models.Price
-	if err := database.DB.Find(&priceItems).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch price items"})
+	if err := priceQuery.Find(&priceItems).Error; err != nil {
+		return fmt.Errorf("failed to fetch price items: %w", err)
 	}
 
-	// Create maps for easier lookup
+	// Pasangan dari sisi lain diambil berdasarkan name_key, termasuk yang tidak berubah,
+	// agar sync inkremental tidak membuat duplikat
+	counterpartPrices, counterpartBarang := priceItems, barangItems
+	if opts.Since != nil {
+		barangNames := make([]string, 0, len(barangItems))
+		for _, barang := range barangItems {
+			barangNames = append(barangNames, barang.NameKey)
+		}
+		priceNames := make([]string, 0, len(priceItems))
+		for _, price := range priceItems {
+			priceNames = append(priceNames, price.NameKey)
+		}
+
+		counterpartPrices, counterpartBarang = nil, nil
+		if len(barangNames) > 0 {
+			if err := database.DB.Where("name_key IN ?", barangNames).Find(&counterpartPrices).Error; err != nil {
+				return fmt.Errorf("failed to fetch price items: %w", err)
+			}
+		}
+		if len(priceNames) > 0 {
+			if err := database.DB.Where("name_key IN ?", priceNames).Find(&counterpartBarang).Error; err != nil {
+				return fmt.Errorf("failed to fetch barang items: %w", err)
+			}
+		}
+	}
+
+	// Create maps for easier lookup, dicocokkan lewat name_key agar beda spasi/huruf tetap satu barang
 	priceMap := make(map[string]models.Price)
-	for _, price := range priceItems {
-		priceMap[price.ItemName] = price
+	for _, price := range counterpartPrices {
+		priceMap[price.NameKey] = price
 	}
 
 	barangMap := make(map[string]models.Barang)
-	for _, barang := range barangItems {
-		barangMap[barang.Nama] = barang
+	for _, barang := range counterpartBarang {
+		barangMap[barang.NameKey] = barang
 	}
 
-	// Start a transaction
-	tx := database.DB.Begin()
-
-	// Sync from barang to price
-	for _, barang := range barangItems {
-		if price, exists := priceMap[barang.Nama]; exists {
-			// If price exists but values are different, update price
-			if price.CurrentPrice != barang.HargaSekarang {
-				price.InitialPrice = price.CurrentPrice
-				price.CurrentPrice = barang.HargaSekarang
-				if price.InitialPrice > 0 {
-					price.ChangePercent = ((price.CurrentPrice - price.InitialPrice) / price.InitialPrice) * 100
-				} else {
-					price.ChangePercent = 0 // atau nilai lain yang sesuai
+	// Semua perubahan dalam satu transaksi, dibatalkan seluruhnya bila ada langkah yang gagal
+	return database.WithTransaction(func(tx *gorm.DB) error {
+		// Bila barang dan harga sama-sama berubah, sisi dengan timestamp terbaru yang menang
+		// (last-write-wins); bila sama persis barang yang menang. Tiap pasangan hanya disalin
+		// ke satu arah sehingga hasilnya tidak bergantung pada urutan loop.
+
+		// Sync from barang to price
+		for _, barang := range barangItems {
+			if price, exists := priceMap[barang.NameKey]; exists {
+				// If price exists but values are different and barang is newer, update price
+				if !pricesEqual(price.CurrentPrice, barang.HargaSekarang) && !syncPriceIsNewer(barang, price) {
+					price.InitialPrice = price.CurrentPrice
+					price.CurrentPrice = roundPrice(barang.HargaSekarang)
+					price.ChangePercent = calculateChangePercent(price.InitialPrice, price.CurrentPrice)
+					price.Reason = "sync: barang newer"
+					price.UpdatedAt = time.Now().UTC()
+					price.UpdatedBy = nil // Perubahan oleh sync, bukan petugas
+
+					if err := tx.Save(&price).Error; err != nil {
+						return fmt.Errorf("failed to update price for %s: %w", barang.Nama, err)
+					}
+
+					// Create price history
+					history := models.PriceHistory{
+						ItemID:        price.ItemID,
+						ItemName:      price.ItemName,
+						InitialPrice:  price.InitialPrice,
+						CurrentPrice:  price.CurrentPrice,
+						Reason:        price.Reason,
+						MarketID:      price.MarketID,
+						CategoryID:    price.CategoryID,
+						ChangePercent: price.ChangePercent,
+						CreatedAt:     time.Now().UTC(),
+					}
+					if err := tx.Create(&history).Error; err != nil {
+						return fmt.Errorf("failed to create price history for %s: %w", barang.Nama, err)
+					}
+					counts.PricesUpdated++
+				}
+			} else {
+				// If price doesn't exist, create a new price entry
+				// Find a suitable market and category ID
+				var marketID, categoryID uint = 1, 1 // Default values
+				if barang.CategoryID != nil {
+					categoryID = uint(*barang.CategoryID)
+
+					// Try to find a market associated with this category
+					var categoryMarket models.CategoryMarket
+					if err := tx.Where("category_id = ?", categoryID).First(&categoryMarket).Error; err == nil {
+						marketID = categoryMarket.MarketID
+					}
 				}
-				price.Reason = "Synchronized from mobile app"
-				price.UpdatedAt = time.Now().UTC()
 
-				if err := tx.Save(&price).Error; err != nil {
-					tx.Rollback()
-					return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to update price for %s: %v", barang.Nama, err)})
+				newPrice := models.Price{
+					ItemID:       sharedItemID(tx, barang.Nama),
+					ItemName:     barang.Nama,
+					InitialPrice: barang.HargaSebelumnya,
+					CurrentPrice: barang.HargaSekarang,
+					Reason:       "Created from mobile app data",
+					MarketID:     marketID,
+					CategoryID:   categoryID,
+					CreatedAt:    time.Now().UTC(),
+					UpdatedAt:    time.Now().UTC(),
+				}
+
+				// Hitung persentase perubahan dengan aman (hindari pembagian dengan nol)
+				newPrice.ChangePercent = calculateChangePercent(barang.HargaSebelumnya, barang.HargaSekarang)
+
+				// Lewat upsert agar baris yang pernah dihapus (soft delete) dihidupkan lagi, bukan bentrok
+				// dengan unique index nama per pasar
+				if _, err := upsertPrice(tx, &newPrice); err != nil {
+					return fmt.Errorf("failed to create price for %s: %w", barang.Nama, err)
 				}
 
 				// Create price history
 				history := models.PriceHistory{
-					ItemID:        price.ItemID,
-					ItemName:      price.ItemName,
-					InitialPrice:  price.InitialPrice,
-					CurrentPrice:  price.CurrentPrice,
-					Reason:        price.Reason,
-					MarketID:      price.MarketID,
-					CategoryID:    price.CategoryID,
-					ChangePercent: price.ChangePercent,
+					ItemID:        newPrice.ItemID,
+					ItemName:      newPrice.ItemName,
+					InitialPrice:  newPrice.InitialPrice,
+					CurrentPrice:  newPrice.CurrentPrice,
+					Reason:        newPrice.Reason,
+					MarketID:      newPrice.MarketID,
+					CategoryID:    newPrice.CategoryID,
+					ChangePercent: newPrice.ChangePercent,
 					CreatedAt:     time.Now().UTC(),
 				}
 				if err := tx.Create(&history).Error; err != nil {
-					tx.Rollback()
-					return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to create price history for %s: %v", barang.Nama, err)})
-				}
-			}
-		} else {
-			// If price doesn't exist, create a new price entry
-			// Find a suitable market and category ID
-			var marketID, categoryID uint = 1, 1 // Default values
-			if barang.CategoryID != nil {
-				categoryID = uint(*barang.CategoryID)
-
-				// Try to find a market associated with this category
-				var categoryMarket models.CategoryMarket
-				if err := tx.Where("category_id = ?", categoryID).First(&categoryMarket).Error; err == nil {
-					marketID = categoryMarket.MarketID
+					return fmt.Errorf("failed to create price history for %s: %w", barang.Nama, err)
 				}
-			}
-
-			newPrice := models.Price{
-				ItemID:       uint(barang.IdBarang),
-				ItemName:     barang.Nama,
-				InitialPrice: barang.HargaSebelumnya,
-				CurrentPrice: barang.HargaSekarang,
-				Reason:       "Created from mobile app data",
-				MarketID:     marketID,
-				CategoryID:   categoryID,
-				CreatedAt:    time.Now().UTC(),
-				UpdatedAt:    time.Now().UTC(),
-			}
-
-			// Hitung persentase perubahan dengan aman (hindari pembagian dengan nol)
-			if barang.HargaSebelumnya > 0 {
-				newPrice.ChangePercent = ((barang.HargaSekarang - barang.HargaSebelumnya) / barang.HargaSebelumnya) * 100
-			} else {
-				// Untuk barang baru atau harga sebelumnya 0, set persentase perubahan ke 0 atau nilai lain yang sesuai
-				newPrice.ChangePercent = 0 // atau 100 jika ingin menandai sebagai barang baru
-			}
-
-			if err := tx.Create(&newPrice).Error; err != nil {
-				tx.Rollback()
-				return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to create price for %s: %v", barang.Nama, err)})
-			}
-
-			// Create price history
-			history := models.PriceHistory{
-				ItemID:        newPrice.ItemID,
-				ItemName:      newPrice.ItemName,
-				InitialPrice:  newPrice.InitialPrice,
-				CurrentPrice:  newPrice.CurrentPrice,
-				Reason:        newPrice.Reason,
-				MarketID:      newPrice.MarketID,
-				CategoryID:    newPrice.CategoryID,
-				ChangePercent: newPrice.ChangePercent,
-				CreatedAt:     time.Now().UTC(),
-			}
-			if err := tx.Create(&history).Error; err != nil {
-				tx.Rollback()
-				return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to create price history for %s: %v", barang.Nama, err)})
+				counts.PricesCreated++
 			}
 		}
-	}
 
-	// Sync from price to barang
-	for _, price := range priceItems {
-		if barang, exists := barangMap[price.ItemName]; exists {
-			// If barang exists but values are different, update barang
-			if barang.HargaSekarang != price.CurrentPrice {
-				// Create barang history before updating
-				history := models.BarangHistory{
-					BarangID:       barang.IdBarang,
-					HargaPedagang1: barang.HargaPedagang1,
-					HargaPedagang2: barang.HargaPedagang2,
-					HargaPedagang3: barang.HargaPedagang3,
-					HargaSekarang:  barang.HargaSekarang,
-					TanggalUpdate:  time.Now().UTC(),
+		// Sync from price to barang
+		for _, price := range priceItems {
+			if barang, exists := barangMap[price.NameKey]; exists {
+				// If barang exists but values are different and price is newer, update barang
+				if !pricesEqual(barang.HargaSekarang, price.CurrentPrice) && syncPriceIsNewer(barang, price) {
+					// Create barang history before updating
+					history := models.BarangHistory{
+						BarangID:       barang.IdBarang,
+						HargaPedagang1: barang.HargaPedagang1,
+						HargaPedagang2: barang.HargaPedagang2,
+						HargaPedagang3: barang.HargaPedagang3,
+						HargaSekarang:  barang.HargaSekarang,
+						TanggalUpdate:  time.Now().UTC(),
+					}
+					if err := tx.Create(&history).Error; err != nil {
+						return fmt.Errorf("failed to create barang history for %s: %w", price.ItemName, err)
+					}
+
+					// Update barang
+					barang.HargaSebelumnya = barang.HargaSekarang
+					barang.HargaSekarang = roundPrice(price.CurrentPrice)
+					barang.AlasanPerubahan = "sync: price newer"
+					barang.TanggalUpdate = time.Now().UTC()
+					barang.UpdatedBy = nil
+
+					if err := tx.Save(&barang).Error; err != nil {
+						return fmt.Errorf("failed to update barang for %s: %w", price.ItemName, err)
+					}
+					counts.BarangUpdated++
 				}
-				if err := tx.Create(&history).Error; err != nil {
-					tx.Rollback()
-					return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to create barang history for %s: %v", price.ItemName, err)})
+			} else {
+				// If barang doesn't exist, create a new barang entry
+				// For new barang, we need to set default values for the three merchant prices
+				// We'll set them all to the current price for simplicity
+				avgPrice := price.CurrentPrice
+
+				newBarang := models.Barang{
+					Nama:            price.ItemName,
+					Satuan:          "unit", // Default value
+					HargaPedagang1:  avgPrice,
+					HargaPedagang2:  avgPrice,
+					HargaPedagang3:  avgPrice,
+					HargaSebelumnya: price.InitialPrice,
+					HargaSekarang:   price.CurrentPrice,
+					AlasanPerubahan: "Created from web app data",
+					TanggalUpdate:   time.Now().UTC(),
 				}
 
-				// Update barang
-				barang.HargaSebelumnya = barang.HargaSekarang
-				barang.HargaSekarang = price.CurrentPrice
-				barang.AlasanPerubahan = "Synchronized from web app"
-				barang.TanggalUpdate = time.Now().UTC()
-
-				if err := tx.Save(&barang).Error; err != nil {
-					tx.Rollback()
-					return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to update barang for %s: %v", price.ItemName, err)})
+				// Set category if available
+				if price.CategoryID > 0 {
+					categoryID := uint(price.CategoryID)
+					newBarang.CategoryID = &categoryID
 				}
-			}
-		} else {
-			// If barang doesn't exist, create a new barang entry
-			// For new barang, we need to set default values for the three merchant prices
-			// We'll set them all to the current price for simplicity
-			avgPrice := price.CurrentPrice
-
-			newBarang := models.Barang{
-				Nama:            price.ItemName,
-				Satuan:          "unit", // Default value
-				HargaPedagang1:  avgPrice,
-				HargaPedagang2:  avgPrice,
-				HargaPedagang3:  avgPrice,
-				HargaSebelumnya: price.InitialPrice,
-				HargaSekarang:   price.CurrentPrice,
-				AlasanPerubahan: "Created from web app data",
-				TanggalUpdate:   time.Now().UTC(),
-			}
 
-			// Set category if available
-			if price.CategoryID > 0 {
-				categoryID := uint(price.CategoryID)
-				newBarang.CategoryID = &categoryID
+				if err := tx.Create(&newBarang).Error; err != nil {
+					return fmt.Errorf("failed to create barang for %s: %w", price.ItemName, err)
+				}
+				counts.BarangCreated++
 			}
+		}
 
-			if err := tx.Create(&newBarang).Error; err != nil {
-				tx.Rollback()
-				return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to create barang for %s: %v", price.ItemName, err)})
+		if opts.UpdateWatermark {
+			state := models.SyncState{Name: models.SyncStateBarangPrice, LastSyncedAt: startedAt}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"last_synced_at", "updated_at"}),
+			}).Create(&state).Error; err != nil {
+				return fmt.Errorf("failed to save sync watermark: %w", err)
 			}
 		}
-	}
-
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("Failed to commit transaction: %v", err)})
-	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Synchronization completed successfully",
+		return nil
 	})
 }
 
@@ -213,7 +284,7 @@ func SyncBarangWithPrice(barangID uint64, tx *gorm.DB) error {
 	}
 
 	var price models.Price
-	if err := tx.Where("item_name = ?", barang.Nama).First(&price).Error; err != nil {
+	if err := tx.Where("name_key = ?", models.NameKey(barang.Nama)).First(&price).Error; err != nil {
 		// Price doesn't exist, create a new one
 		var marketID, categoryID uint
 		marketID = barang.MarketID // Langsung ambil dari field barang
@@ -225,7 +296,7 @@ func SyncBarangWithPrice(barangID uint64, tx *gorm.DB) error {
 		}
 
 		newPrice := models.Price{
-			ItemID:       uint(barang.IdBarang),
+			ItemID:       sharedItemID(tx, barang.Nama),
 			ItemName:     barang.Nama,
 			InitialPrice: barang.HargaSebelumnya,
 			CurrentPrice: barang.HargaSekarang,
@@ -237,15 +308,12 @@ func SyncBarangWithPrice(barangID uint64, tx *gorm.DB) error {
 		}
 
 		// Hitung persentase perubahan dengan aman (hindari pembagian dengan nol)
-		if barang.HargaSebelumnya > 0 {
-			newPrice.ChangePercent = ((barang.HargaSekarang - barang.HargaSebelumnya) / barang.HargaSebelumnya) * 100
-		} else {
-			// Untuk barang baru atau harga sebelumnya 0, set persentase perubahan ke 0 atau nilai lain yang sesuai
-			newPrice.ChangePercent = 0 // atau 100 jika ingin menandai sebagai barang baru
-		}
+		newPrice.ChangePercent = calculateChangePercent(barang.HargaSebelumnya, barang.HargaSekarang)
 
-		if err := tx.Create(&newPrice).Error; err != nil {
-			return fmt.Errorf("failed to create price: %v", err)
+		// Lewat upsert agar baris yang pernah dihapus (soft delete) dihidupkan lagi, bukan bentrok
+		// dengan unique index nama per pasar
+		if _, err := upsertPrice(tx, &newPrice); err != nil {
+			return fmt.Errorf("failed to create price: %w", err)
 		}
 
 		// Create price history
@@ -265,16 +333,13 @@ func SyncBarangWithPrice(barangID uint64, tx *gorm.DB) error {
 		}
 	} else {
 		// Price exists, update it if needed
-		if price.CurrentPrice != barang.HargaSekarang {
+		if !pricesEqual(price.CurrentPrice, barang.HargaSekarang) {
 			price.InitialPrice = price.CurrentPrice
-			price.CurrentPrice = barang.HargaSekarang
-			if price.InitialPrice > 0 {
-				price.ChangePercent = ((price.CurrentPrice - price.InitialPrice) / price.InitialPrice) * 100
-			} else {
-				price.ChangePercent = 0 // atau nilai lain yang sesuai
-			}
+			price.CurrentPrice = roundPrice(barang.HargaSekarang)
+			price.ChangePercent = calculateChangePercent(price.InitialPrice, price.CurrentPrice)
 			price.Reason = barang.AlasanPerubahan
 			price.UpdatedAt = time.Now().UTC()
+			price.UpdatedBy = nil // Perubahan oleh sync, bukan petugas
 
 			if err := tx.Save(&price).Error; err != nil {
 				return fmt.Errorf("failed to update price: %v", err)
@@ -309,7 +374,7 @@ func SyncPriceWithBarang(priceID uint, tx *gorm.DB) error {
 	}
 
 	var barang models.Barang
-	if err := tx.Where("nama = ?", price.ItemName).First(&barang).Error; err != nil {
+	if err := tx.Where("name_key = ?", models.NameKey(price.ItemName)).First(&barang).Error; err != nil {
 		// Barang doesn't exist, create a new one
 		avgPrice := price.CurrentPrice
 
@@ -344,7 +409,7 @@ func SyncPriceWithBarang(priceID uint, tx *gorm.DB) error {
 		}
 	} else {
 		// Barang exists, update it if needed
-		if barang.HargaSekarang != price.CurrentPrice {
+		if !pricesEqual(barang.HargaSekarang, price.CurrentPrice) {
 			// Simpan histori sebelum update
 			history := models.BarangHistory{
 				BarangID:       barang.IdBarang,
@@ -360,9 +425,10 @@ func SyncPriceWithBarang(priceID uint, tx *gorm.DB) error {
 
 			// Lanjut update barang
 			barang.HargaSebelumnya = barang.HargaSekarang
-			barang.HargaSekarang = price.CurrentPrice
+			barang.HargaSekarang = roundPrice(price.CurrentPrice)
 			barang.AlasanPerubahan = price.Reason
 			barang.TanggalUpdate = time.Now().UTC()
+			barang.UpdatedBy = nil
 
 			if err := tx.Save(&barang).Error; err != nil {
 				return fmt.Errorf("failed to update barang: %v", err)
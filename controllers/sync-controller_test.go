@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"backend/models"
+	"testing"
+	"time"
+)
+
+func TestRunSyncLastWriteWins(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	older, newer := now.Add(-time.Hour), now
+
+	tests := []struct {
+		name            string
+		barangUpdatedAt time.Time
+		priceUpdatedAt  time.Time
+		want            float64
+		wantCounts      SyncCounts
+	}{
+		{"barang lebih baru menimpa harga", newer, older, 12000, SyncCounts{PricesUpdated: 1}},
+		{"harga lebih baru menimpa barang", older, newer, 15000, SyncCounts{BarangUpdated: 1}},
+		{"timestamp sama dimenangkan barang", now, now, 12000, SyncCounts{PricesUpdated: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useTestDB(t)
+			market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+			mustCreate(t, db, &market)
+			barang := models.Barang{Nama: "Cabai", MarketID: market.ID, HargaSekarang: 12000, TanggalUpdate: tt.barangUpdatedAt}
+			price := models.Price{ItemName: "cabai ", MarketID: market.ID, InitialPrice: 10000, CurrentPrice: 15000, UpdatedAt: tt.priceUpdatedAt}
+			mustCreate(t, db, &barang, &price)
+
+			var counts SyncCounts
+			if err := runSync(SyncOptions{}, &counts); err != nil {
+				t.Fatal(err)
+			}
+			if counts != tt.wantCounts {
+				t.Errorf("counts = %+v, want %+v", counts, tt.wantCounts)
+			}
+
+			db.First(&barang, barang.IdBarang)
+			db.First(&price, price.ID)
+			if barang.HargaSekarang != tt.want || price.CurrentPrice != tt.want {
+				t.Errorf("barang = %v, harga = %v, want keduanya %v", barang.HargaSekarang, price.CurrentPrice, tt.want)
+			}
+
+			// Sinkronisasi kedua tidak mengubah apa pun karena kedua sisi sudah sama
+			if err := runSync(SyncOptions{}, &counts); err != nil {
+				t.Fatal(err)
+			}
+			if counts != (SyncCounts{}) {
+				t.Errorf("sinkronisasi ulang: counts = %+v, want kosong", counts)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	SyncJobPending = "pending"
+	SyncJobRunning = "running"
+	SyncJobDone    = "done"
+	SyncJobFailed  = "failed"
+)
+
+// Job yang sudah selesai lebih lama dari ini dibuang dari registry
+const syncJobRetention = time.Hour
+
+type SyncJob struct {
+	ID         string     `json:"job_id"`
+	Status     string     `json:"status"`
+	Mode       string     `json:"mode"`
+	Since      *time.Time `json:"since,omitempty"`
+	Counts     SyncCounts `json:"counts"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// syncJobRegistry menyimpan job sinkronisasi di memori. Job dijalankan satu per satu oleh
+// satu worker goroutine karena dua sinkronisasi penuh yang berjalan bersamaan akan saling tabrakan.
+type syncJobRegistry struct {
+	mu        sync.Mutex
+	jobs      map[string]*SyncJob
+	options   map[string]SyncOptions
+	queue     chan string
+	startOnce sync.Once
+	run       func(SyncOptions, *SyncCounts) error
+}
+
+var syncJobs = &syncJobRegistry{
+	jobs:    make(map[string]*SyncJob),
+	options: make(map[string]SyncOptions),
+	queue:   make(chan string, 16),
+	run:     runSync,
+}
+
+// enqueue membuat job baru, atau mengembalikan job yang masih pending/running
+func (r *syncJobRegistry) enqueue(opts SyncOptions) (SyncJob, bool) {
+	r.startOnce.Do(func() { go r.worker() })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, job := range r.jobs {
+		if job.Status == SyncJobPending || job.Status == SyncJobRunning {
+			return *job, false
+		}
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > syncJobRetention {
+			delete(r.jobs, id)
+			delete(r.options, id)
+		}
+	}
+
+	job := &SyncJob{ID: uuid.NewString(), Status: SyncJobPending, Mode: "full", Since: opts.Since, CreatedAt: now}
+	if opts.Since != nil {
+		job.Mode = "incremental"
+	}
+	r.jobs[job.ID] = job
+	r.options[job.ID] = opts
+	r.queue <- job.ID
+	return *job, true
+}
+
+func (r *syncJobRegistry) get(id string) (SyncJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return SyncJob{}, false
+	}
+	return *job, true
+}
+
+func (r *syncJobRegistry) update(id string, fn func(job *SyncJob)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job, ok := r.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+func (r *syncJobRegistry) worker() {
+	for id := range r.queue {
+		r.mu.Lock()
+		opts := r.options[id]
+		r.mu.Unlock()
+
+		r.update(id, func(job *SyncJob) {
+			now := time.Now()
+			job.Status = SyncJobRunning
+			job.StartedAt = &now
+		})
+
+		var counts SyncCounts
+		err := r.runSafely(opts, &counts)
+
+		r.update(id, func(job *SyncJob) {
+			now := time.Now()
+			job.FinishedAt = &now
+			if err != nil {
+				slog.Error("sync job gagal", "job_id", id, "error", err)
+				job.Status = SyncJobFailed
+				job.Error = err.Error()
+				return
+			}
+			job.Status = SyncJobDone
+			job.Counts = counts
+		})
+	}
+}
+
+// runSafely menjalankan sinkronisasi dan mengubah panic menjadi error agar worker tetap hidup
+func (r *syncJobRegistry) runSafely(opts SyncOptions, counts *SyncCounts) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return r.run(opts, counts)
+}
+
+// SyncBarangAndPrice menjadwalkan sinkronisasi barang dan harga di background dan langsung
+// mengembalikan 202 dengan job_id. Bila masih ada sinkronisasi yang berjalan, job tersebut yang
+// dikembalikan. Dengan ?since=<waktu> hanya data yang berubah setelah waktu itu yang diproses;
+// tanpa since dipakai watermark sinkronisasi terakhir yang berhasil, atau sinkronisasi penuh
+// bila belum pernah ada.
+func SyncBarangAndPrice(c *fiber.Ctx) error {
+	watermark, err := syncWatermark()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membaca watermark sinkronisasi"})
+	}
+
+	opts := SyncOptions{Since: watermark, UpdateWatermark: true}
+	if value := c.Query("since"); value != "" {
+		since, err := parseTimestamp(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "since: " + err.Error()})
+		}
+		opts.Since = &since
+		// Watermark tidak dimajukan melewati data yang belum pernah disinkronkan
+		opts.UpdateWatermark = watermark != nil && !since.After(*watermark)
+	}
+
+	job, created := syncJobs.enqueue(opts)
+
+	message := "Sinkronisasi dijadwalkan"
+	if !created {
+		message = "Sinkronisasi lain masih berjalan"
+	}
+
+	c.Set(fiber.HeaderLocation, "/api/sync/status/"+job.ID)
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"job_id":  job.ID,
+		"status":  job.Status,
+	})
+}
+
+// GetSyncStatus menampilkan status job sinkronisasi beserta jumlah baris yang dibuat/diperbarui
+func GetSyncStatus(c *fiber.Ctx) error {
+	job, ok := syncJobs.get(c.Params("job_id"))
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "Job sinkronisasi tidak ditemukan"})
+	}
+	return c.JSON(job)
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// useSyncJobs mengganti registry global dengan registry yang menjalankan run
+func useSyncJobs(t *testing.T, run func(SyncOptions, *SyncCounts) error) *syncJobRegistry {
+	t.Helper()
+	registry := &syncJobRegistry{
+		jobs:    make(map[string]*SyncJob),
+		options: make(map[string]SyncOptions),
+		queue:   make(chan string, 16),
+		run:     run,
+	}
+	previous := syncJobs
+	syncJobs = registry
+	t.Cleanup(func() {
+		syncJobs = previous
+		close(registry.queue)
+	})
+	return registry
+}
+
+// waitSyncJob menunggu sampai job selesai atau gagal
+func waitSyncJob(t *testing.T, registry *syncJobRegistry, id string) SyncJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := registry.get(id)
+		if !ok {
+			t.Fatalf("job %s tidak ditemukan", id)
+		}
+		if job.Status == SyncJobDone || job.Status == SyncJobFailed {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s tidak selesai", id)
+	return SyncJob{}
+}
+
+func TestSyncJobRegistry(t *testing.T) {
+	release := make(chan struct{})
+	registry := useSyncJobs(t, func(opts SyncOptions, counts *SyncCounts) error {
+		<-release
+		counts.PricesUpdated = 2
+		return nil
+	})
+
+	job, created := registry.enqueue(SyncOptions{})
+	if !created || job.Mode != "full" {
+		t.Fatalf("enqueue = %+v, %v, want job full baru", job, created)
+	}
+
+	// Selama job pertama belum selesai, job yang sama dikembalikan
+	since := time.Now()
+	again, created := registry.enqueue(SyncOptions{Since: &since})
+	if created || again.ID != job.ID {
+		t.Errorf("enqueue kedua = %s, %v, want job %s yang sudah ada", again.ID, created, job.ID)
+	}
+
+	close(release)
+	done := waitSyncJob(t, registry, job.ID)
+	if done.Status != SyncJobDone || done.Counts.PricesUpdated != 2 || done.StartedAt == nil || done.FinishedAt == nil {
+		t.Errorf("job = %+v, want done dengan counts dan waktu mulai/selesai", done)
+	}
+
+	// Setelah selesai, job baru bisa dibuat
+	next, created := registry.enqueue(SyncOptions{Since: &since})
+	if !created || next.ID == job.ID || next.Mode != "incremental" {
+		t.Errorf("enqueue setelah selesai = %+v, %v, want job incremental baru", next, created)
+	}
+	waitSyncJob(t, registry, next.ID)
+}
+
+func TestSyncJobFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(SyncOptions, *SyncCounts) error
+		wantErr string
+	}{
+		{"error", func(SyncOptions, *SyncCounts) error { return errors.New("database tidak tersedia") }, "database tidak tersedia"},
+		{"panic", func(SyncOptions, *SyncCounts) error { panic("nil map") }, "panic: nil map"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := useSyncJobs(t, tt.run)
+			job, _ := registry.enqueue(SyncOptions{})
+			failed := waitSyncJob(t, registry, job.ID)
+			if failed.Status != SyncJobFailed || failed.Error != tt.wantErr {
+				t.Errorf("job = %s (%q), want failed (%q)", failed.Status, failed.Error, tt.wantErr)
+			}
+
+			// Worker tetap hidup dan memproses job berikutnya
+			next, _ := registry.enqueue(SyncOptions{})
+			waitSyncJob(t, registry, next.ID)
+		})
+	}
+}
+
+func TestSyncEndpointsPollJobStatus(t *testing.T) {
+	useTestDB(t)
+	release := make(chan struct{})
+	useSyncJobs(t, func(opts SyncOptions, counts *SyncCounts) error {
+		<-release
+		counts.BarangCreated = 1
+		return nil
+	})
+
+	app := fiber.New()
+	app.Get("/api/sync", SyncBarangAndPrice)
+	app.Get("/api/sync/status/:job_id", GetSyncStatus)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/sync", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accepted struct {
+		JobID  string `json:"job_id"`
+		Status string `json:"status"`
+	}
+	json.NewDecoder(resp.Body).Decode(&accepted)
+	if resp.StatusCode != fiber.StatusAccepted || accepted.JobID == "" {
+		t.Fatalf("status = %d, job_id = %q, want 202 dengan job_id", resp.StatusCode, accepted.JobID)
+	}
+	location := resp.Header.Get(fiber.HeaderLocation)
+	if location != "/api/sync/status/"+accepted.JobID {
+		t.Errorf("Location = %q", location)
+	}
+
+	poll := func() (int, SyncJob) {
+		resp, err := app.Test(httptest.NewRequest("GET", location, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var job SyncJob
+		json.NewDecoder(resp.Body).Decode(&job)
+		return resp.StatusCode, job
+	}
+
+	if status, job := poll(); status != 200 || (job.Status != SyncJobPending && job.Status != SyncJobRunning) {
+		t.Errorf("sebelum selesai: status = %d, job = %s, want pending/running", status, job.Status)
+	}
+
+	close(release)
+	waitSyncJob(t, syncJobs, accepted.JobID)
+	if status, job := poll(); status != 200 || job.Status != SyncJobDone || job.Counts.BarangCreated != 1 {
+		t.Errorf("setelah selesai: status = %d, job = %+v", status, job)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/sync/status/tidak-ada", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 404 {
+		t.Errorf("job tidak dikenal: status = %d, want 404", resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/sync?since=kemarin", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("since tidak valid: status = %d, want 400", resp.StatusCode)
+	}
+}
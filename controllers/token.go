@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"backend/auth"
+	"backend/database"
+	"backend/logger"
+	"backend/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	accessTokenTTL  = 24 * time.Hour
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// OfficerTokens adalah pasangan access token (JWT) dan refresh token (opaque) hasil login
+type OfficerTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// IssueOfficerTokens membuat sesi baru, access token JWT dengan jti sesi tersebut,
+// dan refresh token untuk officer. Dipakai oleh semua handler login dan refresh.
+func IssueOfficerTokens(c *fiber.Ctx, tx *gorm.DB, officer models.MarketOfficer) (OfficerTokens, error) {
+	var tokens OfficerTokens
+
+	issuedAt := time.Now()
+	expirationTime := issuedAt.Add(accessTokenTTL)
+	jti := uuid.NewString()
+	claims := jwt.MapClaims{
+		"username":   officer.Username,
+		"officer_id": officer.ID,
+		"market_id":  officer.MarketID,
+		"role":       officerRole(officer),
+		"jti":        jti,
+		"iat":        issuedAt.Unix(),
+		"exp":        expirationTime.Unix(),
+	}
+	logger.FromCtx(c).Debug("membuat token officer", "username", officer.Username, "officer_id", officer.ID, "market_id", officer.MarketID)
+
+	accessToken, err := auth.SignToken(claims)
+	if err != nil {
+		return tokens, err
+	}
+
+	// Catat sesi agar token ini bisa dilihat dan dicabut per perangkat
+	session := models.OfficerSession{
+		JTI:       jti,
+		OfficerID: officer.ID,
+		Device:    sessionDevice(c),
+		IPAddress: c.IP(),
+		IssuedAt:  issuedAt,
+		ExpiresAt: expirationTime,
+		LastSeen:  issuedAt,
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return tokens, err
+	}
+
+	refreshToken, err := createRefreshToken(tx, officer.ID, jti)
+	if err != nil {
+		return tokens, err
+	}
+
+	tokens.AccessToken = accessToken
+	tokens.RefreshToken = refreshToken
+	return tokens, nil
+}
+
+// officerRole mengembalikan role officer, petugas tanpa role dianggap petugas lapangan
+func officerRole(officer models.MarketOfficer) string {
+	if officer.Role == "" {
+		return models.OfficerRoleOfficer
+	}
+	return officer.Role
+}
+
+func isValidOfficerRole(role string) bool {
+	return role == models.OfficerRoleAdmin || role == models.OfficerRoleOfficer
+}
+
+// createRefreshToken membuat nilai acak 32 byte dan menyimpan hash-nya, ditautkan ke sesi jti
+func createRefreshToken(tx *gorm.DB, officerID uint64, jti string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(buf)
+
+	record := models.RefreshToken{
+		OfficerID:  officerID,
+		SessionJTI: jti,
+		TokenHash:  hashRefreshToken(value),
+		ExpiresAt:  time.Now().Add(refreshTokenTTL),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+func hashRefreshToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewOfficerResponse menyusun OfficerResponse, market hanya diisi bila relasi Market sudah di-preload
+func NewOfficerResponse(officer models.MarketOfficer) *OfficerResponse {
+	response := &OfficerResponse{
+		ID:       officer.ID,
+		Name:     officer.Name,
+		Username: officer.Username,
+		Role:     officer.Role,
+		Nik:      officer.Nik,
+		Phone:    officer.Phone,
+		ImageURL: officer.ImageURL,
+		MarketID: officer.MarketID,
+	}
+	if officer.Market.ID != 0 {
+		response.Market = &MarketResponse{
+			ID:        officer.Market.ID,
+			Name:      officer.Market.Name,
+			Location:  officer.Market.Location,
+			ImageURL:  officer.Market.ImageURL,
+			Latitude:  officer.Market.Latitude,
+			Longitude: officer.Market.Longitude,
+		}
+	}
+	return response
+}
+
+var errRefreshTokenInvalid = errors.New("refresh token tidak valid")
+
+// RefreshToken menukar refresh token dengan access token baru. Refresh token lama langsung
+// dicabut (rotasi); bila token yang sudah dicabut dipakai lagi, semua refresh token milik
+// officer tersebut ikut dicabut karena kemungkinan token sudah bocor.
+func RefreshToken(c *fiber.Ctx) error {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(http.StatusBadRequest).JSON(LoginResponse{
+			Success: false,
+			Message: "refresh_token wajib diisi",
+		})
+	}
+
+	var officer models.MarketOfficer
+	var tokens OfficerTokens
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var record models.RefreshToken
+		if err := tx.Where("token_hash = ?", hashRefreshToken(req.RefreshToken)).First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRefreshTokenInvalid
+			}
+			return err
+		}
+
+		if record.Revoked {
+			logger.FromCtx(c).Warn("refresh token yang sudah dicabut dipakai ulang", "officer_id", record.OfficerID)
+			return errRefreshTokenInvalid
+		}
+		if time.Now().After(record.ExpiresAt) {
+			return errRefreshTokenInvalid
+		}
+
+		// Sesi yang sudah dicabut (logout, cabut perangkat) tidak boleh diperpanjang lewat refresh
+		if record.SessionJTI != "" {
+			var session models.OfficerSession
+			err := tx.Where("jti = ?", record.SessionJTI).First(&session).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil && session.RevokedAt != nil {
+				return errRefreshTokenInvalid
+			}
+		}
+
+		if err := tx.Preload("Market").First(&officer, record.OfficerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRefreshTokenInvalid
+			}
+			return err
+		}
+		if !officer.IsActive {
+			return errRefreshTokenInvalid
+		}
+
+		// Rotasi atomik: bila dua request memakai token yang sama bersamaan, hanya satu yang
+		// berhasil mencabutnya, yang lain diperlakukan sebagai pemakaian ulang
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked = ?", record.ID, false).
+			Update("revoked", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errRefreshTokenInvalid
+		}
+
+		issued, err := IssueOfficerTokens(c, tx, officer)
+		if err != nil {
+			return err
+		}
+		tokens = issued
+		return nil
+	})
+
+	if errors.Is(err, errRefreshTokenInvalid) {
+		revokeReusedRefreshToken(req.RefreshToken)
+		return c.Status(http.StatusUnauthorized).JSON(LoginResponse{
+			Success: false,
+			Message: "Refresh token tidak valid atau sudah kedaluwarsa",
+		})
+	}
+	if err != nil {
+		logger.FromCtx(c).Error("gagal refresh token", "error", err)
+		return c.Status(http.StatusInternalServerError).JSON(LoginResponse{
+			Success: false,
+			Message: "Gagal memperbarui token",
+		})
+	}
+
+	officerResponse := NewOfficerResponse(officer)
+	return c.JSON(LoginResponse{
+		Success: true,
+		Message: "Token diperbarui",
+		Data: &LoginResponseData{
+			Officer:      officerResponse,
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			Market:       officerResponse.Market,
+		},
+	})
+}
+
+// revokeReusedRefreshToken mencabut semua refresh token officer bila token yang dikirim
+// ternyata sudah pernah dicabut sebelumnya
+func revokeReusedRefreshToken(value string) {
+	var record models.RefreshToken
+	if err := database.DB.Where("token_hash = ? AND revoked = ?", hashRefreshToken(value), true).First(&record).Error; err != nil {
+		return
+	}
+	database.DB.Model(&models.RefreshToken{}).
+		Where("officer_id = ? AND revoked = ?", record.OfficerID, false).
+		Update("revoked", true)
+}
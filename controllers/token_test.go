@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"backend/models"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// seedRefreshToken membuat petugas aktif dengan satu sesi dan refresh token-nya
+func seedRefreshToken(t *testing.T, db *gorm.DB) (models.MarketOfficer, string) {
+	t.Helper()
+	market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	mustCreate(t, db, &market)
+	officer := models.MarketOfficer{Name: "Budi", Nik: "3201010101010001", Username: "budi", MarketID: uint64(market.ID), IsActive: true}
+	mustCreate(t, db, &officer)
+
+	session := models.OfficerSession{JTI: "sesi-1", OfficerID: officer.ID, IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	mustCreate(t, db, &session)
+	value, err := createRefreshToken(db, officer.ID, session.JTI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return officer, value
+}
+
+func postRefresh(t *testing.T, app *fiber.App, value string) int {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(`{"refresh_token":"`+value+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func refreshApp() *fiber.App {
+	app := fiber.New()
+	app.Post("/auth/refresh", RefreshToken)
+	return app
+}
+
+func activeRefreshTokens(t *testing.T, db *gorm.DB, officerID uint64) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&models.RefreshToken{}).Where("officer_id = ? AND revoked = ?", officerID, false).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	db := useTestDB(t)
+	officer, value := seedRefreshToken(t, db)
+	app := refreshApp()
+
+	if status := postRefresh(t, app, value); status != 200 {
+		t.Fatalf("refresh pertama: status = %d, want 200", status)
+	}
+	var old models.RefreshToken
+	if err := db.Where("token_hash = ?", hashRefreshToken(value)).First(&old).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !old.Revoked {
+		t.Error("refresh token lama belum dicabut setelah rotasi")
+	}
+	if got := activeRefreshTokens(t, db, officer.ID); got != 1 {
+		t.Errorf("refresh token aktif = %d, want 1 (hasil rotasi)", got)
+	}
+}
+
+func TestRefreshTokenReuseRevokesAllTokens(t *testing.T) {
+	db := useTestDB(t)
+	officer, value := seedRefreshToken(t, db)
+	app := refreshApp()
+
+	if status := postRefresh(t, app, value); status != 200 {
+		t.Fatalf("refresh pertama: status = %d, want 200", status)
+	}
+	if status := postRefresh(t, app, value); status != 401 {
+		t.Fatalf("pemakaian ulang: status = %d, want 401", status)
+	}
+	if got := activeRefreshTokens(t, db, officer.ID); got != 0 {
+		t.Errorf("refresh token aktif = %d, want 0 setelah pemakaian ulang", got)
+	}
+}
+
+func TestRefreshTokenRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, db *gorm.DB, value string)
+	}{
+		{"kedaluwarsa", func(t *testing.T, db *gorm.DB, value string) {
+			db.Model(&models.RefreshToken{}).Where("token_hash = ?", hashRefreshToken(value)).Update("expires_at", time.Now().Add(-time.Minute))
+		}},
+		{"sudah dicabut", func(t *testing.T, db *gorm.DB, value string) {
+			db.Model(&models.RefreshToken{}).Where("token_hash = ?", hashRefreshToken(value)).Update("revoked", true)
+		}},
+		{"sesi sudah dicabut", func(t *testing.T, db *gorm.DB, value string) {
+			db.Model(&models.OfficerSession{}).Where("jti = ?", "sesi-1").Update("revoked_at", time.Now())
+		}},
+		{"petugas nonaktif", func(t *testing.T, db *gorm.DB, value string) {
+			db.Model(&models.MarketOfficer{}).Where("username = ?", "budi").Update("is_active", false)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := useTestDB(t)
+			_, value := seedRefreshToken(t, db)
+			tt.setup(t, db, value)
+
+			if status := postRefresh(t, refreshApp(), value); status != 401 {
+				t.Errorf("status = %d, want 401", status)
+			}
+		})
+	}
+
+	t.Run("tidak dikenal", func(t *testing.T) {
+		useTestDB(t)
+		if status := postRefresh(t, refreshApp(), "tidak-ada"); status != 401 {
+			t.Errorf("status = %d, want 401", status)
+		}
+	})
+}
+
+// Request lain yang memakai token yang sama lebih dulu mencabutnya di antara pengecekan dan
+// rotasi; request ini harus kalah dan tidak boleh ikut mendapat token baru
+func TestRefreshTokenConcurrentRotation(t *testing.T) {
+	db := useTestDB(t)
+	officer, value := seedRefreshToken(t, db)
+
+	raced := false
+	err := db.Callback().Update().Before("gorm:update").Register("test:concurrent_refresh", func(tx *gorm.DB) {
+		if raced || tx.Statement.Table != "refresh_tokens" {
+			return
+		}
+		raced = true
+		tx.Session(&gorm.Session{NewDB: true}).Exec("UPDATE refresh_tokens SET revoked = ? WHERE token_hash = ?", true, hashRefreshToken(value))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status := postRefresh(t, refreshApp(), value); status != 401 {
+		t.Fatalf("status = %d, want 401", status)
+	}
+	var sessions int64
+	db.Model(&models.OfficerSession{}).Where("officer_id = ?", officer.ID).Count(&sessions)
+	if sessions != 1 {
+		t.Errorf("sesi = %d, want 1 (tidak ada token baru yang diterbitkan)", sessions)
+	}
+}
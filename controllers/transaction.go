@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"backend/logger"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Jumlah percobaan transaksi yang gagal karena deadlock atau lock wait timeout
+const txMaxAttempts = 3
+
+// txError membungkus err dengan status dan pesan untuk client. err tetap bisa dibaca lewat
+// errors.As/Is sehingga database.WithRetry masih mengenali deadlock di dalamnya.
+func txError(status int, message string, err error) error {
+	return fmt.Errorf("%w: %w", fiber.NewError(status, message), err)
+}
+
+// txErrorResponse membalas error dari database.WithTransaction. *fiber.Error dari dalam
+// closure dipakai apa adanya (status dan pesan), error lain dicatat dan dibalas 500 dengan fallback.
+func txErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		if fe.Code >= 500 {
+			logger.FromCtx(c).Error("transaksi gagal", "error", err)
+		}
+		return c.Status(fe.Code).JSON(fiber.Map{"error": fe.Message})
+	}
+	logger.FromCtx(c).Error("transaksi gagal", "error", err)
+	return c.Status(500).JSON(fiber.Map{"error": fallback})
+}
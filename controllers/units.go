@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"strings"
+)
+
+// UnitConversion menyatakan satuan sebagai kelipatan dari satuan dasarnya,
+// mis. "100 gram" = 0.1 kg
+type UnitConversion struct {
+	BaseUnit string
+	Factor   float64
+}
+
+// Registry satuan yang dikenal, key dalam huruf kecil tanpa spasi
+var unitRegistry = map[string]UnitConversion{
+	"kg":       {BaseUnit: "kg", Factor: 1},
+	"kilo":     {BaseUnit: "kg", Factor: 1},
+	"kilogram": {BaseUnit: "kg", Factor: 1},
+	"g":        {BaseUnit: "kg", Factor: 0.001},
+	"gr":       {BaseUnit: "kg", Factor: 0.001},
+	"gram":     {BaseUnit: "kg", Factor: 0.001},
+	"100g":     {BaseUnit: "kg", Factor: 0.1},
+	"100gr":    {BaseUnit: "kg", Factor: 0.1},
+	"100gram":  {BaseUnit: "kg", Factor: 0.1},
+	"ons":      {BaseUnit: "kg", Factor: 0.1},
+	"250g":     {BaseUnit: "kg", Factor: 0.25},
+	"250gram":  {BaseUnit: "kg", Factor: 0.25},
+	"500g":     {BaseUnit: "kg", Factor: 0.5},
+	"500gram":  {BaseUnit: "kg", Factor: 0.5},
+	"l":        {BaseUnit: "liter", Factor: 1},
+	"lt":       {BaseUnit: "liter", Factor: 1},
+	"ltr":      {BaseUnit: "liter", Factor: 1},
+	"liter":    {BaseUnit: "liter", Factor: 1},
+	"ml":       {BaseUnit: "liter", Factor: 0.001},
+	"500ml":    {BaseUnit: "liter", Factor: 0.5},
+	"buah":     {BaseUnit: "buah", Factor: 1},
+	"butir":    {BaseUnit: "butir", Factor: 1},
+	"ikat":     {BaseUnit: "ikat", Factor: 1},
+	"pcs":      {BaseUnit: "buah", Factor: 1},
+}
+
+// lookupUnit mencari satuan di registry, mengabaikan huruf besar/kecil, spasi dan awalan "per"
+func lookupUnit(satuan string) (UnitConversion, bool) {
+	key := strings.ToLower(strings.Join(strings.Fields(satuan), ""))
+	key = strings.TrimPrefix(key, "per")
+	key = strings.TrimPrefix(key, "/")
+	conversion, ok := unitRegistry[key]
+	return conversion, ok
+}
+
+// normalizeUnitPrice mengubah harga per satuan menjadi harga per satuan dasar
+func normalizeUnitPrice(price float64, satuan string) (float64, string, bool) {
+	conversion, ok := lookupUnit(satuan)
+	if !ok || conversion.Factor <= 0 {
+		return 0, "", false
+	}
+	return price / conversion.Factor, conversion.BaseUnit, true
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"backend/logger"
+	"backend/storage"
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// MaxImageUploadSize adalah ukuran maksimal gambar yang boleh diunggah. Dibuat di bawah BodyLimit
+// default fiber (4MB) agar gambar beserta overhead multipart tetap muat tanpa menaikkan batas body
+// untuk semua route.
+const MaxImageUploadSize = 3 << 20
+
+var imageTooLargeMessage = fmt.Sprintf("Ukuran gambar maksimal %dMB", MaxImageUploadSize>>20)
+
+// Tipe gambar yang diterima beserta ekstensi dan fungsi pembersih metadatanya
+var imageUploadTypes = map[string]struct {
+	ext   string
+	strip func([]byte) ([]byte, error)
+}{
+	"image/jpeg": {".jpg", stripJPEGMetadata},
+	"image/png":  {".png", stripPNGMetadata},
+	"image/webp": {".webp", stripWebPMetadata},
+}
+
+// UploadImage menerima gambar (jpeg/png/webp, maks 3MB) pada field multipart 'file' untuk
+// image_url pasar atau petugas. Tipe ditentukan dari isi berkas, bukan ekstensi atau header,
+// dan metadata EXIF dibuang sebelum disimpan. Mengembalikan URL publik gambar.
+func UploadImage(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Gambar wajib diunggah pada field 'file'"})
+	}
+	if fileHeader.Size > MaxImageUploadSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": imageTooLargeMessage})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Gagal membuka file"})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxImageUploadSize+1))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Gagal membaca file"})
+	}
+	if len(data) > MaxImageUploadSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": imageTooLargeMessage})
+	}
+
+	contentType := http.DetectContentType(data)
+	imageType, ok := imageUploadTypes[contentType]
+	if !ok {
+		return c.Status(415).JSON(fiber.Map{"error": "File harus berupa gambar JPEG, PNG, atau WebP"})
+	}
+	// JPEG dan PNG harus benar-benar bisa dibaca, bukan sekadar diawali magic bytes
+	if contentType != "image/webp" {
+		if _, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil {
+			return c.Status(415).JSON(fiber.Map{"error": "File gambar rusak atau tidak valid"})
+		}
+	}
+
+	cleaned, err := imageType.strip(data)
+	if err != nil {
+		return c.Status(415).JSON(fiber.Map{"error": "File gambar rusak atau tidak valid"})
+	}
+
+	url, err := storage.Default.Save(c.Context(), uuid.NewString()+imageType.ext, contentType, bytes.NewReader(cleaned))
+	if err != nil {
+		logger.FromCtx(c).Error("gagal menyimpan gambar", "error", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan gambar"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"url":          url,
+		"content_type": contentType,
+		"size":         len(cleaned),
+	})
+}
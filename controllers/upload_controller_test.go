@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"backend/storage"
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newUploadApp memasang UploadImage dengan storage lokal di direktori sementara
+func newUploadApp(t *testing.T) *fiber.App {
+	t.Helper()
+	previous := storage.Default
+	storage.Default = &storage.LocalStorage{Dir: t.TempDir(), BaseURL: "/uploads"}
+	t.Cleanup(func() { storage.Default = previous })
+
+	app := fiber.New()
+	app.Post("/api/uploads/image", UploadImage)
+	return app
+}
+
+func postImage(t *testing.T, app *fiber.App, filename string, content []byte) (int, map[string]any) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/uploads/image", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]any
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestUploadImageAcceptsPNG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	app := newUploadApp(t)
+	status, result := postImage(t, app, "pasar.png", buf.Bytes())
+	if status != fiber.StatusCreated {
+		t.Fatalf("status = %d, want 201 (%v)", status, result)
+	}
+	if result["content_type"] != "image/png" {
+		t.Errorf("content_type = %v, want image/png", result["content_type"])
+	}
+	url, _ := result["url"].(string)
+	if !strings.HasPrefix(url, "/uploads/") || !strings.HasSuffix(url, ".png") {
+		t.Errorf("url = %q, want /uploads/<uuid>.png", url)
+	}
+
+	local := storage.Default.(*storage.LocalStorage)
+	if _, err := os.Stat(filepath.Join(local.Dir, filepath.Base(url))); err != nil {
+		t.Errorf("berkas tidak tersimpan: %v", err)
+	}
+}
+
+func TestUploadImageRejectsDisguisedText(t *testing.T) {
+	content := []byte("ini bukan gambar, hanya teks biasa")
+	app := newUploadApp(t)
+
+	status, result := postImage(t, app, "palsu.png", content)
+	if status != fiber.StatusUnsupportedMediaType {
+		t.Fatalf("status = %d, want 415 (%v)", status, result)
+	}
+
+	entries, _ := os.ReadDir(storage.Default.(*storage.LocalStorage).Dir)
+	if len(entries) != 0 {
+		t.Errorf("berkas palsu tidak boleh tersimpan, ada %d berkas", len(entries))
+	}
+}
+
+func TestUploadImageRejectsOversizedFile(t *testing.T) {
+	content := bytes.Repeat([]byte{0}, MaxImageUploadSize+1)
+	app := newUploadApp(t)
+
+	status, _ := postImage(t, app, "besar.png", content)
+	if status != fiber.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", status)
+	}
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"backend/logger"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// validateInput menjalankan utils.Validate pada input setelah BodyParser. Bila ok bernilai false,
+// response 400 berisi detail per field sudah ditulis dan err harus langsung dikembalikan oleh handler.
+func validateInput(c *fiber.Ctx, input any) (ok bool, err error) {
+	verr := utils.Validate(input)
+	if verr == nil {
+		return true, nil
+	}
+
+	var fields utils.ValidationErrors
+	if errors.As(verr, &fields) {
+		return false, c.Status(400).JSON(fiber.Map{"error": "Data tidak valid", "fields": fields})
+	}
+	logger.FromCtx(c).Error("aturan validasi tidak valid", "error", verr)
+	return false, c.Status(500).JSON(fiber.Map{"error": "Gagal memvalidasi data"})
+}
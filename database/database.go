@@ -3,8 +3,12 @@ package database
 import (
 	"backend/models"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
@@ -12,21 +16,121 @@ import (
 // DB adalah instance global untuk database
 var DB *gorm.DB
 
-// Fungsi untuk menghubungkan ke database
-func ConnectDatabase() {
-	var err error
-	dsn := "root:@tcp(127.0.0.1:3306)/adminretribusi?charset=utf8mb4&parseTime=True&loc=Local"
+const defaultDBParams = "charset=utf8mb4&parseTime=True&loc=Local"
+
+// Default koneksi lokal, hanya dipakai bila APP_ENV=development
+var developmentDBDefaults = map[string]string{
+	"DB_HOST": "127.0.0.1",
+	"DB_PORT": "3306",
+	"DB_USER": "root",
+	"DB_NAME": "adminretribusi",
+}
+
+// BuildDSN menyusun DSN MySQL dari DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME dan DB_PARAMS.
+// DB_PORT default 3306, DB_PASSWORD boleh kosong. Variabel wajib yang kosong menghasilkan error,
+// kecuali APP_ENV=development yang memakai default lokal.
+func BuildDSN(getenv func(string) string) (string, error) {
+	development := getenv("APP_ENV") == "development"
+	value := func(key string) string {
+		if v := strings.TrimSpace(getenv(key)); v != "" {
+			return v
+		}
+		if development {
+			return developmentDBDefaults[key]
+		}
+		return ""
+	}
+
+	host, port, user, name := value("DB_HOST"), value("DB_PORT"), value("DB_USER"), value("DB_NAME")
+	if port == "" {
+		port = "3306"
+	}
+
+	var missing []string
+	for key, v := range map[string]string{"DB_HOST": host, "DB_USER": user, "DB_NAME": name} {
+		if v == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("konfigurasi database belum lengkap, isi %s", strings.Join(missing, ", "))
+	}
+
+	params := getenv("DB_PARAMS")
+	if params == "" {
+		params = defaultDBParams
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?%s", user, getenv("DB_PASSWORD"), host, port, name, params)
+	if _, err := mysqldriver.ParseDSN(dsn); err != nil {
+		return "", fmt.Errorf("DSN database tidak valid: %w", err)
+	}
+	return dsn, nil
+}
+
+// ConnectDatabase membuka koneksi ke database dan menjalankan migrasi
+func ConnectDatabase() error {
+	dsn, err := BuildDSN(os.Getenv)
+	if err != nil {
+		return err
+	}
+	poolConfig, err := PoolConfigFromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
 	}
+	poolConfig.Apply(sqlDB)
 
-	fmt.Println("✅ Database connected successfully!")
+	slog.Info("database terhubung",
+		"max_open", poolConfig.MaxOpenConns, "max_idle", poolConfig.MaxIdleConns, "conn_lifetime", poolConfig.ConnMaxLifetime.String())
 
 	// Migrasi model ke dalam database
-	err = DB.AutoMigrate(&models.Price{}, &models.Market{}, &models.User{}, &models.Barang{}, &models.BarangHistory{}, &models.PriceHistory{}, &models.Category{}, &models.MarketOfficer{}, &models.CategoryMarket{})
+	if err := Migrate(DB); err != nil {
+		return err
+	}
+
+	slog.Info("migrasi database selesai")
+	return nil
+}
+
+// Migrate membuat dan memperbarui semua tabel lalu menjalankan backfill data lama. Aman dipanggil
+// berulang kali, dipakai ConnectDatabase dan test yang berjalan di atas sqlite.
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(&models.Price{}, &models.Market{}, &models.User{}, &models.Barang{}, &models.BarangHistory{}, &models.Category{}, &models.CategoryMarket{}, &models.PriceDispute{}, &models.OfficerSession{}, &models.PriceConfirmation{}, &models.RefreshToken{}, &models.RevokedToken{}, &models.SyncState{}, &models.Admin{}, &models.IdempotencyKey{})
 	if err != nil {
-		log.Fatalf("❌ Failed to migrate the database: %v\n", err)
+		return fmt.Errorf("failed to migrate the database: %w", err)
+	}
+	// Tabel petugas tidak pernah dihapus kecuali FORCE_OFFICER_RESET=true
+	if err := models.MigrateMarketOfficer(db); err != nil {
+		return err
+	}
+	if err := models.MigratePriceHistory(db); err != nil {
+		return err
+	}
+	if err := models.BackfillNameKeys(db); err != nil {
+		return err
+	}
+	if err := models.MigratePriceUniqueIndex(db); err != nil {
+		return err
+	}
+	if err := models.MigrateCategoryMarketUniqueIndex(db); err != nil {
+		return err
+	}
+	// Petugas lama yang belum punya role dianggap petugas lapangan
+	if err := db.Model(&models.MarketOfficer{}).
+		Where("role IS NULL OR role = ''").
+		Update("role", models.OfficerRoleOfficer).Error; err != nil {
+		return fmt.Errorf("failed to backfill officer roles: %w", err)
 	}
-	fmt.Println("✅ Database migrated successfully!")
+	return nil
 }
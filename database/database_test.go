@@ -0,0 +1,73 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func envMap(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestBuildDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "semua env terisi",
+			env: map[string]string{
+				"DB_HOST": "db.internal", "DB_PORT": "3307", "DB_USER": "app",
+				"DB_PASSWORD": "rahasia", "DB_NAME": "retribusi",
+			},
+			want: "app:rahasia@tcp(db.internal:3307)/retribusi?" + defaultDBParams,
+		},
+		{
+			name: "port default dan params kustom",
+			env: map[string]string{
+				"DB_HOST": "db.internal", "DB_USER": "app", "DB_NAME": "retribusi",
+				"DB_PARAMS": "parseTime=True",
+			},
+			want: "app:@tcp(db.internal:3306)/retribusi?parseTime=True",
+		},
+		{
+			name: "development memakai default lokal",
+			env:  map[string]string{"APP_ENV": "development"},
+			want: "root:@tcp(127.0.0.1:3306)/adminretribusi?" + defaultDBParams,
+		},
+		{
+			name:    "production tanpa env ditolak",
+			env:     map[string]string{"APP_ENV": "production"},
+			wantErr: "isi DB_HOST, DB_NAME, DB_USER",
+		},
+		{
+			name:    "nilai berisi spasi saja dianggap kosong",
+			env:     map[string]string{"DB_HOST": "  ", "DB_USER": "app", "DB_NAME": "retribusi"},
+			wantErr: "isi DB_HOST",
+		},
+		{
+			name:    "params tidak valid",
+			env:     map[string]string{"DB_HOST": "db", "DB_USER": "app", "DB_NAME": "retribusi", "DB_PARAMS": "loc=Bukan/Zona"},
+			wantErr: "DSN database tidak valid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildDSN(envMap(tt.env))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want berisi %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("BuildDSN = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Package dbtest menyiapkan database sqlite sementara untuk test yang butuh query sungguhan.
+// Paket ini tidak mengimpor backend/database maupun backend/models agar bisa dipakai test di
+// paket mana pun, migrasi dijalankan oleh pemanggil.
+package dbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open membuat database sqlite baru di direktori sementara milik test dan menutupnya saat test
+// selesai. Test dilewati bila driver sqlite tidak bisa dipakai (mis. build dengan CGO_ENABLED=0).
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Skipf("sqlite tidak tersedia: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		t.Skipf("sqlite tidak tersedia: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
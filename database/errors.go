@@ -0,0 +1,21 @@
+package database
+
+import (
+	"errors"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// Kode error MySQL untuk pelanggaran unique index
+const mysqlErrDuplicateEntry = 1062
+
+// IsDuplicateKeyError bernilai true bila err berasal dari pelanggaran unique index, baik error
+// MySQL asli maupun gorm.ErrDuplicatedKey dari koneksi dengan TranslateError (dipakai test sqlite)
+func IsDuplicateKeyError(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var mysqlErr *mysqldriver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"backend/database/dbtest"
+	"backend/models"
+	"testing"
+)
+
+// Migrate dijalankan di setiap startup, data yang sudah ada harus tetap utuh
+func TestMigrateKeepsDataAcrossRestarts(t *testing.T) {
+	db := dbtest.Open(t)
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	market := models.Market{Name: "Pasar Baru", Location: "Kota"}
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatal(err)
+	}
+	officer := models.MarketOfficer{Name: "Budi", Nik: "3201010101010001", Username: "budi", MarketID: uint64(market.ID), IsActive: true}
+	if err := db.Create(&officer).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.PriceHistory{ItemID: 1, ItemName: "Beras", CurrentPrice: 12000, MarketID: market.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrasi kedua: %v", err)
+	}
+
+	var stored models.MarketOfficer
+	if err := db.First(&stored, officer.ID).Error; err != nil {
+		t.Fatalf("petugas hilang setelah restart: %v", err)
+	}
+	if stored.Username != "budi" || stored.Role != models.OfficerRoleOfficer {
+		t.Errorf("petugas = %+v, want username budi dengan role officer", stored)
+	}
+	var histories int64
+	db.Model(&models.PriceHistory{}).Count(&histories)
+	if histories != 1 {
+		t.Errorf("histori harga = %d setelah restart, want 1", histories)
+	}
+}
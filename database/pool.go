@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Default pool koneksi, bisa diubah lewat env:
+//   - DB_MAX_OPEN: jumlah koneksi terbuka maksimum (default 25)
+//   - DB_MAX_IDLE: jumlah koneksi idle maksimum (default 10)
+//   - DB_CONN_LIFETIME: umur maksimum satu koneksi, durasi Go seperti "5m" (default 5m)
+const (
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 5 * time.Minute
+)
+
+// PoolConfig adalah pengaturan pool koneksi *sql.DB
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// PoolConfigFromEnv membaca pengaturan pool dari env dengan nilai default di atas
+func PoolConfigFromEnv(getenv func(string) string) (PoolConfig, error) {
+	config := PoolConfig{
+		MaxOpenConns:    defaultMaxOpenConns,
+		MaxIdleConns:    defaultMaxIdleConns,
+		ConnMaxLifetime: defaultConnMaxLifetime,
+	}
+
+	if value := getenv("DB_MAX_OPEN"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return config, fmt.Errorf("DB_MAX_OPEN tidak valid: %q", value)
+		}
+		config.MaxOpenConns = n
+	}
+	if value := getenv("DB_MAX_IDLE"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return config, fmt.Errorf("DB_MAX_IDLE tidak valid: %q", value)
+		}
+		config.MaxIdleConns = n
+	}
+	if value := getenv("DB_CONN_LIFETIME"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return config, fmt.Errorf("DB_CONN_LIFETIME tidak valid: %q", value)
+		}
+		config.ConnMaxLifetime = d
+	}
+
+	// Koneksi idle tidak boleh melebihi batas koneksi terbuka
+	if config.MaxIdleConns > config.MaxOpenConns {
+		config.MaxIdleConns = config.MaxOpenConns
+	}
+	return config, nil
+}
+
+// Apply menerapkan pengaturan pool ke *sql.DB
+func (p PoolConfig) Apply(sqlDB *sql.DB) {
+	sqlDB.SetMaxOpenConns(p.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(p.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(p.ConnMaxLifetime)
+}
+
+// Ping memeriksa apakah database masih bisa dijangkau, dipakai oleh endpoint health check
+func Ping(ctx context.Context) error {
+	sqlDB, err := sqlHandle()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats mengembalikan statistik pool koneksi DB (koneksi terbuka, terpakai, idle, dan batasnya)
+func Stats() (sql.DBStats, error) {
+	sqlDB, err := sqlHandle()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDB.Stats(), nil
+}
+
+func sqlHandle() (*sql.DB, error) {
+	if DB == nil {
+		return nil, errors.New("database belum diinisialisasi")
+	}
+	return DB.DB()
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"backend/database/dbtest"
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	defaults := PoolConfig{MaxOpenConns: 25, MaxIdleConns: 10, ConnMaxLifetime: 5 * time.Minute}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    PoolConfig
+		wantErr bool
+	}{
+		{"tanpa env memakai default", nil, defaults, false},
+		{"semua env terisi", map[string]string{"DB_MAX_OPEN": "50", "DB_MAX_IDLE": "20", "DB_CONN_LIFETIME": "90s"},
+			PoolConfig{MaxOpenConns: 50, MaxIdleConns: 20, ConnMaxLifetime: 90 * time.Second}, false},
+		{"idle dibatasi max open", map[string]string{"DB_MAX_OPEN": "4"},
+			PoolConfig{MaxOpenConns: 4, MaxIdleConns: 4, ConnMaxLifetime: 5 * time.Minute}, false},
+		{"idle nol diizinkan", map[string]string{"DB_MAX_IDLE": "0"},
+			PoolConfig{MaxOpenConns: 25, MaxIdleConns: 0, ConnMaxLifetime: 5 * time.Minute}, false},
+		{"max open nol ditolak", map[string]string{"DB_MAX_OPEN": "0"}, PoolConfig{}, true},
+		{"max open bukan angka", map[string]string{"DB_MAX_OPEN": "banyak"}, PoolConfig{}, true},
+		{"idle negatif ditolak", map[string]string{"DB_MAX_IDLE": "-1"}, PoolConfig{}, true},
+		{"lifetime tanpa satuan ditolak", map[string]string{"DB_CONN_LIFETIME": "300"}, PoolConfig{}, true},
+		{"lifetime negatif ditolak", map[string]string{"DB_CONN_LIFETIME": "-1m"}, PoolConfig{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PoolConfigFromEnv(envMap(tt.env))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("err = nil, want error (config %+v)", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("PoolConfigFromEnv = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolConfigAppliedToStats(t *testing.T) {
+	t.Setenv("DB_MAX_OPEN", "4")
+	t.Setenv("DB_MAX_IDLE", "2")
+	t.Setenv("DB_CONN_LIFETIME", "1m")
+
+	config, err := PoolConfigFromEnv(os.Getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := dbtest.Open(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Apply(sqlDB)
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() { DB = previous })
+
+	// Buka koneksi sebanyak batas lalu kembalikan semuanya ke pool
+	ctx := context.Background()
+	conns := make([]*sql.Conn, 0, 4)
+	for i := 0; i < 4; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+
+	stats, err := Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.MaxOpenConnections != 4 {
+		t.Errorf("MaxOpenConnections = %d, want 4", stats.MaxOpenConnections)
+	}
+	// Hanya DB_MAX_IDLE koneksi yang disimpan, sisanya ditutup
+	if stats.Idle != 2 || stats.MaxIdleClosed != 2 {
+		t.Errorf("Idle = %d, MaxIdleClosed = %d, want 2 dan 2", stats.Idle, stats.MaxIdleClosed)
+	}
+}
+
+func TestStatsWithoutDatabase(t *testing.T) {
+	previous := DB
+	DB = nil
+	t.Cleanup(func() { DB = previous })
+
+	if _, err := Stats(); err == nil {
+		t.Error("Stats tanpa database harus error")
+	}
+}
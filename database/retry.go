@@ -0,0 +1,51 @@
+package database
+
+import (
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+// Kode error MySQL yang aman untuk diulang karena transaksinya sudah dibatalkan server
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+)
+
+// Jeda dasar sebelum percobaan ulang, berlipat dua tiap percobaan dan diberi jitter
+var retryBaseDelay = 50 * time.Millisecond
+
+// WithRetry menjalankan fn sampai maxAttempts kali selama fn gagal karena deadlock (1213)
+// atau lock wait timeout (1205). Error lain dikembalikan langsung. fn harus menjalankan
+// transaksi utuh (mis. lewat WithTransaction) agar aman diulang dari awal.
+func WithRetry(maxAttempts int, fn func() error) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !IsRetryableError(err) || attempt == maxAttempts {
+			return err
+		}
+
+		delay := retryBaseDelay << (attempt - 1)
+		delay = delay/2 + rand.N(delay/2+1)
+		slog.Warn("transaksi gagal karena lock, diulang", "attempt", attempt, "max_attempts", maxAttempts, "delay", delay.String(), "error", err)
+		time.Sleep(delay)
+	}
+	return err
+}
+
+// IsRetryableError bernilai true untuk error deadlock dan lock wait timeout MySQL
+func IsRetryableError(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestWithRetry(t *testing.T) {
+	previous := retryBaseDelay
+	retryBaseDelay = 0
+	t.Cleanup(func() { retryBaseDelay = previous })
+
+	deadlock := &mysqldriver.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	lockWait := &mysqldriver.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}
+	duplicate := &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"}
+
+	tests := []struct {
+		name         string
+		failures     []error
+		maxAttempts  int
+		wantAttempts int
+		wantErr      error
+	}{
+		{"berhasil langsung", nil, 3, 1, nil},
+		{"deadlock lalu berhasil", []error{deadlock}, 3, 2, nil},
+		{"lock wait timeout lalu berhasil", []error{lockWait, lockWait}, 3, 3, nil},
+		{"deadlock terbungkus tetap diulang", []error{fmt.Errorf("simpan harga: %w", deadlock)}, 3, 2, nil},
+		{"deadlock terus sampai batas", []error{deadlock, deadlock, deadlock, deadlock}, 3, 3, deadlock},
+		{"error lain tidak diulang", []error{duplicate}, 3, 1, duplicate},
+		{"maxAttempts kurang dari satu tetap dijalankan sekali", []error{deadlock}, 0, 1, deadlock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := WithRetry(tt.maxAttempts, func() error {
+				attempts++
+				if attempts <= len(tt.failures) {
+					return tt.failures[attempts-1]
+				}
+				return nil
+			})
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// WithTransaction menjalankan fn di dalam satu transaksi: commit bila fn mengembalikan nil,
+// rollback bila fn mengembalikan error atau panic. Panic diubah menjadi error sehingga
+// pemanggil tetap bisa membalas 500 tanpa meninggalkan transaksi terbuka.
+func WithTransaction(fn func(tx *gorm.DB) error) (err error) {
+	tx := DB.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			err = fmt.Errorf("transaksi dibatalkan karena panic: %v", r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// txRecorder adalah driver database/sql palsu yang hanya mencatat begin, commit dan rollback,
+// cukup untuk menguji WithTransaction tanpa server MySQL
+type txRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *txRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *txRecorder) Events() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.events, ",")
+}
+
+func (r *txRecorder) Open(string) (driver.Conn, error) { return &recorderConn{r}, nil }
+
+// Connect dan Driver membuat txRecorder sekaligus driver.Connector untuk sql.OpenDB
+func (r *txRecorder) Connect(context.Context) (driver.Conn, error) { return &recorderConn{r}, nil }
+func (r *txRecorder) Driver() driver.Driver                        { return r }
+
+type recorderConn struct{ r *txRecorder }
+
+func (c *recorderConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("query tidak didukung driver palsu")
+}
+func (c *recorderConn) Close() error { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error) {
+	c.r.record("begin")
+	return &recorderTx{c.r}, nil
+}
+
+type recorderTx struct{ r *txRecorder }
+
+func (t *recorderTx) Commit() error   { t.r.record("commit"); return nil }
+func (t *recorderTx) Rollback() error { t.r.record("rollback"); return nil }
+
+// useRecorderDB mengganti DB dengan gorm di atas driver palsu selama test berjalan
+func useRecorderDB(t *testing.T) *txRecorder {
+	t.Helper()
+	recorder := &txRecorder{}
+	sqlDB := sql.OpenDB(recorder)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() {
+		DB = previous
+		sqlDB.Close()
+	})
+	return recorder
+}
+
+func TestWithTransactionCommitsOnSuccess(t *testing.T) {
+	recorder := useRecorderDB(t)
+
+	called := false
+	err := WithTransaction(func(tx *gorm.DB) error {
+		called = true
+		return nil
+	})
+	if err != nil || !called {
+		t.Fatalf("err = %v, called = %v", err, called)
+	}
+	if got := recorder.Events(); got != "begin,commit" {
+		t.Errorf("events = %q, want begin,commit", got)
+	}
+}
+
+func TestWithTransactionRollsBackOnError(t *testing.T) {
+	recorder := useRecorderDB(t)
+
+	errFailed := errors.New("gagal simpan harga")
+	err := WithTransaction(func(tx *gorm.DB) error {
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("err = %v, want %v", err, errFailed)
+	}
+	if got := recorder.Events(); got != "begin,rollback" {
+		t.Errorf("events = %q, want begin,rollback", got)
+	}
+}
+
+func TestWithTransactionRollsBackOnPanic(t *testing.T) {
+	recorder := useRecorderDB(t)
+
+	err := WithTransaction(func(tx *gorm.DB) error {
+		var price *struct{ ID uint }
+		_ = price.ID // nil pointer dereference
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "panic") {
+		t.Fatalf("err = %v, want error panic", err)
+	}
+	if got := recorder.Events(); got != "begin,rollback" {
+		t.Errorf("events = %q, want begin,rollback", got)
+	}
+}
@@ -0,0 +1,16 @@
+// Package docs menyimpan spesifikasi OpenAPI 3 untuk API ini beserta halaman Swagger UI.
+// openapi.json ditulis manual dan wajib diperbarui bersama perubahan endpoint, agar client
+// mobile dan web bisa membuat kode dari kontrak yang sama.
+package docs
+
+import _ "embed"
+
+// OpenAPI berisi spesifikasi yang disajikan di GET /swagger/doc.json
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// SwaggerUI adalah halaman Swagger UI yang membaca /swagger/doc.json
+//
+//go:embed index.html
+var SwaggerUI []byte
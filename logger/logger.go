@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Init memasang logger JSON sebagai slog default dengan level dari env LOG_LEVEL
+// (debug, info, warn, error; default info).
+func Init() {
+	slog.SetDefault(New(os.Stdout, ParseLevel(os.Getenv("LOG_LEVEL"))))
+}
+
+// New membuat logger dengan output JSON satu baris per entri
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// ParseLevel mengubah nama level menjadi slog.Level, nilai tidak dikenal dianggap info
+func ParseLevel(value string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// FromCtx mengembalikan logger default dengan field request_id dan officer_id dari request
+// (bila ada), agar log bisa dikorelasikan dengan response error dan petugas yang memanggil
+func FromCtx(c *fiber.Ctx) *slog.Logger {
+	l := slog.Default().With("method", c.Method(), "path", c.Path())
+	if requestID, ok := c.Locals("request_id").(string); ok && requestID != "" {
+		l = l.With("request_id", requestID)
+	}
+	if officerID, ok := c.Locals("officer_id").(uint64); ok && officerID != 0 {
+		l = l.With("officer_id", officerID)
+	}
+	return l
+}
+
+// Fatal mencatat error lalu menghentikan aplikasi, pengganti log.Fatal
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -1,107 +1,49 @@
 package main
 
 import (
+	"backend/auth"
+	"backend/controllers"
 	"backend/database"
+	"backend/logger"
+	"backend/middleware"
 	"backend/models"
 	"backend/routes"
-	"fmt"
-	"log"
+	"context"
+	"log/slog"
 	"os"
 	"time"
+	_ "time/tzdata" // Data zona waktu untuk APP_TIMEZONE, juga di image tanpa /usr/share/zoneinfo
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtKey []byte
+// Versi aplikasi bisa diisi saat build (-ldflags "-X main.version=...") atau lewat APP_VERSION
+var (
+	version   = "dev"
+	startedAt = time.Now()
+)
 
-func getJWTSecret() string {
-	if os.Getenv("JWT_SECRET") != "" {
-		return os.Getenv("JWT_SECRET")
+func getAppVersion() string {
+	if os.Getenv("APP_VERSION") != "" {
+		return os.Getenv("APP_VERSION")
 	}
-	return "default-secret"
-}
-
-func init() {
-	jwtKey = []byte(getJWTSecret())
-}
-
-type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-}
-
-type LoginResponse struct {
-	Success bool                  `json:"success"`
-	Message string                `json:"message"`
-	Data    *LoginResponsePayload `json:"data,omitempty"`
-}
-
-type LoginResponsePayload struct {
-	Officer *models.MarketOfficer `json:"officer"`
-	Token   string                `json:"token"`
+	return version
 }
 
-func loginHandlermobile(c *fiber.Ctx) error {
-	var creds LoginRequest
-	if err := c.BodyParser(&creds); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(LoginResponse{
-			Success: false,
-			Message: "Invalid request format",
-		})
+// checkJWTSecret menghentikan startup di production bila JWT_SECRET tidak aman,
+// di environment lain hanya mencatat peringatan
+func checkJWTSecret() {
+	err := auth.ValidateSecret(os.Getenv("JWT_SECRET"))
+	if err == nil {
+		return
 	}
-
-	var officer models.MarketOfficer
-	result := database.DB.Preload("Market").Where("username = ?", creds.Username).First(&officer)
-	if result.Error != nil {
-		log.Println("❌ Officer not found:", creds.Username)
-		return c.Status(fiber.StatusUnauthorized).JSON(LoginResponse{
-			Success: false,
-			Message: "Username atau password salah",
-		})
+	if os.Getenv("APP_ENV") == "production" {
+		logger.Fatal("JWT_SECRET tidak aman, server tidak dijalankan", "error", err)
 	}
-
-	if !officer.IsActive {
-		return c.Status(fiber.StatusUnauthorized).JSON(LoginResponse{
-			Success: false,
-			Message: "Akun tidak aktif. Hubungi admin",
-		})
-	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(officer.Password), []byte(creds.Password)); err != nil {
-		log.Println("❌ Invalid password for officer:", creds.Username)
-		return c.Status(fiber.StatusUnauthorized).JSON(LoginResponse{
-			Success: false,
-			Message: "Username atau password salah",
-		})
-	}
-
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := jwt.MapClaims{
-		"username": officer.Username,
-		"exp":      expirationTime.Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
-	if err != nil {
-		log.Printf("❌ Error generating JWT token: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(LoginResponse{
-			Success: false,
-			Message: "Gagal membuat token login",
-		})
-	}
-
-	return c.JSON(LoginResponse{
-		Success: true,
-		Message: "Login berhasil",
-		Data: &LoginResponsePayload{
-			Officer: &officer,
-			Token:   tokenString,
-		},
-	})
+	slog.Warn("JWT_SECRET TIDAK AMAN: token bisa dipalsukan, jangan jalankan konfigurasi ini di production", "error", err)
 }
 
 type Credentials struct {
@@ -116,35 +58,69 @@ type Claims struct {
 
 // 🔧 Fungsi untuk inisialisasi database
 func initDatabase() {
-	database.ConnectDatabase()
+	if err := database.ConnectDatabase(); err != nil {
+		logger.Fatal("gagal menyiapkan database", "error", err)
+	}
 
 	if database.DB == nil {
-		log.Fatalf("❌ Koneksi database nil! Pastikan database berjalan.")
-		os.Exit(1)
+		logger.Fatal("koneksi database nil, pastikan database berjalan")
+	}
+
+	slog.Info("database siap digunakan")
+}
+
+// healthHandler dipakai orkestrator (Railway/Kubernetes) sebagai readiness probe:
+// 200 bila database bisa di-ping, 503 bila tidak. db_pool berisi statistik pool koneksi.
+func healthHandler(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 2*time.Second)
+	defer cancel()
+
+	status, db, code := "ok", "up", fiber.StatusOK
+	if err := database.Ping(ctx); err != nil {
+		logger.FromCtx(c).Error("health check database gagal", "error", err)
+		status, db, code = "unavailable", "down", fiber.StatusServiceUnavailable
 	}
 
-	fmt.Println("✅ Database sudah siap digunakan!")
+	uptime := time.Since(startedAt)
+	body := fiber.Map{
+		"status":         status,
+		"db":             db,
+		"version":        getAppVersion(),
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+	}
+	// Statistik pool untuk memantau koneksi yang habis terpakai
+	if stats, err := database.Stats(); err == nil {
+		body["db_pool"] = fiber.Map{
+			"max_open":   stats.MaxOpenConnections,
+			"open":       stats.OpenConnections,
+			"in_use":     stats.InUse,
+			"idle":       stats.Idle,
+			"wait_count": stats.WaitCount,
+		}
+	}
+	return c.Status(code).JSON(body)
 }
 
-// 🔐 Fungsi untuk menangani login dengan hashing password
+// 🔐 Login dashboard lama (POST /api/login) untuk akun models.User, login petugas ada di controllers.Login
 func loginHandler(c *fiber.Ctx) error {
 	var creds Credentials
 	if err := c.BodyParser(&creds); err != nil {
-		log.Println("❌ Error parsing request body:", err)
+		logger.FromCtx(c).Warn("body login tidak valid", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request format"})
 	}
 
 	var user models.User
 	result := database.DB.Where("username = ?", creds.Username).First(&user)
 	if result.Error != nil {
-		log.Println("❌ User not found:", creds.Username)
+		logger.FromCtx(c).Warn("login gagal: user tidak ditemukan", "username", creds.Username)
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid username or password"})
 	}
 
 	// Validasi password dengan bcrypt
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(creds.Password))
 	if err != nil {
-		log.Println("❌ Invalid password for user:", creds.Username)
+		logger.FromCtx(c).Warn("login gagal: password salah", "username", creds.Username)
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid username or password"})
 	}
 
@@ -157,10 +133,9 @@ func loginHandler(c *fiber.Ctx) error {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
+	tokenString, err := auth.SignToken(claims)
 	if err != nil {
-		log.Println("❌ Error generating JWT token:", err)
+		logger.FromCtx(c).Error("gagal membuat token login", "username", creds.Username, "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not generate token"})
 	}
 
@@ -169,42 +144,114 @@ func loginHandler(c *fiber.Ctx) error {
 }
 
 func main() {
+	// Log JSON terstruktur, level diatur lewat LOG_LEVEL
+	logger.Init()
+
+	// Tolak secret JWT kosong atau default sebelum server menerima request
+	checkJWTSecret()
+
+	// Origin CORS dari CORS_ALLOWED_ORIGINS, konfigurasi yang salah menghentikan startup
+	corsConfig, err := middleware.CORSConfigFromEnv(os.Getenv)
+	if err != nil {
+		logger.Fatal("konfigurasi CORS tidak valid", "error", err)
+	}
+	if corsConfig.AllowOriginsFunc != nil {
+		slog.Warn("CORS_ALLOWED_ORIGINS kosong, semua request cross-origin ditolak")
+	}
+
+	// IP client di belakang reverse proxy (TRUSTED_PROXIES, PROXY_HEADER), dipakai rate limit login
+	proxyConfig, err := middleware.ProxyConfigFromEnv(os.Getenv)
+	if err != nil {
+		logger.Fatal("konfigurasi proxy tidak valid", "error", err)
+	}
+
+	// Zona waktu pasar (APP_TIMEZONE) dan jam reset harga harian (PRICE_RESET_HOUR)
+	if err := controllers.ConfigureClock(os.Getenv); err != nil {
+		logger.Fatal("konfigurasi waktu tidak valid", "error", err)
+	}
+
 	// Inisialisasi database
 	initDatabase()
 
+	// Muat daftar token yang dicabut dan bersihkan entri kedaluwarsa setiap jam
+	if err := middleware.LoadRevokedTokens(); err != nil {
+		slog.Error("gagal memuat token yang dicabut", "error", err)
+	}
+	go middleware.StartRevokedTokenCleanup(time.Hour)
+	// Idempotency-Key berlaku 24 jam, entri kedaluwarsa dibersihkan setiap jam
+	go middleware.StartIdempotencyKeyCleanup(time.Hour)
+
 	// Inisialisasi Fiber
-	app := fiber.New()
+	fiberConfig := fiber.Config{
+		ErrorHandler: middleware.ErrorHandler,
+	}
+	proxyConfig.Apply(&fiberConfig)
+	app := fiber.New(fiberConfig)
 
 	// 🛡 Middleware CORS & Logger
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:8000,http://yourdomain.com", // Bisa disesuaikan dengan domain tertentu jika perlu
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
-		AllowHeaders: "Content-Type, Authorization",
+	app.Use(cors.New(corsConfig))
+
+	// Semua response JSON dikirim dengan charset utf-8, didaftarkan paling luar agar
+	// ikut berlaku untuk error response yang ditulis oleh middleware RequestID
+	app.Use(middleware.JSONCharset)
+
+	// Request ID untuk korelasi error response dengan log server
+	app.Use(middleware.RequestID)
+
+	// Logger request dengan header Authorization dan field password disensor,
+	// body endpoint login tidak pernah dicatat
+	app.Use(middleware.RequestLogger(middleware.RequestLoggerConfig{
+		OmitBodyPrefixes: []string{"/auth", "/api/login"},
 	}))
-	app.Use(logger.New()) // Tambahkan logger untuk debugging request
+
+	// Endpoint JSON wajib mengirim Content-Type application/json, kecuali upload multipart
+	requireJSON := middleware.RequireJSON("/api/barang/import/preview", "/api/prices/import", "/api/uploads/image")
+	app.Use("/api", requireJSON)
+	app.Use("/auth", requireJSON)
 
 	// Daftarkan Routes
 	routes.RegisterPriceRoutes(app)
 	routes.RegisterMarketRoutes(app)
 	routes.RegisterCategoryRoutes(app)
 	routes.RegisterMarketOfficerRoutes(app)
+	routes.OfficerRoutes(app)
 	routes.RegisterBarangRoutes(app)
 	routes.SetupRoutes(app)
 	routes.RegisterSyncRoutes(app)
+	routes.RegisterUploadRoutes(app)
+	routes.RegisterDocsRoutes(app)
+
+	// Batasi percobaan login gagal per username + IP
+	loginLimiter := middleware.LoginRateLimiter(middleware.LoginRateLimitConfigFromEnv())
 
 	web := app.Group("/api")
-	web.Post("/login", loginHandler)
+	web.Post("/login", loginLimiter, loginHandler)
+	web.Post("/admin/login", loginLimiter, controllers.AdminLogin)
 	web.Get("/", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"message": "📡 API root aktif!"})
 	})
 
 	// Mobile routes
 	mobile := app.Group("/auth")
-	mobile.Post("/login", loginHandlermobile)
+	mobile.Post("/login", loginLimiter, controllers.Login)
+	mobile.Post("/refresh", controllers.RefreshToken)
+	mobile.Post("/logout", middleware.JWTMiddleware, controllers.Logout)
+
+	// Readiness probe
+	app.Get("/healthz", healthHandler)
 
 	// Endpoint testing
 	app.Get("/", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{"message": "🚀 Golang Backend is Running!"})
+		uptime := time.Since(startedAt)
+		return c.JSON(fiber.Map{
+			"success":        true,
+			"message":        "🚀 Golang Backend is Running!",
+			"version":        getAppVersion(),
+			"environment":    os.Getenv("APP_ENV"),
+			"started_at":     startedAt.UTC().Format(time.RFC3339),
+			"uptime":         uptime.Round(time.Second).String(),
+			"uptime_seconds": int64(uptime.Seconds()),
+		})
 	})
 
 	// Jalankan server di port 8080
@@ -212,9 +259,9 @@ func main() {
 	if port == "" {
 		port = "8081" // fallback jika tidak di Railway
 	}
-	fmt.Println("🚀 Server running on port " + port)
-	log.Fatal(app.Listen(":" + port))
-
-	fmt.Printf("🌍 Running in environment: %s\n", os.Getenv("APP_ENV"))
+	slog.Info("server berjalan", "port", port, "environment", os.Getenv("APP_ENV"), "version", getAppVersion())
+	if err := app.Listen(":" + port); err != nil {
+		logger.Fatal("server berhenti", "error", err)
+	}
 
 }
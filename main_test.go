@@ -0,0 +1,105 @@
+package main
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func TestHealthHandler(t *testing.T) {
+	up := func(t *testing.T) *gorm.DB { return dbtest.Open(t) }
+	down := func(t *testing.T) *gorm.DB {
+		db := dbtest.Open(t)
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqlDB.Close()
+		return db
+	}
+
+	tests := []struct {
+		name       string
+		db         func(t *testing.T) *gorm.DB
+		wantStatus int
+		wantBody   map[string]string
+	}{
+		{"database up", up, 200, map[string]string{"status": "ok", "db": "up"}},
+		{"database down", down, 503, map[string]string{"status": "unavailable", "db": "down"}},
+		{"database belum diinisialisasi", func(*testing.T) *gorm.DB { return nil }, 503, map[string]string{"status": "unavailable", "db": "down"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := database.DB
+			database.DB = tt.db(t)
+			t.Cleanup(func() { database.DB = previous })
+			t.Setenv("APP_VERSION", "1.2.3")
+
+			app := fiber.New()
+			app.Get("/healthz", healthHandler)
+			resp, err := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			var body map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			tt.wantBody["version"] = "1.2.3"
+			for key, want := range tt.wantBody {
+				if body[key] != want {
+					t.Errorf("%s = %v, want %s", key, body[key], want)
+				}
+			}
+			if _, ok := body["uptime_seconds"]; !ok {
+				t.Error("uptime_seconds tidak ada")
+			}
+			if _, ok := body["db_pool"]; ok != (database.DB != nil) {
+				t.Errorf("db_pool ada = %v, want %v", ok, database.DB != nil)
+			}
+		})
+	}
+}
+
+func TestHealthHandlerReportsPoolStats(t *testing.T) {
+	db := dbtest.Open(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.PoolConfig{MaxOpenConns: 6, MaxIdleConns: 2}.Apply(sqlDB)
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	app := fiber.New()
+	app.Get("/healthz", healthHandler)
+	resp, err := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		DBPool struct {
+			MaxOpen int `json:"max_open"`
+			Open    int `json:"open"`
+			InUse   int `json:"in_use"`
+		} `json:"db_pool"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	// Ping membuka satu koneksi yang kembali ke pool sebelum statistik dibaca
+	if body.DBPool.MaxOpen != 6 || body.DBPool.Open != 1 || body.DBPool.InUse != 0 {
+		t.Errorf("db_pool = %+v, want max_open 6, open 1, in_use 0", body.DBPool)
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireJSON menolak request POST/PUT/PATCH ber-body yang bukan application/json dengan 415.
+// Path pada exemptPaths (mis. endpoint upload multipart) dilewati.
+func RequireJSON(exemptPaths ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+
+		if len(c.Body()) == 0 {
+			return c.Next()
+		}
+
+		for _, path := range exemptPaths {
+			if c.Path() == path {
+				return c.Next()
+			}
+		}
+
+		contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+		if !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+			return ErrorResponse(c, fiber.StatusUnsupportedMediaType, "Content-Type harus application/json")
+		}
+
+		return c.Next()
+	}
+}
+
+// JSONCharset memastikan setiap response JSON dikirim sebagai application/json; charset=utf-8
+// agar teks berbahasa Indonesia selalu diparse sebagai UTF-8. Response dengan content type
+// lain (grafik PNG, file ekspor, upload) tidak disentuh karena sudah mengatur header sendiri.
+func JSONCharset(c *fiber.Ctx) error {
+	err := c.Next()
+
+	contentType := strings.ToLower(string(c.Response().Header.ContentType()))
+	if strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) && !strings.Contains(contentType, "charset=") {
+		c.Response().Header.SetContentType(fiber.MIMEApplicationJSONCharsetUTF8)
+	}
+
+	return err
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequireJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"JSON diterima", "POST", "/api/barang", "application/json", `{}`, 200},
+		{"JSON dengan charset diterima", "PUT", "/api/barang", "Application/JSON; charset=utf-8", `{}`, 200},
+		{"form ditolak", "POST", "/api/barang", "application/x-www-form-urlencoded", "name=Beras", 415},
+		{"text ditolak", "PATCH", "/api/barang", "text/plain", `{}`, 415},
+		{"tanpa content type ditolak", "POST", "/api/barang", "", `{}`, 415},
+		{"body kosong dilewati", "POST", "/api/barang", "", "", 200},
+		{"GET dilewati", "GET", "/api/barang", "text/plain", "x", 200},
+		{"DELETE dilewati", "DELETE", "/api/barang", "text/plain", "x", 200},
+		{"path upload dikecualikan", "POST", "/api/uploads/image", "multipart/form-data; boundary=x", "--x--", 200},
+	}
+
+	app := fiber.New()
+	app.Use(RequireJSON("/api/uploads/image"))
+	app.All("/*", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
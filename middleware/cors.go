@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// Default CORS bila CORS_ALLOWED_METHODS / CORS_ALLOWED_HEADERS tidak diisi
+const (
+	defaultCORSMethods       = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	defaultCORSHeaders       = "Content-Type, Authorization, X-Request-ID, Idempotency-Key"
+	defaultCORSExposeHeaders = "X-Request-ID, Link, X-Total-Count, X-Page-Limit, Retry-After, Content-Disposition, Idempotent-Replayed"
+)
+
+// CORSConfigFromEnv menyusun konfigurasi CORS dari env:
+//   - CORS_ALLOWED_ORIGINS: daftar origin dipisah koma (mis. "https://app.example.com,https://*.example.com"),
+//     atau "*" untuk semua origin. Bila kosong, APP_ENV=development mengizinkan semua origin
+//     dan environment lain menolak semua request cross-origin.
+//   - CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS: daftar dipisah koma, default di atas
+//
+// Origin yang formatnya tidak valid menghasilkan error agar bisa ditolak saat startup.
+func CORSConfigFromEnv(getenv func(string) string) (cors.Config, error) {
+	config := cors.Config{
+		AllowMethods:  defaultCORSMethods,
+		AllowHeaders:  defaultCORSHeaders,
+		ExposeHeaders: defaultCORSExposeHeaders,
+	}
+
+	origins, err := parseCORSOrigins(getenv("CORS_ALLOWED_ORIGINS"))
+	if err != nil {
+		return config, err
+	}
+	switch {
+	case len(origins) > 0:
+		config.AllowOrigins = strings.Join(origins, ",")
+	case getenv("APP_ENV") == "development":
+		config.AllowOrigins = "*"
+	default:
+		// AllowOrigins kosong berarti "*" bagi middleware cors, jadi penolakan memakai fungsi
+		config.AllowOriginsFunc = func(string) bool { return false }
+	}
+
+	if value := strings.TrimSpace(getenv("CORS_ALLOWED_METHODS")); value != "" {
+		methods := splitCSV(value)
+		for i, method := range methods {
+			methods[i] = strings.ToUpper(method)
+			if !isToken(methods[i]) {
+				return config, fmt.Errorf("CORS_ALLOWED_METHODS tidak valid: %q", method)
+			}
+		}
+		config.AllowMethods = strings.Join(methods, ", ")
+	}
+	if value := strings.TrimSpace(getenv("CORS_ALLOWED_HEADERS")); value != "" {
+		headers := splitCSV(value)
+		for _, header := range headers {
+			if !isToken(header) {
+				return config, fmt.Errorf("CORS_ALLOWED_HEADERS tidak valid: %q", header)
+			}
+		}
+		config.AllowHeaders = strings.Join(headers, ", ")
+	}
+
+	return config, nil
+}
+
+// parseCORSOrigins memecah daftar origin dan memastikan tiap origin berbentuk scheme://host[:port]
+// tanpa path. "*" hanya boleh berdiri sendiri.
+func parseCORSOrigins(value string) ([]string, error) {
+	origins := splitCSV(value)
+	for i, origin := range origins {
+		if origin == "*" {
+			if len(origins) > 1 {
+				return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: \"*\" tidak bisa digabung dengan origin lain")
+			}
+			return origins, nil
+		}
+
+		// Wildcard subdomain (https://*.example.com) divalidasi tanpa bagian "*."
+		parsed, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+			(parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" || parsed.User != nil {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: origin tidak valid %q, gunakan format https://domain[:port]", origin)
+		}
+		origins[i] = strings.ToLower(strings.TrimSuffix(origin, "/"))
+	}
+	return origins, nil
+}
+
+func splitCSV(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isToken memeriksa nama method/header hanya berisi karakter token HTTP
+func isToken(value string) bool {
+	return value != "" && strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || strings.ContainsRune("!#$%&'*+-.^_`|~", r))
+	}) == -1
+}
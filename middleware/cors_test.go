@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"testing"
+)
+
+func TestCORSConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantOrigins string
+		wantDenyAll bool
+		wantMethods string
+		wantHeaders string
+		wantErr     bool
+	}{
+		{
+			name:        "daftar origin dinormalisasi",
+			env:         map[string]string{"CORS_ALLOWED_ORIGINS": " https://App.Example.com/ , http://localhost:3000,https://*.example.com"},
+			wantOrigins: "https://app.example.com,http://localhost:3000,https://*.example.com",
+			wantMethods: defaultCORSMethods,
+			wantHeaders: defaultCORSHeaders,
+		},
+		{
+			name:        "semua origin",
+			env:         map[string]string{"CORS_ALLOWED_ORIGINS": "*"},
+			wantOrigins: "*",
+			wantMethods: defaultCORSMethods,
+			wantHeaders: defaultCORSHeaders,
+		},
+		{
+			name:        "kosong di development mengizinkan semua",
+			env:         map[string]string{"APP_ENV": "development"},
+			wantOrigins: "*",
+			wantMethods: defaultCORSMethods,
+			wantHeaders: defaultCORSHeaders,
+		},
+		{
+			name:        "kosong di production menolak semua",
+			env:         map[string]string{"APP_ENV": "production"},
+			wantDenyAll: true,
+			wantMethods: defaultCORSMethods,
+			wantHeaders: defaultCORSHeaders,
+		},
+		{
+			name: "method dan header kustom",
+			env: map[string]string{
+				"CORS_ALLOWED_ORIGINS": "https://app.example.com",
+				"CORS_ALLOWED_METHODS": "get, post",
+				"CORS_ALLOWED_HEADERS": "Content-Type,X-Market-ID",
+			},
+			wantOrigins: "https://app.example.com",
+			wantMethods: "GET, POST",
+			wantHeaders: "Content-Type, X-Market-ID",
+		},
+		{name: "* digabung origin lain", env: map[string]string{"CORS_ALLOWED_ORIGINS": "*,https://app.example.com"}, wantErr: true},
+		{name: "tanpa scheme", env: map[string]string{"CORS_ALLOWED_ORIGINS": "app.example.com"}, wantErr: true},
+		{name: "scheme selain http", env: map[string]string{"CORS_ALLOWED_ORIGINS": "ftp://app.example.com"}, wantErr: true},
+		{name: "dengan path", env: map[string]string{"CORS_ALLOWED_ORIGINS": "https://app.example.com/api"}, wantErr: true},
+		{name: "dengan query", env: map[string]string{"CORS_ALLOWED_ORIGINS": "https://app.example.com?x=1"}, wantErr: true},
+		{name: "method tidak valid", env: map[string]string{"CORS_ALLOWED_ORIGINS": "*", "CORS_ALLOWED_METHODS": "GET,PO ST"}, wantErr: true},
+		{name: "header tidak valid", env: map[string]string{"CORS_ALLOWED_ORIGINS": "*", "CORS_ALLOWED_HEADERS": "X-Test:1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := CORSConfigFromEnv(func(key string) string { return tt.env[key] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if config.AllowOrigins != tt.wantOrigins {
+				t.Errorf("AllowOrigins = %q, want %q", config.AllowOrigins, tt.wantOrigins)
+			}
+			if denyAll := config.AllowOriginsFunc != nil; denyAll != tt.wantDenyAll {
+				t.Errorf("AllowOriginsFunc terpasang = %v, want %v", denyAll, tt.wantDenyAll)
+			} else if denyAll && config.AllowOriginsFunc("https://app.example.com") {
+				t.Error("AllowOriginsFunc harus menolak semua origin")
+			}
+			if config.AllowMethods != tt.wantMethods {
+				t.Errorf("AllowMethods = %q, want %q", config.AllowMethods, tt.wantMethods)
+			}
+			if config.AllowHeaders != tt.wantHeaders {
+				t.Errorf("AllowHeaders = %q, want %q", config.AllowHeaders, tt.wantHeaders)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"backend/logger"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorHandler menggantikan error handler bawaan fiber agar semua error, termasuk route
+// yang tidak dikenal, dikembalikan sebagai envelope JSON { success, message }.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	message := "Internal Server Error"
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+		message = fiberErr.Message
+	} else {
+		logger.FromCtx(c).Error("unhandled error", "error", err)
+	}
+
+	// Pesan bawaan fiber untuk 404 berisi method dan path, samakan dengan format API
+	if status == fiber.StatusNotFound {
+		message = "Not Found"
+	}
+
+	return ErrorResponse(c, status, message)
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag menambahkan weak ETag dari hash body pada response GET 200, lalu membalas 304 tanpa body
+// bila ETag tersebut ada di header If-None-Match. Hash dihitung dari payload yang sudah diserialisasi,
+// jadi query string, filter maupun role yang menghasilkan data berbeda otomatis mendapat ETag berbeda.
+func ETag(c *fiber.Ctx) error {
+	if err := c.Next(); err != nil {
+		return err
+	}
+	if c.Method() != fiber.MethodGet || c.Response().StatusCode() != fiber.StatusOK {
+		return nil
+	}
+
+	etag := `W/"` + hashHex(string(c.Response().Body())) + `"`
+	c.Set(fiber.HeaderETag, etag)
+
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		c.Response().ResetBody()
+		c.Status(fiber.StatusNotModified)
+	}
+	return nil
+}
+
+// etagMatches membandingkan daftar If-None-Match dengan etag secara weak (prefix W/ diabaikan)
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func etagApp(body *string) *fiber.App {
+	app := fiber.New()
+	app.Get("/api/markets", ETag, func(c *fiber.Ctx) error { return c.SendString(*body) })
+	app.Get("/api/missing", ETag, func(c *fiber.Ctx) error { return c.Status(404).SendString("tidak ada") })
+	app.Post("/api/markets", ETag, func(c *fiber.Ctx) error { return c.SendString(*body) })
+	return app
+}
+
+func etagRequest(t *testing.T, app *fiber.App, method, path, ifNoneMatch string) (int, string, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderETag), string(body)
+}
+
+func TestETag(t *testing.T) {
+	body := `[{"id":1,"name":"Pasar Baru"}]`
+	app := etagApp(&body)
+
+	status, etag, _ := etagRequest(t, app, "GET", "/api/markets", "")
+	if status != 200 || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("status = %d, ETag = %q, want 200 dengan weak ETag", status, etag)
+	}
+
+	strong := strings.TrimPrefix(etag, "W/")
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"ETag sama dibalas 304", etag, 304},
+		{"ETag strong dibandingkan secara weak", strong, 304},
+		{"salah satu dari daftar cocok", `W/"lain", ` + etag, 304},
+		{"wildcard", "*", 304},
+		{"ETag berbeda", `W/"lain"`, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, gotETag, gotBody := etagRequest(t, app, "GET", "/api/markets", tt.ifNoneMatch)
+			if status != tt.want {
+				t.Fatalf("status = %d, want %d", status, tt.want)
+			}
+			if gotETag != etag {
+				t.Errorf("ETag = %q, want %q", gotETag, etag)
+			}
+			if status == 304 && gotBody != "" {
+				t.Errorf("body 304 = %q, want kosong", gotBody)
+			}
+			if status == 200 && gotBody != body {
+				t.Errorf("body = %q, want %q", gotBody, body)
+			}
+		})
+	}
+
+	// Data berubah, ETag lama tidak lagi cocok
+	body = `[{"id":1,"name":"Pasar Lama"}]`
+	status, newETag, _ := etagRequest(t, app, "GET", "/api/markets", etag)
+	if status != 200 || newETag == etag {
+		t.Errorf("setelah data berubah: status = %d, ETag = %q, want 200 dengan ETag baru", status, newETag)
+	}
+}
+
+func TestETagSkipsNonGetAndErrors(t *testing.T) {
+	body := `[]`
+	app := etagApp(&body)
+
+	if status, etag, _ := etagRequest(t, app, "POST", "/api/markets", "*"); status != 200 || etag != "" {
+		t.Errorf("POST: status = %d, ETag = %q, want 200 tanpa ETag", status, etag)
+	}
+	if status, etag, _ := etagRequest(t, app, "GET", "/api/missing", "*"); status != 404 || etag != "" {
+		t.Errorf("404: status = %d, ETag = %q, want 404 tanpa ETag", status, etag)
+	}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"backend/database"
+	"backend/logger"
+	"backend/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKeyTTL adalah lama response disimpan untuk satu Idempotency-Key
+const IdempotencyKeyTTL = 24 * time.Hour
+
+const maxIdempotencyKeyLength = 255
+
+// Idempotency membuat POST aman untuk di-retry. Bila request membawa header Idempotency-Key,
+// response 2xx pertama disimpan selama IdempotencyKeyTTL dan request berikutnya dengan key
+// yang sama mendapat response tersebut (dengan header Idempotent-Replayed: true) tanpa
+// menjalankan handler lagi. Key berlaku per user, method dan path; key yang sama dengan body
+// berbeda ditolak 422, dan 409 bila request pertama masih diproses. Response non-2xx tidak
+// disimpan sehingga client bisa mencoba ulang. Dipasang setelah middleware JWT.
+func Idempotency(c *fiber.Ctx) error {
+	key := c.Get("Idempotency-Key")
+	if key == "" {
+		return c.Next()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return ErrorResponse(c, fiber.StatusBadRequest, fmt.Sprintf("Idempotency-Key maksimal %d karakter", maxIdempotencyKeyLength))
+	}
+
+	username, _ := c.Locals("username").(string)
+	scopedKey := hashHex(c.Method() + " " + c.Path() + "\n" + username + "\n" + key)
+	requestHash := hashHex(string(c.Body()))
+
+	record, reserved, err := reserveIdempotencyKey(scopedKey, requestHash, time.Now())
+	if err != nil {
+		logger.FromCtx(c).Error("gagal memeriksa Idempotency-Key", "error", err)
+		return ErrorResponse(c, fiber.StatusInternalServerError, "Gagal memeriksa Idempotency-Key")
+	}
+	if !reserved {
+		switch {
+		case record.RequestHash != requestHash:
+			return ErrorResponse(c, fiber.StatusUnprocessableEntity, "Idempotency-Key sudah dipakai untuk request dengan isi berbeda")
+		case record.StatusCode == 0:
+			return ErrorResponse(c, fiber.StatusConflict, "Request dengan Idempotency-Key yang sama masih diproses")
+		}
+		c.Set("Idempotent-Replayed", "true")
+		if record.ContentType != "" {
+			c.Set(fiber.HeaderContentType, record.ContentType)
+		}
+		return c.Status(record.StatusCode).Send(record.Body)
+	}
+
+	// Handler yang panic juga melepas reservasi, agar key tidak tertahan 409 sampai kedaluwarsa
+	defer func() {
+		if r := recover(); r != nil {
+			releaseIdempotencyKey(c, scopedKey)
+			panic(r)
+		}
+	}()
+
+	handlerErr := c.Next()
+
+	status := c.Response().StatusCode()
+	if handlerErr != nil || status < 200 || status >= 300 {
+		// Gagal: lepas reservasi agar key bisa dipakai lagi
+		releaseIdempotencyKey(c, scopedKey)
+		return handlerErr
+	}
+
+	if err := database.DB.Model(&models.IdempotencyKey{}).Where("`key` = ?", scopedKey).Updates(map[string]interface{}{
+		"status_code":  status,
+		"content_type": string(c.Response().Header.ContentType()),
+		"body":         append([]byte(nil), c.Response().Body()...),
+	}).Error; err != nil {
+		logger.FromCtx(c).Error("gagal menyimpan response Idempotency-Key", "error", err)
+	}
+	return nil
+}
+
+// reserveIdempotencyKey mencoba menyimpan key sebagai "sedang diproses". Bila key sudah ada
+// dan belum kedaluwarsa, record yang ada dikembalikan dengan reserved=false.
+func reserveIdempotencyKey(key, requestHash string, now time.Time) (models.IdempotencyKey, bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		record := models.IdempotencyKey{Key: key, RequestHash: requestHash, ExpiresAt: now.Add(IdempotencyKeyTTL)}
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return models.IdempotencyKey{}, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return record, true, nil
+		}
+
+		var existing models.IdempotencyKey
+		if err := database.DB.First(&existing, "`key` = ?", key).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return models.IdempotencyKey{}, false, err
+		}
+		if existing.ExpiresAt.After(now) {
+			return existing, false, nil
+		}
+		// Entri lama sudah kedaluwarsa, hapus lalu coba reservasi lagi
+		if err := database.DB.Delete(&models.IdempotencyKey{}, "`key` = ? AND expires_at <= ?", key, now).Error; err != nil {
+			return models.IdempotencyKey{}, false, err
+		}
+	}
+	return models.IdempotencyKey{}, false, errors.New("gagal mereservasi Idempotency-Key")
+}
+
+// releaseIdempotencyKey menghapus reservasi yang gagal diselesaikan
+func releaseIdempotencyKey(c *fiber.Ctx, scopedKey string) {
+	if err := database.DB.Delete(&models.IdempotencyKey{}, "`key` = ?", scopedKey).Error; err != nil {
+		logger.FromCtx(c).Error("gagal melepas Idempotency-Key", "error", err)
+	}
+}
+
+// StartIdempotencyKeyCleanup menghapus Idempotency-Key yang sudah kedaluwarsa secara berkala.
+// Dijalankan sebagai goroutine dari main.
+func StartIdempotencyKeyCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		result := database.DB.Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{})
+		if result.Error != nil {
+			slog.Error("gagal membersihkan Idempotency-Key", "error", result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			slog.Info("Idempotency-Key kedaluwarsa dihapus", "count", result.RowsAffected)
+		}
+	}
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"backend/database"
+	"backend/database/dbtest"
+	"backend/models"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// useIdempotencyDB memasang database sqlite dengan tabel yang dipakai test idempotency
+func useIdempotencyDB(t *testing.T) {
+	t.Helper()
+	db := dbtest.Open(t)
+	if err := db.AutoMigrate(&models.IdempotencyKey{}, &models.Market{}); err != nil {
+		t.Fatal(err)
+	}
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+}
+
+// idempotencyApp membuat pasar baru di setiap request yang sampai ke handler
+func idempotencyApp() *fiber.App {
+	app := fiber.New()
+	app.Post("/api/markets", Idempotency, func(c *fiber.Ctx) error {
+		var market models.Market
+		if err := c.BodyParser(&market); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
+		}
+		if err := database.DB.Create(&market).Error; err != nil {
+			return err
+		}
+		return c.Status(201).JSON(market)
+	})
+	return app
+}
+
+func postWithKey(t *testing.T, app *fiber.App, key, body string) (int, string, string) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/markets", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(raw), resp.Header.Get("Idempotent-Replayed")
+}
+
+func countMarkets(t *testing.T) int64 {
+	t.Helper()
+	var count int64
+	if err := database.DB.Model(&models.Market{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	useIdempotencyDB(t)
+	app := idempotencyApp()
+	body := `{"name":"Pasar Baru","location":"Kota"}`
+
+	firstStatus, firstBody, firstReplayed := postWithKey(t, app, "kunci-1", body)
+	secondStatus, secondBody, secondReplayed := postWithKey(t, app, "kunci-1", body)
+
+	if firstStatus != 201 || secondStatus != 201 {
+		t.Fatalf("status = %d lalu %d, want 201 dua kali", firstStatus, secondStatus)
+	}
+	if firstBody != secondBody {
+		t.Errorf("response berbeda:\n%s\n%s", firstBody, secondBody)
+	}
+	if firstReplayed != "" || secondReplayed != "true" {
+		t.Errorf("Idempotent-Replayed = %q lalu %q, want kosong lalu true", firstReplayed, secondReplayed)
+	}
+	if got := countMarkets(t); got != 1 {
+		t.Errorf("pasar tersimpan = %d, want 1", got)
+	}
+
+	// Key lain tetap membuat data baru
+	if status, _, _ := postWithKey(t, app, "kunci-2", body); status != 201 {
+		t.Errorf("key lain: status = %d, want 201", status)
+	}
+	if got := countMarkets(t); got != 2 {
+		t.Errorf("pasar tersimpan = %d, want 2", got)
+	}
+}
+
+func TestIdempotencyRejectsDifferentBody(t *testing.T) {
+	useIdempotencyDB(t)
+	app := idempotencyApp()
+
+	postWithKey(t, app, "kunci-1", `{"name":"Pasar Baru","location":"Kota"}`)
+	if status, _, _ := postWithKey(t, app, "kunci-1", `{"name":"Pasar Lain","location":"Kota"}`); status != fiber.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", status)
+	}
+	if got := countMarkets(t); got != 1 {
+		t.Errorf("pasar tersimpan = %d, want 1", got)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterFailure(t *testing.T) {
+	useIdempotencyDB(t)
+	app := idempotencyApp()
+
+	if status, _, _ := postWithKey(t, app, "kunci-1", `{"name":`); status != 400 {
+		t.Fatalf("body rusak: status = %d, want 400", status)
+	}
+	// Response gagal tidak disimpan sehingga retry dengan key yang sama diproses ulang
+	if status, _, replayed := postWithKey(t, app, "kunci-1", `{"name":`); status != 400 || replayed != "" {
+		t.Errorf("retry: status = %d, replayed = %q, want 400 yang tidak di-replay", status, replayed)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterPanic(t *testing.T) {
+	useIdempotencyDB(t)
+
+	app := fiber.New()
+	// Setara middleware recover: panic dibalas 500 tanpa menghentikan server
+	app.Use(func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = c.Status(500).JSON(fiber.Map{"error": "Internal server error"})
+			}
+		}()
+		return c.Next()
+	})
+	calls := 0
+	app.Post("/api/markets", Idempotency, func(c *fiber.Ctx) error {
+		calls++
+		if calls == 1 {
+			panic("koneksi database terputus")
+		}
+		return c.Status(201).JSON(fiber.Map{"id": 1})
+	})
+
+	if status, _, _ := postWithKey(t, app, "kunci-1", `{}`); status != 500 {
+		t.Fatalf("request pertama: status = %d, want 500", status)
+	}
+	if status, _, _ := postWithKey(t, app, "kunci-1", `{}`); status != 201 {
+		t.Errorf("retry setelah panic: status = %d, want 201 (bukan 409)", status)
+	}
+}
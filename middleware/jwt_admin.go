@@ -1,53 +1,64 @@
 package middleware
 
 import (
-	"fmt"
-	"log"
-	"os"
+	"backend/auth"
+	"backend/logger"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// JWTAdminMiddleware menerima token hasil POST /api/admin/login (claim is_admin) dan token
+// petugas dengan role "admin", lalu mengisi c.Locals username dan role "admin"
 func JWTAdminMiddleware(c *fiber.Ctx) error {
 	authHeader := c.Get("Authorization")
 	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Token diperlukan dalam format Bearer",
-		})
+		return ErrorResponse(c, fiber.StatusUnauthorized, "Token diperlukan dalam format Bearer")
 	}
 
 	tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("metode signing tidak valid: %v", token.Header["alg"])
-		}
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
+	token, err := auth.ParseToken(tokenStr)
 
 	if err != nil || !token.Valid {
-		log.Printf("❌ Token admin tidak valid: %v", err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Token tidak valid",
-		})
+		logger.FromCtx(c).Warn("token admin tidak valid", "error", err)
+		return ErrorResponse(c, fiber.StatusUnauthorized, "Token tidak valid")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || claims["username"] == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Token tidak memiliki username",
-		})
+	if !ok {
+		return ErrorResponse(c, fiber.StatusUnauthorized, "Format token tidak valid")
+	}
+	// Token petugas divalidasi penuh oleh JWTMiddleware (termasuk sesi), lalu harus ber-role admin
+	if isAdmin, _ := claims["is_admin"].(bool); !isAdmin {
+		if role, _ := claims["role"].(string); role != "admin" {
+			return ErrorResponse(c, fiber.StatusForbidden, "Akses khusus admin")
+		}
+		return JWTMiddleware(c)
+	}
+	return adminTokenNext(c, claims)
+}
+
+// adminTokenNext mengisi c.Locals untuk token login admin (claim is_admin) lalu melanjutkan
+// request. market_id dan officer_id diisi 0 agar handler petugas tetap aman dipanggil; role
+// "admin" membuat pemeriksaan akses pasar dilewati.
+func adminTokenNext(c *fiber.Ctx, claims jwt.MapClaims) error {
+	username, ok := claims["username"].(string)
+	if !ok || username == "" {
+		return ErrorResponse(c, fiber.StatusUnauthorized, "Token tidak memiliki username")
 	}
 
 	// Inject username ke context
-	c.Locals("username", claims["username"].(string))
+	c.Locals("username", username)
+	if adminID, ok := idClaim(claims, "admin_id"); ok {
+		c.Locals("admin_id", adminID)
+	}
+	c.Locals("market_id", uint64(0))
+	c.Locals("officer_id", uint64(0))
+	c.Locals("role", "admin")
 
-	log.Printf("✅ Admin login sebagai: %s", claims["username"].(string))
+	logger.FromCtx(c).Debug("token admin diterima", "username", username)
 
 	return c.Next()
 }
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"backend/auth"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func signTestToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := auth.SignToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// roleApp mengembalikan role dan market_id dari c.Locals setelah middleware dijalankan
+func roleApp(handlers ...fiber.Handler) *fiber.App {
+	app := fiber.New()
+	handlers = append(handlers, func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		marketID, _ := c.Locals("market_id").(uint64)
+		return c.JSON(fiber.Map{"role": role, "market_id": marketID})
+	})
+	app.Get("/", handlers...)
+	return app
+}
+
+func callWithToken(t *testing.T, app *fiber.App, token string) int {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+var (
+	adminLoginClaims   = jwt.MapClaims{"username": "admin", "admin_id": 1, "is_admin": true}
+	adminOfficerClaims = jwt.MapClaims{"username": "kepala", "officer_id": 2, "market_id": 3, "role": "admin"}
+	officerClaims      = jwt.MapClaims{"username": "petugas", "officer_id": 4, "market_id": 3, "role": "officer"}
+)
+
+func TestAdminModelIsSharedByBothMiddlewares(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		handlers []fiber.Handler
+		want     int
+	}{
+		{"token admin di JWTMiddleware", adminLoginClaims, []fiber.Handler{JWTMiddleware}, 200},
+		{"token admin di RequireRole admin", adminLoginClaims, []fiber.Handler{JWTMiddleware, RequireRole("admin")}, 200},
+		{"token admin di JWTAdminMiddleware", adminLoginClaims, []fiber.Handler{JWTAdminMiddleware}, 200},
+		{"petugas role admin di JWTAdminMiddleware", adminOfficerClaims, []fiber.Handler{JWTAdminMiddleware}, 200},
+		{"petugas biasa di JWTAdminMiddleware", officerClaims, []fiber.Handler{JWTAdminMiddleware}, 403},
+		{"petugas biasa di RequireRole admin", officerClaims, []fiber.Handler{JWTMiddleware, RequireRole("admin")}, 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{}
+			for k, v := range tt.claims {
+				claims[k] = v
+			}
+			if got := callWithToken(t, roleApp(tt.handlers...), signTestToken(t, claims)); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMarketAccessAllowsAdminToken(t *testing.T) {
+	app := fiber.New()
+	app.Get("/markets/:market_id", JWTMiddleware, ValidateMarketAccess, func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	req := httptest.NewRequest("GET", "/markets/9", nil)
+	req.Header.Set("Authorization", "Bearer "+signTestToken(t, jwt.MapClaims{"username": "admin", "is_admin": true}))
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+}
@@ -1,9 +1,10 @@
 	package middleware
 
 	import (
-		"fmt"
-		"log"
-		"os"
+		"errors"
+		"backend/auth"
+		"backend/logger"
+		"math"
 		"strconv"
 		"strings"
 
@@ -11,83 +12,102 @@
 		"github.com/golang-jwt/jwt/v4"
 	)
 
-	var jwtSecret = []byte(getJWTSecret())
-
-	func getJWTSecret() string {
-		if os.Getenv("JWT_SECRET") != "" {
-			return os.Getenv("JWT_SECRET")
-		}
-		return "default-secret"
-	}
-
 	func JWTMiddleware(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Token diperlukan dalam format Bearer",
-			})
+			return ErrorResponse(c, fiber.StatusUnauthorized, "Token diperlukan dalam format Bearer")
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("metode signing tidak valid: %v", token.Header["alg"])
-			}
-			return jwtSecret, nil
-		})
+		token, err := auth.ParseToken(tokenStr)
 
 		if err != nil {
-			log.Printf("Error parsing token: %v", err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Token tidak valid",
-			})
+			logger.FromCtx(c).Warn("token tidak valid", "error", err)
+			return ErrorResponse(c, fiber.StatusUnauthorized, "Token tidak valid")
 		}
 
 		if !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Token sudah kedaluwarsa",
-			})
+			return ErrorResponse(c, fiber.StatusUnauthorized, "Token sudah kedaluwarsa")
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Format token tidak valid",
-			})
+			return ErrorResponse(c, fiber.StatusUnauthorized, "Format token tidak valid")
+		}
+
+		// Token login admin tidak membawa claim petugas, diperlakukan sebagai role admin
+		if isAdmin, _ := claims["is_admin"].(bool); isAdmin {
+			return adminTokenNext(c, claims)
 		}
 
-		// Validasi claims penting
-		requiredClaims := []string{"market_id", "officer_id", "username"}
-		for _, claim := range requiredClaims {
-			if _, ok := claims[claim]; !ok {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"success": false,
-					"message": fmt.Sprintf("Token tidak mengandung %s", claim),
-				})
+		// Validasi claims penting, claim yang hilang atau tipenya salah ditolak dengan 401
+		marketID, ok := idClaim(claims, "market_id")
+		if !ok {
+			return ErrorResponse(c, fiber.StatusUnauthorized, "Token tidak mengandung market_id yang valid")
+		}
+		officerID, ok := idClaim(claims, "officer_id")
+		if !ok {
+			return ErrorResponse(c, fiber.StatusUnauthorized, "Token tidak mengandung officer_id yang valid")
+		}
+		username, ok := claims["username"].(string)
+		if !ok || username == "" {
+			return ErrorResponse(c, fiber.StatusUnauthorized, "Token tidak mengandung username yang valid")
+		}
+
+		// Token yang membawa jti harus masih tercatat sebagai sesi aktif
+		if jti, ok := claims["jti"].(string); ok && jti != "" {
+			if IsTokenRevoked(jti) {
+				return ErrorResponse(c, fiber.StatusUnauthorized, "Sesi sudah berakhir, silakan login kembali")
+			}
+			if err := checkOfficerSession(jti); err != nil {
+				if errors.Is(err, errSessionRevoked) {
+					return ErrorResponse(c, fiber.StatusUnauthorized, "Sesi sudah berakhir, silakan login kembali")
+				}
+				logger.FromCtx(c).Error("gagal memeriksa sesi", "error", err)
+				return ErrorResponse(c, fiber.StatusInternalServerError, "Gagal memeriksa sesi")
 			}
+			c.Locals("jti", jti)
 		}
 
 		// Log claims untuk debugging
-		log.Printf("JWT Claims - MarketID: %v, OfficerID: %v, Username: %v",
-			claims["market_id"], claims["officer_id"], claims["username"])
+		logger.FromCtx(c).Debug("token officer diterima",
+			"market_id", marketID, "officer_id", officerID, "username", username)
 
 		// Inject ke context
-		c.Locals("market_id", uint64(claims["market_id"].(float64)))
-		c.Locals("officer_id", uint64(claims["officer_id"].(float64)))
-		c.Locals("username", claims["username"].(string))
+		c.Locals("market_id", marketID)
+		c.Locals("officer_id", officerID)
+		c.Locals("username", username)
+
+		// Token lama tanpa claim role diperlakukan sebagai petugas lapangan
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = "officer"
+		}
+		c.Locals("role", role)
 
 		return c.Next()
 	}
+
+	// idClaim membaca claim ID numerik (JSON number menjadi float64) sebagai uint64.
+	// Nilai yang hilang, bukan angka, negatif, atau pecahan dianggap tidak valid.
+	func idClaim(claims jwt.MapClaims, name string) (uint64, bool) {
+		value, ok := claims[name].(float64)
+		if !ok || value < 0 || value != math.Trunc(value) {
+			return 0, false
+		}
+		return uint64(value), true
+	}
+
 	func ValidateMarketAccess(c *fiber.Ctx) error {
-		userMarketID := c.Locals("market_id").(uint64)
+		// Admin boleh mengakses semua pasar
+		if role, _ := c.Locals("role").(string); role == "admin" {
+			return c.Next()
+		}
+		userMarketID, ok := c.Locals("market_id").(uint64)
 		requestMarketID, err := strconv.ParseUint(c.Params("market_id"), 10, 64)
 
-		if err != nil || userMarketID != requestMarketID {
+		if !ok || err != nil || userMarketID != requestMarketID {
 			return c.Status(403).JSON(fiber.Map{
 				"error": "Akses ditolak untuk market ini",
 			})
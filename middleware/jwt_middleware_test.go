@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestIDClaim(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   uint64
+		wantOK bool
+	}{
+		{"angka", float64(7), 7, true},
+		{"nol", float64(0), 0, true},
+		{"string angka", "7", 0, false},
+		{"bukan angka", "abc", 0, false},
+		{"negatif", float64(-1), 0, false},
+		{"pecahan", float64(1.5), 0, false},
+		{"bool", true, 0, false},
+		{"null", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := idClaim(jwt.MapClaims{"market_id": tt.value}, "market_id")
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("idClaim(%v) = %d, %v, want %d, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := idClaim(jwt.MapClaims{}, "market_id"); ok {
+		t.Error("claim yang hilang harus tidak valid")
+	}
+}
+
+func TestJWTMiddlewareRejectsMalformedClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   int
+	}{
+		{"claims lengkap", jwt.MapClaims{"username": "petugas", "officer_id": 4, "market_id": 3}, 200},
+		{"market_id string", jwt.MapClaims{"username": "petugas", "officer_id": 4, "market_id": "3"}, 401},
+		{"market_id hilang", jwt.MapClaims{"username": "petugas", "officer_id": 4}, 401},
+		{"officer_id bukan angka", jwt.MapClaims{"username": "petugas", "officer_id": "empat", "market_id": 3}, 401},
+		{"officer_id negatif", jwt.MapClaims{"username": "petugas", "officer_id": -4, "market_id": 3}, 401},
+		{"username hilang", jwt.MapClaims{"officer_id": 4, "market_id": 3}, 401},
+		{"username bukan string", jwt.MapClaims{"username": 12, "officer_id": 4, "market_id": 3}, 401},
+		{"username kosong", jwt.MapClaims{"username": "", "officer_id": 4, "market_id": 3}, 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := roleApp(JWTMiddleware)
+			if got := callWithToken(t, app, signTestToken(t, tt.claims)); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Header default bila TRUSTED_PROXIES diisi tanpa PROXY_HEADER
+const defaultProxyHeader = "X-Real-IP"
+
+// ProxyConfig menentukan dari mana c.IP() dibaca, dipakai antara lain sebagai kunci rate limit login
+type ProxyConfig struct {
+	ProxyHeader    string
+	TrustedProxies []string
+}
+
+// ProxyConfigFromEnv membaca konfigurasi reverse proxy dari env:
+//   - TRUSTED_PROXIES: IP atau CIDR proxy dipisah koma (mis. "10.0.0.1,172.16.0.0/12")
+//   - PROXY_HEADER: header berisi IP client dari proxy tersebut, default X-Real-IP.
+//     Pakai header yang ditimpa proxy (bukan ditambahkan), karena isi X-Forwarded-For
+//     paling kiri bisa diisi sendiri oleh client.
+//
+// Bila keduanya kosong, c.IP() memakai alamat koneksi langsung. PROXY_HEADER tanpa
+// TRUSTED_PROXIES ditolak karena siapa pun bisa memalsukan IP lewat header tersebut.
+func ProxyConfigFromEnv(getenv func(string) string) (ProxyConfig, error) {
+	config := ProxyConfig{ProxyHeader: strings.TrimSpace(getenv("PROXY_HEADER"))}
+
+	if value := strings.TrimSpace(getenv("TRUSTED_PROXIES")); value != "" {
+		for _, proxy := range splitCSV(value) {
+			if net.ParseIP(proxy) == nil {
+				if _, _, err := net.ParseCIDR(proxy); err != nil {
+					return config, fmt.Errorf("TRUSTED_PROXIES tidak valid: %q", proxy)
+				}
+			}
+			config.TrustedProxies = append(config.TrustedProxies, proxy)
+		}
+	}
+
+	switch {
+	case config.ProxyHeader != "" && len(config.TrustedProxies) == 0:
+		return config, errors.New("PROXY_HEADER membutuhkan TRUSTED_PROXIES")
+	case config.ProxyHeader == "" && len(config.TrustedProxies) > 0:
+		config.ProxyHeader = defaultProxyHeader
+	}
+	if config.ProxyHeader != "" && !isToken(config.ProxyHeader) {
+		return config, fmt.Errorf("PROXY_HEADER tidak valid: %q", config.ProxyHeader)
+	}
+	return config, nil
+}
+
+// Apply memasang konfigurasi proxy ke fiber.Config. Header hanya dipercaya bila request
+// datang dari salah satu TrustedProxies, dan isinya harus berupa IP yang valid.
+func (p ProxyConfig) Apply(config *fiber.Config) {
+	if p.ProxyHeader == "" {
+		return
+	}
+	config.ProxyHeader = p.ProxyHeader
+	config.EnableTrustedProxyCheck = true
+	config.TrustedProxies = p.TrustedProxies
+	config.EnableIPValidation = true
+}
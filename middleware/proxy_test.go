@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestProxyConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    ProxyConfig
+		wantErr bool
+	}{
+		{"tanpa proxy", nil, ProxyConfig{}, false},
+		{"proxy dengan header default", map[string]string{"TRUSTED_PROXIES": "10.0.0.1, 172.16.0.0/12"},
+			ProxyConfig{ProxyHeader: "X-Real-IP", TrustedProxies: []string{"10.0.0.1", "172.16.0.0/12"}}, false},
+		{"header kustom", map[string]string{"TRUSTED_PROXIES": "10.0.0.1", "PROXY_HEADER": "CF-Connecting-IP"},
+			ProxyConfig{ProxyHeader: "CF-Connecting-IP", TrustedProxies: []string{"10.0.0.1"}}, false},
+		{"header tanpa proxy tepercaya", map[string]string{"PROXY_HEADER": "X-Forwarded-For"}, ProxyConfig{}, true},
+		{"proxy tidak valid", map[string]string{"TRUSTED_PROXIES": "10.0.0.300"}, ProxyConfig{}, true},
+		{"header tidak valid", map[string]string{"TRUSTED_PROXIES": "10.0.0.1", "PROXY_HEADER": "X Real IP"}, ProxyConfig{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProxyConfigFromEnv(func(key string) string { return tt.env[key] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyConfigOnlyTrustsConfiguredProxies(t *testing.T) {
+	ipFor := func(proxies string) string {
+		proxyConfig, err := ProxyConfigFromEnv(func(key string) string {
+			return map[string]string{"TRUSTED_PROXIES": proxies}[key]
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		config := fiber.Config{}
+		proxyConfig.Apply(&config)
+		app := fiber.New(config)
+		app.Get("/", func(c *fiber.Ctx) error { return c.SendString(c.IP()) })
+
+		// app.Test memakai alamat koneksi 0.0.0.0
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Real-IP", "203.0.113.7")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 64)
+		n, _ := resp.Body.Read(buf)
+		return string(buf[:n])
+	}
+
+	if got := ipFor("0.0.0.0"); got != "203.0.113.7" {
+		t.Errorf("dari proxy tepercaya IP = %q, want 203.0.113.7", got)
+	}
+	if got := ipFor("10.0.0.1"); got == "203.0.113.7" {
+		t.Errorf("header dari client langsung tidak boleh dipercaya, IP = %q", got)
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultLoginMaxAttempts = 5
+	defaultLoginWindow      = 5 * time.Minute
+)
+
+// LoginAttemptStore menyimpan waktu percobaan login gagal per key (username + IP).
+// Implementasi bawaan menyimpan di memori; bisa diganti (mis. Redis) selama memenuhi interface ini.
+type LoginAttemptStore interface {
+	// Failures mengembalikan waktu percobaan gagal yang masih berada dalam window, terurut dari yang terlama
+	Failures(key string, window time.Duration, now time.Time) []time.Time
+	AddFailure(key string, now time.Time)
+	Reset(key string)
+}
+
+type memoryLoginAttemptStore struct {
+	mu        sync.Mutex
+	attempts  map[string][]time.Time
+	lastSweep time.Time
+	window    time.Duration
+}
+
+// NewMemoryLoginAttemptStore membuat store in-memory yang dijaga mutex
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: make(map[string][]time.Time)}
+}
+
+func (s *memoryLoginAttemptStore) Failures(key string, window time.Duration, now time.Time) []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.window = window
+	recent := pruneAttempts(s.attempts[key], window, now)
+	if len(recent) == 0 {
+		delete(s.attempts, key)
+		return nil
+	}
+	s.attempts[key] = recent
+	return append([]time.Time(nil), recent...)
+}
+
+func (s *memoryLoginAttemptStore) AddFailure(key string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts[key] = append(s.attempts[key], now)
+
+	// Sesekali buang key yang semua percobaannya sudah keluar dari window
+	if s.window > 0 && now.Sub(s.lastSweep) >= s.window {
+		for k, times := range s.attempts {
+			if recent := pruneAttempts(times, s.window, now); len(recent) == 0 {
+				delete(s.attempts, k)
+			} else {
+				s.attempts[k] = recent
+			}
+		}
+		s.lastSweep = now
+	}
+}
+
+func (s *memoryLoginAttemptStore) Reset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+}
+
+func pruneAttempts(times []time.Time, window time.Duration, now time.Time) []time.Time {
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// Store bawaan dipakai bersama oleh semua endpoint login agar hitungan gagal tidak terpisah
+var defaultLoginAttemptStore = NewMemoryLoginAttemptStore()
+
+// LoginRateLimitConfig mengatur batas percobaan login gagal dalam sliding window
+type LoginRateLimitConfig struct {
+	MaxAttempts int
+	Window      time.Duration
+	Store       LoginAttemptStore
+}
+
+// LoginRateLimitConfigFromEnv membaca LOGIN_MAX_ATTEMPTS dan LOGIN_ATTEMPT_WINDOW (durasi Go, mis. "5m"),
+// dengan default 5 percobaan per 5 menit
+func LoginRateLimitConfigFromEnv() LoginRateLimitConfig {
+	config := LoginRateLimitConfig{MaxAttempts: defaultLoginMaxAttempts, Window: defaultLoginWindow}
+
+	if value := os.Getenv("LOGIN_MAX_ATTEMPTS"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			config.MaxAttempts = n
+		} else {
+			slog.Warn("LOGIN_MAX_ATTEMPTS tidak valid, memakai default", "value", value, "default", defaultLoginMaxAttempts)
+		}
+	}
+	if value := os.Getenv("LOGIN_ATTEMPT_WINDOW"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			config.Window = d
+		} else {
+			slog.Warn("LOGIN_ATTEMPT_WINDOW tidak valid, memakai default", "value", value, "default", defaultLoginWindow.String())
+		}
+	}
+
+	return config
+}
+
+// LoginRateLimiter membatasi percobaan login gagal per username + IP. Setelah MaxAttempts kali
+// gagal (response 401) dalam Window, request berikutnya ditolak dengan 429 dan header Retry-After
+// sampai percobaan terlama keluar dari window. Login yang berhasil mereset hitungan.
+func LoginRateLimiter(config LoginRateLimitConfig) fiber.Handler {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultLoginMaxAttempts
+	}
+	if config.Window <= 0 {
+		config.Window = defaultLoginWindow
+	}
+	if config.Store == nil {
+		config.Store = defaultLoginAttemptStore
+	}
+
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			Username string `json:"username"`
+		}
+		_ = c.BodyParser(&body)
+		key := strings.ToLower(strings.TrimSpace(body.Username)) + "|" + c.IP()
+
+		now := time.Now()
+		if failures := config.Store.Failures(key, config.Window, now); len(failures) >= config.MaxAttempts {
+			retryAfter := failures[len(failures)-config.MaxAttempts].Add(config.Window).Sub(now)
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+			return ErrorResponse(c, fiber.StatusTooManyRequests, "Terlalu banyak percobaan login, coba lagi nanti")
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		switch status := c.Response().StatusCode(); {
+		case status == fiber.StatusUnauthorized:
+			config.Store.AddFailure(key, time.Now())
+		case status >= 200 && status < 300:
+			config.Store.Reset(key)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fixedFailureStore mengembalikan percobaan gagal yang sudah disiapkan dan mencatat panggilan
+type fixedFailureStore struct {
+	failures map[string][]time.Time
+	added    []string
+	resets   []string
+}
+
+func (s *fixedFailureStore) Failures(key string, window time.Duration, now time.Time) []time.Time {
+	return pruneAttempts(s.failures[key], window, now)
+}
+
+func (s *fixedFailureStore) AddFailure(key string, now time.Time) {
+	s.added = append(s.added, key)
+	s.failures[key] = append(s.failures[key], now)
+}
+
+func (s *fixedFailureStore) Reset(key string) {
+	s.resets = append(s.resets, key)
+	delete(s.failures, key)
+}
+
+// loginApp membalas 200 untuk password "benar" dan 401 untuk password lain
+func loginApp(config LoginRateLimitConfig) *fiber.App {
+	app := fiber.New()
+	app.Post("/login", LoginRateLimiter(config), func(c *fiber.Ctx) error {
+		var body struct {
+			Password string `json:"password"`
+		}
+		_ = c.BodyParser(&body)
+		if body.Password != "benar" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Username atau password salah"})
+		}
+		return c.JSON(fiber.Map{"success": true})
+	})
+	return app
+}
+
+func login(t *testing.T, app *fiber.App, username, password string) (int, string) {
+	t.Helper()
+	body := `{"username":"` + username + `","password":"` + password + `"}`
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter)
+}
+
+func TestLoginRateLimiter(t *testing.T) {
+	type attempt struct {
+		username string
+		password string
+		want     int
+	}
+	wrong := func(username string) attempt { return attempt{username, "salah", 401} }
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name:     "dikunci setelah MaxAttempts kali gagal",
+			attempts: []attempt{wrong("budi"), wrong("budi"), wrong("budi"), {"budi", "salah", 429}, {"budi", "benar", 429}},
+		},
+		{
+			name:     "login berhasil mereset hitungan",
+			attempts: []attempt{wrong("budi"), wrong("budi"), {"budi", "benar", 200}, wrong("budi"), wrong("budi"), wrong("budi"), {"budi", "benar", 429}},
+		},
+		{
+			name:     "username dihitung tanpa membedakan huruf besar",
+			attempts: []attempt{wrong("Budi"), wrong(" budi"), wrong("BUDI"), {"budi", "benar", 429}},
+		},
+		{
+			name:     "username lain tidak ikut terkunci",
+			attempts: []attempt{wrong("budi"), wrong("budi"), wrong("budi"), {"siti", "benar", 200}, {"budi", "benar", 429}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fixedFailureStore{failures: map[string][]time.Time{}}
+			app := loginApp(LoginRateLimitConfig{MaxAttempts: 3, Window: time.Minute, Store: store})
+
+			for i, a := range tt.attempts {
+				status, retryAfter := login(t, app, a.username, a.password)
+				if status != a.want {
+					t.Fatalf("percobaan %d (%s): status = %d, want %d", i+1, a.username, status, a.want)
+				}
+				if (status == fiber.StatusTooManyRequests) != (retryAfter != "") {
+					t.Errorf("percobaan %d: Retry-After = %q untuk status %d", i+1, retryAfter, status)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginRateLimiterRetryAfter(t *testing.T) {
+	now := time.Now()
+	key := "budi|0.0.0.0"
+	store := &fixedFailureStore{failures: map[string][]time.Time{
+		// Percobaan terlama keluar dari window 5 menit dalam 4 menit lagi
+		key: {now.Add(-2 * time.Minute), now.Add(-time.Minute), now.Add(-30 * time.Second)},
+	}}
+	app := loginApp(LoginRateLimitConfig{MaxAttempts: 2, Window: 5 * time.Minute, Store: store})
+
+	status, retryAfter := login(t, app, "budi", "benar")
+	if status != fiber.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", status)
+	}
+	// Dengan MaxAttempts 2, kunci terbuka saat percobaan kedua terakhir (1 menit lalu) keluar dari window
+	seconds, err := strconv.Atoi(retryAfter)
+	if err != nil || seconds < 239 || seconds > 240 {
+		t.Errorf("Retry-After = %q, want sekitar 240 detik", retryAfter)
+	}
+	if len(store.added) != 0 || len(store.resets) != 0 {
+		t.Errorf("request yang ditolak tidak boleh mengubah store: added=%v resets=%v", store.added, store.resets)
+	}
+}
+
+func TestLoginRateLimiterUpdatesStore(t *testing.T) {
+	store := &fixedFailureStore{failures: map[string][]time.Time{}}
+	app := loginApp(LoginRateLimitConfig{MaxAttempts: 3, Window: time.Minute, Store: store})
+
+	login(t, app, "budi", "salah")
+	login(t, app, "budi", "benar")
+
+	key := "budi|0.0.0.0"
+	if len(store.added) != 1 || store.added[0] != key {
+		t.Errorf("AddFailure = %v, want [%s]", store.added, key)
+	}
+	if len(store.resets) != 1 || store.resets[0] != key {
+		t.Errorf("Reset = %v, want [%s]", store.resets, key)
+	}
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+)
+
+const redacted = "[REDACTED]"
+
+// Header yang nilainya tidak boleh muncul di log
+var sensitiveHeaders = map[string]bool{
+	fiber.HeaderAuthorization: true,
+	fiber.HeaderCookie:        true,
+}
+
+type RequestLoggerConfig struct {
+	// Prefix path yang body request-nya tidak pernah dicatat (mis. endpoint login)
+	OmitBodyPrefixes []string
+
+	// Output log, default os.Stdout
+	Output io.Writer
+}
+
+// RequestLogger membungkus logger fiber dengan header dan body yang sudah disensor. Request ID
+// dari middleware RequestID ikut dicatat, jadi RequestID harus dipasang lebih dulu.
+func RequestLogger(config RequestLoggerConfig) fiber.Handler {
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	return logger.New(logger.Config{
+		Format: "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | request_id=${locals:request_id} | ${error} | headers=${safeHeaders} | body=${safeBody}\n",
+		Output: output,
+		CustomTags: map[string]logger.LogFunc{
+			"safeHeaders": func(output logger.Buffer, c *fiber.Ctx, data *logger.Data, extraParam string) (int, error) {
+				return output.WriteString(redactHeaders(c))
+			},
+			"safeBody": func(output logger.Buffer, c *fiber.Ctx, data *logger.Data, extraParam string) (int, error) {
+				for _, prefix := range config.OmitBodyPrefixes {
+					if strings.HasPrefix(c.Path(), prefix) {
+						return output.WriteString("[omitted]")
+					}
+				}
+				return output.WriteString(redactBody(c))
+			},
+		},
+	})
+}
+
+func redactHeaders(c *fiber.Ctx) string {
+	headers := c.GetReqHeaders()
+
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := strings.Join(headers[key], ",")
+		if sensitiveHeaders[key] {
+			value = redacted
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func redactBody(c *fiber.Ctx) string {
+	body := c.Body()
+	if len(body) == 0 {
+		return ""
+	}
+
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationJSON):
+		var payload interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return "[invalid json]"
+		}
+		redacted, _ := json.Marshal(redactValue(payload))
+		return string(redacted)
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationForm):
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "[invalid form]"
+		}
+		for key := range values {
+			if isSensitiveField(key) {
+				values.Set(key, redacted)
+			}
+		}
+		return values.Encode()
+	case strings.HasPrefix(contentType, fiber.MIMEMultipartForm):
+		return "[multipart]"
+	default:
+		return "[unlogged body]"
+	}
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if isSensitiveField(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(inner)
+		}
+		return v
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(inner)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "password") || strings.Contains(key, "token")
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequestLoggerRedactsCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:        "field password dan token di JSON",
+			path:        "/api/barang",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"name":"Beras","password":"rahasia","detail":{"refresh_token":"abc123"}}`,
+			want:        []string{`"name":"Beras"`, `"password":"[REDACTED]"`, `"refresh_token":"[REDACTED]"`},
+			notWant:     []string{"rahasia", "abc123"},
+		},
+		{
+			name:        "field password di form",
+			path:        "/api/barang",
+			contentType: fiber.MIMEApplicationForm,
+			body:        "username=budi&password=rahasia",
+			want:        []string{"username=budi", "password=%5BREDACTED%5D"},
+			notWant:     []string{"rahasia"},
+		},
+		{
+			name:        "body endpoint login tidak dicatat",
+			path:        "/api/login",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"username":"budi","password":"rahasia"}`,
+			want:        []string{"body=[omitted]"},
+			notWant:     []string{"rahasia", "budi"},
+		},
+		{
+			name:        "JSON rusak tidak dicatat mentah",
+			path:        "/api/barang",
+			contentType: fiber.MIMEApplicationJSON,
+			body:        `{"password":"rahasia"`,
+			want:        []string{"body=[invalid json]"},
+			notWant:     []string{"rahasia"},
+		},
+		{
+			name:        "multipart tidak dicatat",
+			path:        "/api/barang",
+			contentType: fiber.MIMEMultipartForm + "; boundary=x",
+			body:        "--x--",
+			want:        []string{"body=[multipart]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			app := fiber.New()
+			app.Use(RequestLogger(RequestLoggerConfig{OmitBodyPrefixes: []string{"/api/login"}, Output: &output}))
+			app.Post("/*", func(c *fiber.Ctx) error { return c.SendStatus(200) })
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("Authorization", "Bearer token-rahasia")
+			req.Header.Set("Cookie", "session=sesi-rahasia")
+			if _, err := app.Test(req); err != nil {
+				t.Fatal(err)
+			}
+
+			line := output.String()
+			want := append([]string{"Authorization=[REDACTED]", "Cookie=[REDACTED]"}, tt.want...)
+			notWant := append([]string{"token-rahasia", "sesi-rahasia"}, tt.notWant...)
+			for _, s := range want {
+				if !strings.Contains(line, s) {
+					t.Errorf("log tidak memuat %q: %s", s, line)
+				}
+			}
+			for _, s := range notWant {
+				if strings.Contains(line, s) {
+					t.Errorf("log memuat %q: %s", s, line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// RequestID memakai X-Request-ID dari client atau membuat UUID baru, menyimpannya di
+// c.Locals("request_id"), mengirimnya balik di header response, dan menyisipkannya
+// ke setiap body error JSON agar bisa dicocokkan dengan log server. Access log dan
+// logger.FromCtx membaca nilai yang sama sehingga semua baris log satu request berkorelasi.
+func RequestID(c *fiber.Ctx) error {
+	requestID := strings.TrimSpace(c.Get(HeaderRequestID))
+	if requestID == "" || len(requestID) > 128 {
+		requestID = uuid.NewString()
+	}
+
+	c.Locals("request_id", requestID)
+	c.Set(HeaderRequestID, requestID)
+
+	if err := c.Next(); err != nil {
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			return handlerErr
+		}
+	}
+
+	injectRequestID(c, requestID)
+	return nil
+}
+
+// GetRequestID mengambil request ID yang diset oleh middleware RequestID
+func GetRequestID(c *fiber.Ctx) string {
+	requestID, _ := c.Locals("request_id").(string)
+	return requestID
+}
+
+// ErrorResponse menulis envelope error standar { success, message, request_id }
+func ErrorResponse(c *fiber.Ctx, status int, message string) error {
+	body := fiber.Map{
+		"success": false,
+		"message": message,
+	}
+	if requestID := GetRequestID(c); requestID != "" {
+		body["request_id"] = requestID
+	}
+	return c.Status(status).JSON(body)
+}
+
+func injectRequestID(c *fiber.Ctx, requestID string) {
+	if c.Response().StatusCode() < fiber.StatusBadRequest {
+		return
+	}
+	if !strings.HasPrefix(string(c.Response().Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(c.Response().Body(), &body); err != nil {
+		return
+	}
+	if _, ok := body["request_id"]; ok {
+		return
+	}
+
+	body["request_id"] = requestID
+	if encoded, err := json.Marshal(body); err == nil {
+		c.Response().SetBody(encoded)
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"backend/logger"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantSame  bool
+		wantValid bool
+	}{
+		{"memakai header dari client", "req-123", true, false},
+		{"header kosong dibuatkan UUID", "", false, true},
+		{"header terlalu panjang diganti UUID", strings.Repeat("a", 129), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(RequestID)
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendString(GetRequestID(c)) })
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderRequestID, tt.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			got := resp.Header.Get(HeaderRequestID)
+
+			if got != string(body) {
+				t.Errorf("header %q berbeda dengan c.Locals %q", got, body)
+			}
+			if tt.wantSame && got != tt.header {
+				t.Errorf("request ID = %q, want %q", got, tt.header)
+			}
+			if _, err := uuid.Parse(got); tt.wantValid && err != nil {
+				t.Errorf("request ID %q bukan UUID", got)
+			}
+		})
+	}
+}
+
+func TestRequestIDInjectedIntoErrorBodies(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID)
+	app.Get("/error", func(c *fiber.Ctx) error {
+		return c.Status(404).JSON(fiber.Map{"error": "Data tidak ditemukan"})
+	})
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"data": "ok"})
+	})
+
+	for path, want := range map[string]bool{"/error": true, "/ok": false} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(HeaderRequestID, "req-123")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if got := body["request_id"] == "req-123"; got != want {
+			t.Errorf("%s: request_id di body = %v, want ada=%v", path, body["request_id"], want)
+		}
+	}
+}
+
+func TestRequestIDInLogLines(t *testing.T) {
+	var accessLog, appLog bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logger.New(&appLog, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	app := fiber.New()
+	app.Use(RequestID)
+	app.Use(RequestLogger(RequestLoggerConfig{Output: &accessLog}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		logger.FromCtx(c).Info("memproses request")
+		return c.SendStatus(200)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(HeaderRequestID, "req-123")
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(accessLog.String(), "request_id=req-123") {
+		t.Errorf("access log tanpa request_id: %s", accessLog.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(appLog.Bytes(), &entry); err != nil {
+		t.Fatalf("log aplikasi bukan JSON: %v", err)
+	}
+	if entry["request_id"] != "req-123" {
+		t.Errorf("log aplikasi request_id = %v, want req-123", entry["request_id"])
+	}
+}
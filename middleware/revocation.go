@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"backend/database"
+	"backend/models"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// revokedTokens adalah salinan in-memory dari tabel revoked_tokens agar pengecekan
+// di JWTMiddleware tidak perlu query database untuk setiap request
+var revokedTokens = struct {
+	sync.RWMutex
+	expiresAt map[string]time.Time
+}{expiresAt: make(map[string]time.Time)}
+
+// LoadRevokedTokens mengisi daftar hitam in-memory dari database, dipanggil saat server start
+func LoadRevokedTokens() error {
+	var tokens []models.RevokedToken
+	if err := database.DB.Where("expires_at > ?", time.Now()).Find(&tokens).Error; err != nil {
+		return err
+	}
+
+	revokedTokens.Lock()
+	defer revokedTokens.Unlock()
+	for _, t := range tokens {
+		revokedTokens.expiresAt[t.JTI] = t.ExpiresAt
+	}
+	slog.Info("token yang dicabut dimuat", "count", len(tokens))
+	return nil
+}
+
+// RevokeToken memasukkan jti ke daftar hitam sampai token tersebut kedaluwarsa
+func RevokeToken(jti string, expiresAt time.Time) error {
+	record := models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+		return err
+	}
+
+	revokedTokens.Lock()
+	revokedTokens.expiresAt[jti] = expiresAt
+	revokedTokens.Unlock()
+	return nil
+}
+
+// IsTokenRevoked bernilai true bila jti ada di daftar hitam
+func IsTokenRevoked(jti string) bool {
+	revokedTokens.RLock()
+	defer revokedTokens.RUnlock()
+	_, ok := revokedTokens.expiresAt[jti]
+	return ok
+}
+
+// StartRevokedTokenCleanup menghapus entri daftar hitam yang tokennya sudah kedaluwarsa
+// secara berkala. Dijalankan sebagai goroutine dari main.
+func StartRevokedTokenCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		purgeExpiredRevokedTokens(time.Now())
+	}
+}
+
+func purgeExpiredRevokedTokens(now time.Time) {
+	result := database.DB.Where("expires_at <= ?", now).Delete(&models.RevokedToken{})
+	if result.Error != nil {
+		slog.Error("gagal membersihkan token yang dicabut", "error", result.Error)
+		return
+	}
+
+	revokedTokens.Lock()
+	for jti, expiresAt := range revokedTokens.expiresAt {
+		if !expiresAt.After(now) {
+			delete(revokedTokens.expiresAt, jti)
+		}
+	}
+	revokedTokens.Unlock()
+
+	if result.RowsAffected > 0 {
+		slog.Info("token dicabut yang sudah kedaluwarsa dihapus", "count", result.RowsAffected)
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole hanya meneruskan request bila role di c.Locals("role") termasuk salah satu roles.
+// Harus dipasang setelah JWTMiddleware atau JWTAdminMiddleware yang mengisi role tersebut.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+		return ErrorResponse(c, fiber.StatusForbidden, "Akses ditolak untuk role ini")
+	}
+}
+
+// AdminWhen menjalankan JWTAdminMiddleware hanya bila cond terpenuhi, mis. untuk query
+// parameter yang membuka data khusus admin pada endpoint publik
+func AdminWhen(cond func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if cond(c) {
+			return JWTAdminMiddleware(c)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name  string
+		role  interface{}
+		roles []string
+		want  int
+	}{
+		{"role diizinkan", "admin", []string{"admin"}, 200},
+		{"salah satu role diizinkan", "officer", []string{"admin", "officer"}, 200},
+		{"role tidak diizinkan", "officer", []string{"admin"}, 403},
+		{"role kosong", "", []string{"admin"}, 403},
+		{"role tidak diisi middleware", nil, []string{"admin"}, 403},
+		{"role bukan string", 1, []string{"admin"}, 403},
+		{"tanpa role yang diizinkan", "admin", nil, 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRole := func(c *fiber.Ctx) error {
+				if tt.role != nil {
+					c.Locals("role", tt.role)
+				}
+				return c.Next()
+			}
+			if got := callWithToken(t, roleApp(setRole, RequireRole(tt.roles...)), ""); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireRoleTreatsTokenWithoutRoleAsOfficer(t *testing.T) {
+	claims := jwt.MapClaims{"username": "petugas", "officer_id": 4, "market_id": 3}
+
+	if got := callWithToken(t, roleApp(JWTMiddleware, RequireRole("officer")), signTestToken(t, claims)); got != 200 {
+		t.Errorf("RequireRole(officer): status = %d, want 200", got)
+	}
+	claims = jwt.MapClaims{"username": "petugas", "officer_id": 4, "market_id": 3}
+	if got := callWithToken(t, roleApp(JWTMiddleware, RequireRole("admin")), signTestToken(t, claims)); got != 403 {
+		t.Errorf("RequireRole(admin): status = %d, want 403", got)
+	}
+}
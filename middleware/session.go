@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"backend/database"
+	"backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Interval minimum antar pembaruan last_seen agar tidak menulis ke database di setiap request
+const sessionTouchInterval = time.Minute
+
+var errSessionRevoked = errors.New("sesi sudah dicabut atau tidak dikenal")
+
+// checkOfficerSession memastikan token dengan jti tersebut masih aktif dan memperbarui last_seen
+func checkOfficerSession(jti string) error {
+	var session models.OfficerSession
+	if err := database.DB.Where("jti = ?", jti).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errSessionRevoked
+		}
+		return err
+	}
+
+	now := time.Now()
+	if !session.IsActive(now) {
+		return errSessionRevoked
+	}
+
+	if now.Sub(session.LastSeen) >= sessionTouchInterval {
+		database.DB.Model(&models.OfficerSession{}).
+			Where("id = ?", session.ID).
+			Update("last_seen", now)
+	}
+
+	return nil
+}
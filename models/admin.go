@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Admin adalah akun pengelola dashboard, terpisah dari petugas pasar
+type Admin struct {
+	ID        uint64    `json:"id" gorm:"primaryKey"`
+	Username  string    `json:"username" gorm:"type:varchar(255);uniqueIndex;not null"`
+	Password  string    `json:"-" gorm:"not null"`
+	Name      string    `json:"name"`
+	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HashPassword menyimpan hash bcrypt dari password
+func (a *Admin) HashPassword(password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	a.Password = string(hashedPassword)
+	return nil
+}
+
+// CheckPassword mencocokkan password dengan hash yang tersimpan
+func (a *Admin) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(a.Password), []byte(password)) == nil
+}
@@ -1,7 +1,7 @@
 package models
 
 import (
-	"fmt"
+	"log/slog"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,17 +9,20 @@ import (
 
 type Barang struct {
 	IdBarang        uint64         `gorm:"primaryKey;autoIncrement;column:id_barang" json:"id_barang"`
-	Nama            string         `json:"nama"`
-	Satuan          string         `json:"satuan"`
-	HargaPedagang1  float64        `json:"harga_pedagang1"`
-	HargaPedagang2  float64        `json:"harga_pedagang2"`
-	HargaPedagang3  float64        `json:"harga_pedagang3"`
+	Nama            string         `json:"nama" validate:"required,notblank,max=255"`
+	NameKey         string         `json:"-" gorm:"type:varchar(255);index"` // Diisi otomatis dari Nama, lihat NameKey
+	Satuan          string         `json:"satuan" validate:"max=50"`
+	HargaPedagang1  float64        `json:"harga_pedagang1" validate:"gte=0"`
+	HargaPedagang2  float64        `json:"harga_pedagang2" validate:"gte=0"`
+	HargaPedagang3  float64        `json:"harga_pedagang3" validate:"gte=0"`
 	HargaSebelumnya float64        `json:"harga_sebelumnya"`
 	HargaSekarang   float64        `json:"harga_sekarang"`
 	AlasanPerubahan string         `json:"alasan_perubahan"`
 	CategoryID      *uint          `json:"category_id"`
 	MarketID        uint           `json:"market_id"`
-	Category        Category       `gorm:"foreignKey:CategoryID" json:"category"`
+	Category        Category       `gorm:"foreignKey:CategoryID" json:"category" validate:"-"`
+	CreatedBy       *uint64        `json:"created_by" gorm:"index"`                     // Petugas pembuat, nil untuk sync/sistem
+	UpdatedBy       *uint64        `json:"updated_by" gorm:"index"`                     // Petugas terakhir yang mengubah, nil untuk sync/sistem
 	TanggalUpdate   time.Time      `gorm:"column:tanggal_update" json:"tanggal_update"` // Add this field
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
@@ -41,5 +44,5 @@ func MigrateBarang(db *gorm.DB) {
 		}
 	}
 
-	fmt.Println("✅ Tabel Barang sudah dimigrasi dengan sukses!")
+	slog.Info("migrasi Barang berhasil")
 }
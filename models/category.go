@@ -1,31 +1,33 @@
 package models
 
 import (
-	"fmt"
-	"log"
+	"backend/logger"
+	"log/slog"
 
 	"gorm.io/gorm"
 )
 
 type Category struct {
-	ID          uint     `json:"id" gorm:"primaryKey"`
-	Name        string   `json:"name" gorm:"not null"`
-	Description string   `json:"description"`
-	Markets     []Market `json:"markets" gorm:"many2many:category_markets"`
-	Prices      []Price  `json:"prices" gorm:"foreignKey:CategoryID"` // Tambahkan relasi ke Price
-	Barangs     []Barang `gorm:"foreignKey:CategoryID" json:"barangs"`
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" gorm:"not null"`
+	Description string     `json:"description"`
+	ParentID    *uint      `json:"parent_id" gorm:"index"` // Induk subkategori, nil untuk kategori utama
+	Children    []Category `json:"children,omitempty" gorm:"foreignKey:ParentID"`
+	Markets     []Market   `json:"markets" gorm:"many2many:category_markets"`
+	Prices      []Price    `json:"prices" gorm:"foreignKey:CategoryID"` // Tambahkan relasi ke Price
+	Barangs     []Barang   `gorm:"foreignKey:CategoryID" json:"barangs"`
 }
 
 // Fungsi untuk migrasi Category
 func MigrateCategory(db *gorm.DB) {
 	if db.Migrator().HasTable(&Category{}) {
-		fmt.Println("✅ Tabel Category sudah ada, skip migrasi ulang.")
+		slog.Info("tabel Category sudah ada, migrasi dilewati")
 		return
 	}
 
 	if err := db.AutoMigrate(&Category{}); err != nil {
-		log.Fatalf("❌ Gagal migrasi tabel Category: %v", err)
+		logger.Fatal("gagal migrasi tabel Category", "error", err)
 	}
 
-	fmt.Println("✅ Migrasi Category berhasil.")
+	slog.Info("migrasi Category berhasil")
 }
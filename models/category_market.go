@@ -1,6 +1,60 @@
 package models
 
+import (
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// CategoryMarketIndex menjamin satu baris per pasangan kategori dan pasar
+const CategoryMarketIndex = "idx_category_markets_category_market"
+
 type CategoryMarket struct {
 	CategoryID uint `json:"category_id"`
 	MarketID   uint `json:"market_id"`
 }
+
+// MigrateCategoryMarketUniqueIndex merapikan baris category_markets yang kembar lalu membuat
+// unique index (category_id, market_id). Baris kembar tidak membawa data lain sehingga cukup
+// disisakan satu. Aman dipanggil berulang kali.
+func MigrateCategoryMarketUniqueIndex(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&CategoryMarket{}, CategoryMarketIndex) {
+		return nil
+	}
+
+	var duplicates []struct {
+		CategoryID uint
+		MarketID   uint
+	}
+	if err := db.Model(&CategoryMarket{}).
+		Select("category_id, market_id").
+		Group("category_id, market_id").
+		Having("COUNT(*) > 1").
+		Scan(&duplicates).Error; err != nil {
+		return fmt.Errorf("failed to check duplicate category_markets: %w", err)
+	}
+	if len(duplicates) > 0 {
+		slog.Warn("baris category_markets kembar dirapikan sebelum membuat unique index",
+			"index", CategoryMarketIndex, "groups", len(duplicates))
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, d := range duplicates {
+			if err := tx.Where("category_id = ? AND market_id = ?", d.CategoryID, d.MarketID).Delete(&CategoryMarket{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&CategoryMarket{CategoryID: d.CategoryID, MarketID: d.MarketID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove duplicate category_markets: %w", err)
+	}
+
+	if err := db.Exec("CREATE UNIQUE INDEX " + CategoryMarketIndex + " ON category_markets (category_id, market_id)").Error; err != nil {
+		return fmt.Errorf("failed to create %s: %w", CategoryMarketIndex, err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestMigrateCategoryMarketUniqueIndexRemovesDuplicates(t *testing.T) {
+	db := dbtest.Open(t)
+	// Tabel lama tanpa primary key maupun unique index, sehingga bisa berisi baris kembar
+	if err := db.Migrator().CreateTable(&CategoryMarket{}); err != nil {
+		t.Fatal(err)
+	}
+	rows := []CategoryMarket{{1, 1}, {1, 1}, {1, 1}, {1, 2}, {2, 1}, {2, 1}}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := MigrateCategoryMarketUniqueIndex(db); err != nil {
+			t.Fatalf("migrasi ke-%d: %v", i+1, err)
+		}
+	}
+
+	var links []CategoryMarket
+	if err := db.Order("category_id, market_id").Find(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []CategoryMarket{{1, 1}, {1, 2}, {2, 1}}
+	if len(links) != len(want) {
+		t.Fatalf("links = %v, want %v", links, want)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("links[%d] = %v, want %v", i, links[i], want[i])
+		}
+	}
+
+	if !db.Migrator().HasIndex(&CategoryMarket{}, CategoryMarketIndex) {
+		t.Fatalf("index %s belum dibuat", CategoryMarketIndex)
+	}
+	if err := db.Create(&CategoryMarket{CategoryID: 1, MarketID: 1}).Error; err == nil {
+		t.Error("baris kembar masih bisa disimpan setelah unique index dibuat")
+	}
+}
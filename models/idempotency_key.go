@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey menyimpan response dari request POST yang memakai header Idempotency-Key,
+// agar retry dengan key yang sama mendapat response asli tanpa membuat data baru.
+// StatusCode 0 berarti request pertama masih diproses. Entri boleh dihapus setelah ExpiresAt.
+type IdempotencyKey struct {
+	Key         string    `json:"key" gorm:"primaryKey;type:varchar(64)"`
+	RequestHash string    `json:"request_hash" gorm:"type:varchar(64)"`
+	StatusCode  int       `json:"status_code"`
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"-"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
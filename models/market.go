@@ -1,7 +1,8 @@
 package models
 
 import (
-	"log"
+	"backend/logger"
+	"log/slog"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,11 +11,13 @@ import (
 // Struktur Market dengan timestamps dan soft delete
 type Market struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
-	Name      string         `gorm:"not null" json:"name"`
-	Location  string         `gorm:"not null" json:"location"`
-	ImageURL  string         `json:"image_url"`
-	Latitude  float64        `gorm:"default:0" json:"latitude"`
-	Longitude float64        `gorm:"default:0" json:"longitude"`
+	Name      string         `gorm:"not null" json:"name" validate:"required,notblank,max=255"`
+	Location  string         `gorm:"not null" json:"location" validate:"required,notblank,max=255"`
+	ImageURL  string         `json:"image_url" validate:"max=2048"`
+	Latitude  float64        `gorm:"default:0" json:"latitude" validate:"gte=-90,lte=90"`
+	Longitude float64        `gorm:"default:0" json:"longitude" validate:"gte=-180,lte=180"`
+	Currency  string         `gorm:"type:varchar(3);default:IDR" json:"currency"`
+	Locale    string         `gorm:"type:varchar(10);default:id-ID" json:"locale"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -23,21 +26,21 @@ type MarketResponse struct {
 	ID        uint    `json:"id"`
 	Name      string  `json:"name"`
 	Location  string  `json:"location"`
-	ImageURL  string  `json:"image_url"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	ImageURL  string  `json:"image_url,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
 }
 
 // Fungsi untuk migrasi tabel Market
 func MigrateMarket(db *gorm.DB) {
 	if db.Migrator().HasTable(&Market{}) {
-		log.Println("✅ Market table already exists. Skipping migration.")
+		slog.Info("tabel Market sudah ada, migrasi dilewati")
 		return
 	}
 
 	if err := db.AutoMigrate(&Market{}); err != nil {
-		log.Fatalf("❌ Failed to migrate Market table: %v", err)
+		logger.Fatal("gagal migrasi tabel Market", "error", err)
 	}
 
-	log.Println("✅ Market table migrated successfully.")
+	slog.Info("migrasi Market berhasil")
 }
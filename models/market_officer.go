@@ -1,43 +1,65 @@
 package models
 
 import (
+	"fmt"
+	"log/slog"
+	"os"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+const (
+	OfficerRoleAdmin   = "admin"
+	OfficerRoleOfficer = "officer"
+)
+
 type MarketOfficer struct {
-	ID        uint64    `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name"`
-	Nik       string    `json:"nik" gorm:"type:varchar(255);uniqueIndex:idx_market_officers_nik"`
-	Phone     string    `json:"phone"`
-	ImageURL  string    `json:"image_url"`
-	Username  string    `json:"username" gorm:"type:varchar(255);uniqueIndex:idx_market_officers_username"`
-	Password  string    `json:"-"`
-	MarketID  uint64    `json:"market_id"`
-	Market    Market    `json:"market" gorm:"foreignKey:MarketID;references:ID"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	IsActive  bool      `json:"is_active" gorm:"default:true"`
+	ID        uint64         `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" validate:"required,notblank,max=255"`
+	Nik       string         `json:"nik" gorm:"type:varchar(255);uniqueIndex:idx_market_officers_nik" validate:"required,nik"`
+	Phone     string         `json:"phone" validate:"omitempty,phone"`
+	ImageURL  string         `json:"image_url" validate:"max=2048"`
+	Username  string         `json:"username" gorm:"type:varchar(255);uniqueIndex:idx_market_officers_username" validate:"required,notblank,max=255"`
+	Password  string         `json:"-"`
+	MarketID  uint64         `json:"market_id" validate:"required"`
+	Market    Market         `json:"market" gorm:"foreignKey:MarketID;references:ID" validate:"-"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	Role      string         `json:"role" gorm:"type:varchar(20);default:officer;index" validate:"omitempty,oneof=admin officer"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 type MarketOfficerResponse struct {
 	ID       uint64          `json:"id"`
 	Name     string          `json:"name"`
 	Username string          `json:"username"`
-	Nik      string          `json:"nik"`
-	Phone    string          `json:"phone"`
-	ImageURL string          `json:"image_url"`
+	Nik      string          `json:"nik,omitempty"`
+	Phone    string          `json:"phone,omitempty"`
+	ImageURL string          `json:"image_url,omitempty"`
 	MarketID uint64          `json:"market_id"`
-	Market   *MarketResponse `json:"market"`
+	Market   *MarketResponse `json:"market,omitempty"`
 }
 
-// MigrateMarketOfficer membuat tabel MarketOfficer jika belum ada
-func MigrateMarketOfficer(db *gorm.DB) {
-	if err := db.Migrator().DropTable(&MarketOfficer{}); err != nil {
-		panic("Failed to drop the existing MarketOfficer table: " + err.Error())
+// MigrateMarketOfficer membuat tabel MarketOfficer jika belum ada dan menambah kolom baru tanpa
+// menghapus data. Reset tabel (drop lalu buat ulang) hanya dilakukan bila FORCE_OFFICER_RESET=true;
+// hapus env tersebut setelah reset karena migrasi dijalankan di setiap startup.
+func MigrateMarketOfficer(db *gorm.DB) error {
+	if os.Getenv("FORCE_OFFICER_RESET") == "true" {
+		slog.Warn("FORCE_OFFICER_RESET=true, tabel MarketOfficer dihapus dan dibuat ulang")
+		if err := db.Migrator().DropTable(&MarketOfficer{}); err != nil {
+			return fmt.Errorf("failed to drop the existing MarketOfficer table: %w", err)
+		}
+	}
+
+	if !db.Migrator().HasTable(&MarketOfficer{}) {
+		if err := db.Migrator().CreateTable(&MarketOfficer{}); err != nil {
+			return fmt.Errorf("failed to create MarketOfficer table: %w", err)
+		}
 	}
 
 	if err := db.AutoMigrate(&MarketOfficer{}); err != nil {
-		panic("Failed to migrate MarketOfficer table: " + err.Error())
+		return fmt.Errorf("failed to migrate MarketOfficer table: %w", err)
 	}
+	return nil
 }
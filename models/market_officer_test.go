@@ -0,0 +1,44 @@
+package models
+
+import (
+	"backend/database/dbtest"
+	"testing"
+)
+
+func TestMigrateMarketOfficerKeepsOfficers(t *testing.T) {
+	db := dbtest.Open(t)
+	if err := db.AutoMigrate(&Market{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateMarketOfficer(db); err != nil {
+		t.Fatal(err)
+	}
+	officer := MarketOfficer{Name: "Budi", Nik: "3201010101010001", Username: "budi", IsActive: true}
+	if err := db.Create(&officer).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	countOfficers := func() int64 {
+		var count int64
+		if err := db.Model(&MarketOfficer{}).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	// Startup berikutnya tidak boleh menghapus petugas yang sudah ada
+	if err := MigrateMarketOfficer(db); err != nil {
+		t.Fatal(err)
+	}
+	if got := countOfficers(); got != 1 {
+		t.Fatalf("petugas = %d setelah migrasi ulang, want 1", got)
+	}
+
+	t.Setenv("FORCE_OFFICER_RESET", "true")
+	if err := MigrateMarketOfficer(db); err != nil {
+		t.Fatal(err)
+	}
+	if got := countOfficers(); got != 0 {
+		t.Errorf("petugas = %d setelah FORCE_OFFICER_RESET, want 0", got)
+	}
+}
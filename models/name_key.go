@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NameKey adalah kunci pencocokan nama komoditas antara tabel barang dan harga: spasi di awal
+// dan akhir dibuang, spasi berulang dijadikan satu, lalu huruf kecil. "Beras ", "beras" dan
+// "BERAS" menghasilkan kunci yang sama. Nama yang ditampilkan tetap seperti yang diinput.
+func NameKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// BeforeSave mengisi NameKey dari ItemName setiap kali harga disimpan lewat struct
+func (p *Price) BeforeSave(tx *gorm.DB) error {
+	p.NameKey = NameKey(p.ItemName)
+	return nil
+}
+
+// BeforeSave mengisi NameKey dari Nama setiap kali barang disimpan lewat struct
+func (b *Barang) BeforeSave(tx *gorm.DB) error {
+	b.NameKey = NameKey(b.Nama)
+	return nil
+}
+
+// BackfillNameKeys mengisi name_key yang masih kosong pada data lama. Aman dipanggil berulang kali.
+func BackfillNameKeys(db *gorm.DB) error {
+	var prices []Price
+	if err := db.Unscoped().Select("id", "item_name").Where("name_key = '' OR name_key IS NULL").Find(&prices).Error; err != nil {
+		return fmt.Errorf("failed to load prices for name_key backfill: %w", err)
+	}
+	for _, price := range prices {
+		if err := db.Unscoped().Model(&Price{}).Where("id = ?", price.ID).UpdateColumn("name_key", NameKey(price.ItemName)).Error; err != nil {
+			return fmt.Errorf("failed to backfill price name_key: %w", err)
+		}
+	}
+
+	var barangs []Barang
+	if err := db.Unscoped().Select("id_barang", "nama").Where("name_key = '' OR name_key IS NULL").Find(&barangs).Error; err != nil {
+		return fmt.Errorf("failed to load barang for name_key backfill: %w", err)
+	}
+	for _, barang := range barangs {
+		if err := db.Unscoped().Model(&Barang{}).Where("id_barang = ?", barang.IdBarang).UpdateColumn("name_key", NameKey(barang.Nama)).Error; err != nil {
+			return fmt.Errorf("failed to backfill barang name_key: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import "testing"
+
+func TestNameKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Beras", "beras"},
+		{"Beras ", "beras"},
+		{"  BERAS", "beras"},
+		{"Cabai   Rawit\tMerah", "cabai rawit merah"},
+		{"Gula Pasir\n", "gula pasir"},
+		{"", ""},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := NameKey(tt.name); got != tt.want {
+			t.Errorf("NameKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBeforeSaveFillsNameKey(t *testing.T) {
+	price := Price{ItemName: " Minyak  Goreng "}
+	if err := price.BeforeSave(nil); err != nil {
+		t.Fatal(err)
+	}
+	if price.NameKey != "minyak goreng" || price.ItemName != " Minyak  Goreng " {
+		t.Errorf("price = %q/%q, want nama tetap dan kunci minyak goreng", price.ItemName, price.NameKey)
+	}
+
+	barang := Barang{Nama: "MINYAK GORENG"}
+	if err := barang.BeforeSave(nil); err != nil {
+		t.Fatal(err)
+	}
+	if barang.NameKey != price.NameKey {
+		t.Errorf("barang.NameKey = %q, want %q", barang.NameKey, price.NameKey)
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// OfficerSession mencatat setiap token login petugas (dikenali lewat claim jti) beserta
+// perangkatnya, sehingga token tertentu bisa dicabut tanpa memengaruhi perangkat lain
+type OfficerSession struct {
+	ID        uint       `json:"-" gorm:"primaryKey"`
+	JTI       string     `json:"jti" gorm:"type:varchar(64);uniqueIndex:idx_officer_sessions_jti"`
+	OfficerID uint64     `json:"officer_id" gorm:"index"`
+	Device    string     `json:"device"`
+	IPAddress string     `json:"ip_address"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	LastSeen  time.Time  `json:"last_seen"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive bernilai true bila token belum dicabut dan belum kedaluwarsa
+func (s OfficerSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
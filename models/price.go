@@ -1,28 +1,71 @@
 package models
 
 import (
+	"fmt"
+	"log/slog"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// PriceNameMarketIndex menjamin satu baris harga per komoditas (name_key) per pasar
+const PriceNameMarketIndex = "idx_prices_name_market"
+
 type Price struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`
 	ItemID        uint           `json:"item_id"`
-	ItemName      string         `json:"item_name"`
-	InitialPrice  float64        `json:"initial_price"`
-	CurrentPrice  float64        `json:"current_price"`
+	ItemName      string         `json:"item_name" validate:"required,notblank,max=255"`
+	NameKey       string         `json:"-" gorm:"type:varchar(255);index"` // Diisi otomatis dari ItemName, lihat NameKey
+	InitialPrice  float64        `json:"initial_price" validate:"gte=0"`
+	CurrentPrice  float64        `json:"current_price" validate:"gte=0"`
 	ChangePercent float64        `json:"change_percent"`
-	Reason        string         `json:"reason"`
+	Reason        string         `json:"reason" validate:"max=255"`
 	MarketID      uint           `json:"market_id"`
-	Market        Market         `json:"market" gorm:"foreignKey:MarketID"`
+	Market        Market         `json:"market" gorm:"foreignKey:MarketID" validate:"-"`
 	CategoryID    uint           `json:"category_id"`
-	Category      Category       `json:"category" gorm:"foreignKey:CategoryID"`
+	Category      Category       `json:"category" gorm:"foreignKey:CategoryID" validate:"-"`
+	CreatedBy     *uint64        `json:"created_by" gorm:"index"` // Petugas pembuat, nil untuk sync/sistem
+	UpdatedBy     *uint64        `json:"updated_by" gorm:"index"` // Petugas terakhir yang mengubah, nil untuk sync/sistem
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at" gorm:"column:updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"` // optional soft delete
+
+	// Petunjuk format untuk client, diisi dari pasar dan tidak disimpan di database
+	Currency              string `json:"currency,omitempty" gorm:"-"`
+	Locale                string `json:"locale,omitempty" gorm:"-"`
+	FormattedInitialPrice string `json:"formatted_initial_price,omitempty" gorm:"-"`
+	FormattedCurrentPrice string `json:"formatted_current_price,omitempty" gorm:"-"`
 }
 
 func MigratePrice(db *gorm.DB) {
 	db.AutoMigrate(&Price{})
 }
+
+// MigratePriceUniqueIndex membuat unique index (name_key, market_id) pada tabel harga. Harus
+// dijalankan setelah BackfillNameKeys. Bila data lama masih punya duplikat, index dilewati dengan
+// peringatan agar server tetap jalan; rapikan duplikatnya lewat GET /api/admin/duplicates lalu
+// restart. Aman dipanggil berulang kali.
+func MigratePriceUniqueIndex(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&Price{}, PriceNameMarketIndex) {
+		return nil
+	}
+
+	var duplicates int64
+	if err := db.Unscoped().Model(&Price{}).
+		Select("name_key, market_id").
+		Group("name_key, market_id").
+		Having("COUNT(*) > 1").
+		Count(&duplicates).Error; err != nil {
+		return fmt.Errorf("failed to check duplicate prices: %w", err)
+	}
+	if duplicates > 0 {
+		slog.Warn("unique index harga dilewati karena masih ada duplikat nama per pasar",
+			"index", PriceNameMarketIndex, "groups", duplicates)
+		return nil
+	}
+
+	if err := db.Exec("CREATE UNIQUE INDEX " + PriceNameMarketIndex + " ON prices (name_key, market_id)").Error; err != nil {
+		return fmt.Errorf("failed to create %s: %w", PriceNameMarketIndex, err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// PriceConfirmation menandai bahwa petugas sudah mengecek harga dan harganya tidak berubah,
+// dicatat terpisah dari PriceHistory karena nilai harga tidak ikut diubah
+type PriceConfirmation struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	PriceID     uint      `json:"price_id" gorm:"index"`
+	ItemID      uint      `json:"item_id"`
+	MarketID    uint      `json:"market_id" gorm:"index"`
+	OfficerID   *uint64   `json:"officer_id" gorm:"index"` // nil bila petugasnya sudah dihapus
+	Username    string    `json:"username"`
+	Price       float64   `json:"price"`
+	ConfirmedAt time.Time `json:"confirmed_at" gorm:"index"`
+}
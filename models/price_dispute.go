@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	DisputeStatusOpen     = "open"
+	DisputeStatusResolved = "resolved"
+)
+
+// PriceDispute menyimpan laporan keberatan atas harga yang dilaporkan,
+// terpisah dari PriceHistory agar tidak mengotori data grafik
+type PriceDispute struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	PriceID    uint       `json:"price_id" gorm:"index"`
+	Price      Price      `json:"price" gorm:"foreignKey:PriceID"`
+	ReportedBy string     `json:"reported_by"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status" gorm:"type:varchar(20);default:open;index"`
+	Resolution string     `json:"resolution"`
+	ResolvedBy string     `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
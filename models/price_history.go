@@ -1,18 +1,66 @@
 package models
 
 import (
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type PriceHistory struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	ItemID       uint      `json:"item_id"`
-	ItemName     string    `json:"item_name"`
-	InitialPrice float64   `json:"initial_price"`
-	CurrentPrice float64   `json:"current_price"`
-	Reason       string    `json:"reason"`
-	MarketID     uint      `json:"market_id"`
-	CategoryID   uint      `json:"category_id"`
-	ChangePercent float64  `json:"change_percent"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ItemID        uint      `json:"item_id" gorm:"index:idx_price_histories_item_created,priority:1"`
+	ItemName      string    `json:"item_name"`
+	InitialPrice  float64   `json:"initial_price"`
+	CurrentPrice  float64   `json:"current_price"`
+	Reason        string    `json:"reason"`
+	MarketID      uint      `json:"market_id" gorm:"index"`
+	CategoryID    uint      `json:"category_id" gorm:"index:idx_price_histories_category_created,priority:1"`
+	ChangePercent float64   `json:"change_percent"`
+	OfficerID     *uint64   `json:"officer_id" gorm:"index"` // Petugas yang mengubah harga, nil untuk data lama atau perubahan sistem
+	CreatedAt     time.Time `json:"created_at" gorm:"index:idx_price_histories_item_created,priority:2;index:idx_price_histories_category_created,priority:2"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Index yang dipakai query histori per item/kategori (ORDER BY created_at) dan filter per pasar/petugas
+var priceHistoryIndexes = []string{
+	"idx_price_histories_item_created",
+	"idx_price_histories_category_created",
+	"MarketID",
+	"OfficerID",
+}
+
+// MigratePriceHistory membuat tabel PriceHistory jika belum ada, lalu menambah kolom dan index
+// yang belum ada tanpa menyentuh data lama. Aman dipanggil berulang kali.
+func MigratePriceHistory(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&PriceHistory{}) {
+		if err := migrator.CreateTable(&PriceHistory{}); err != nil {
+			return fmt.Errorf("failed to create PriceHistory table: %w", err)
+		}
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&PriceHistory{}); err != nil {
+		return fmt.Errorf("failed to parse PriceHistory schema: %w", err)
+	}
+	for _, column := range stmt.Schema.DBNames {
+		if migrator.HasColumn(&PriceHistory{}, column) {
+			continue
+		}
+		if err := migrator.AddColumn(&PriceHistory{}, column); err != nil {
+			return fmt.Errorf("failed to add PriceHistory column %s: %w", column, err)
+		}
+	}
+
+	for _, index := range priceHistoryIndexes {
+		if migrator.HasIndex(&PriceHistory{}, index) {
+			continue
+		}
+		if err := migrator.CreateIndex(&PriceHistory{}, index); err != nil {
+			return fmt.Errorf("failed to create PriceHistory index %s: %w", index, err)
+		}
+	}
+	return nil
 }
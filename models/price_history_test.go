@@ -0,0 +1,71 @@
+package models
+
+import (
+	"backend/database/dbtest"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func assertPriceHistorySchema(t *testing.T, migrator gorm.Migrator) {
+	t.Helper()
+	for _, column := range []string{"item_id", "item_name", "change_percent", "officer_id", "created_at", "updated_at"} {
+		if !migrator.HasColumn(&PriceHistory{}, column) {
+			t.Errorf("kolom %s tidak ada", column)
+		}
+	}
+	for _, index := range priceHistoryIndexes {
+		if !migrator.HasIndex(&PriceHistory{}, index) {
+			t.Errorf("index %s tidak ada", index)
+		}
+	}
+}
+
+func TestMigratePriceHistoryCreatesTable(t *testing.T) {
+	db := dbtest.Open(t)
+
+	for i := 0; i < 2; i++ {
+		if err := MigratePriceHistory(db); err != nil {
+			t.Fatalf("migrasi ke-%d: %v", i+1, err)
+		}
+	}
+	assertPriceHistorySchema(t, db.Migrator())
+}
+
+func TestMigratePriceHistoryUpgradesLegacyTable(t *testing.T) {
+	db := dbtest.Open(t)
+
+	// Tabel versi lama tanpa officer_id, change_percent, updated_at dan tanpa index
+	if err := db.Exec(`CREATE TABLE price_histories (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		item_id integer,
+		item_name text,
+		initial_price real,
+		current_price real,
+		reason text,
+		market_id integer,
+		category_id integer,
+		created_at datetime
+	)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec(`INSERT INTO price_histories (item_id, item_name, initial_price, current_price, market_id, category_id, created_at)
+		VALUES (1, 'Cabai', 10000, 12000, 1, 1, '2024-05-10 08:00:00')`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := MigratePriceHistory(db); err != nil {
+			t.Fatalf("migrasi ke-%d: %v", i+1, err)
+		}
+	}
+	assertPriceHistorySchema(t, db.Migrator())
+
+	var histories []PriceHistory
+	if err := db.Find(&histories).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 1 || histories[0].ItemName != "Cabai" || histories[0].CurrentPrice != 12000 || histories[0].OfficerID != nil {
+		t.Errorf("data lama berubah: %+v", histories)
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken menyimpan token refresh petugas. Nilai aslinya hanya dikirim ke client,
+// yang disimpan di database adalah hash SHA-256-nya. SessionJTI menautkan token ke
+// OfficerSession yang diterbitkan bersamanya, sehingga mencabut sesi ikut mencabut refresh token.
+type RefreshToken struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	OfficerID  uint64    `json:"officer_id" gorm:"index"`
+	SessionJTI string    `json:"session_jti" gorm:"type:varchar(64);index"`
+	TokenHash  string    `json:"-" gorm:"type:varchar(64);uniqueIndex:idx_refresh_tokens_token_hash"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Revoked    bool      `json:"revoked" gorm:"default:false"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+)
+
+// RevokedToken adalah daftar hitam jti access token yang sudah logout atau dicabut.
+// Entri boleh dihapus setelah ExpiresAt karena tokennya sendiri sudah tidak berlaku.
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"primaryKey;type:varchar(64)"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
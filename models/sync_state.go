@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SyncStateBarangPrice adalah nama state untuk sinkronisasi barang dan harga
+const SyncStateBarangPrice = "barang_price"
+
+// SyncState menyimpan watermark sinkronisasi terakhir yang berhasil, dipakai oleh sync inkremental
+type SyncState struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Name         string    `json:"name" gorm:"type:varchar(50);uniqueIndex"`
+	LastSyncedAt time.Time `json:"last_synced_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"backend/controllers"
+	"backend/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,11 +10,22 @@ import (
 func RegisterBarangRoutes(app *fiber.App) {
 	api := app.Group("/api")
 	api.Get("/barang", controllers.GetAllBarang)
+	api.Get("/barang/trash", middleware.JWTMiddleware, middleware.RequireRole("admin"), controllers.GetBarangTrash)
 	api.Get("/barang/:id", controllers.GetBarangByID)
-	api.Post("/barang", controllers.CreateBarang)
-	api.Put("/barang/:id", controllers.UpdateBarang)
-	api.Delete("/barang/:id", controllers.DeleteBarang)
+	// Perubahan barang wajib login, petugas hanya untuk pasarnya sendiri dan hapus khusus admin
+	api.Post("/barang", middleware.JWTMiddleware, middleware.Idempotency, controllers.CreateBarang)
+	api.Post("/barang/bulk", middleware.JWTMiddleware, controllers.BulkCreateBarang)
+	api.Post("/barang/import/preview", controllers.PreviewImport)
+	api.Put("/barang/:id", middleware.JWTMiddleware, controllers.UpdateBarang)
+	api.Patch("/barang/:id", middleware.JWTMiddleware, controllers.PatchBarang)
+	api.Delete("/barang/:id", middleware.JWTMiddleware, middleware.RequireRole("admin"), controllers.DeleteBarang)
+	api.Delete("/barang/:id/permanent", middleware.JWTMiddleware, middleware.RequireRole("admin"), controllers.DeleteBarangPermanent)
+	api.Post("/barang/:id/restore", middleware.JWTMiddleware, middleware.RequireRole("admin"), controllers.RestoreBarang)
 	api.Get("/barang/:id/history", controllers.GetBarangHistory)
+	api.Get("/barang/:id/series", controllers.GetBarangPriceSeries)
 	app.Get("/api/barang/market/:marketId", controllers.GetBarangByMarketID)
 	app.Get("/api/barang/market/:marketId/paginated", controllers.GetBarangByMarketIDPaginated)
+
+	api.Post("/admin/backfill-barang-market", middleware.JWTAdminMiddleware, controllers.BackfillBarangMarket)
+	api.Get("/admin/duplicates", middleware.JWTAdminMiddleware, controllers.GetDuplicateCommodities)
 }
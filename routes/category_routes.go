@@ -13,8 +13,10 @@ func RegisterCategoryRoutes(app *fiber.App) {
 
 	api := app.Group("/api")
 
-	api.Get("/categories", controllers.GetCategories)
+	api.Get("/categories", middlewares.ETag, controllers.GetCategories)
 	api.Get("/categories/:id", controllers.GetCategoryByID)
+	api.Get("/categories/:id/markets", controllers.GetMarketsByCategoryID)
+	api.Post("/categories/:id/link-all-markets", middlewares.JWTAdminMiddleware, controllers.LinkCategoryToAllMarkets)
 	api.Post("/categories", controllers.CreateCategory)
 	api.Put("/categories/:id", controllers.UpdateCategory)
 	api.Delete("/categories/:id", controllers.DeleteCategory)
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"backend/docs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterDocsRoutes menyajikan spesifikasi OpenAPI di /swagger/doc.json dan Swagger UI di /swagger/
+func RegisterDocsRoutes(app *fiber.App) {
+	app.Get("/swagger", func(c *fiber.Ctx) error {
+		return c.Redirect("/swagger/index.html", fiber.StatusMovedPermanently)
+	})
+	app.Get("/swagger/*", func(c *fiber.Ctx) error {
+		switch c.Params("*") {
+		case "doc.json":
+			c.Type("json")
+			return c.Send(docs.OpenAPI)
+		case "", "index.html":
+			c.Type("html")
+			return c.Send(docs.SwaggerUI)
+		}
+		return c.Status(404).JSON(fiber.Map{"error": "Halaman dokumentasi tidak ditemukan"})
+	})
+}
@@ -0,0 +1,147 @@
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSwaggerDocJSON(t *testing.T) {
+	app := fiber.New()
+	RegisterDocsRoutes(app)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/swagger/doc.json", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		t.Fatalf("status = %d, content-type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("doc.json bukan JSON yang valid: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want 3.x", spec.OpenAPI)
+	}
+	for _, path := range []string{"/api/prices", "/api/prices/{id}", "/api/markets", "/api/barang", "/auth/login"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("path %s tidak ada di spesifikasi", path)
+		}
+	}
+}
+
+// allRoutesApp mendaftarkan semua route aplikasi seperti main
+func allRoutesApp() *fiber.App {
+	app := fiber.New()
+	RegisterPriceRoutes(app)
+	RegisterMarketRoutes(app)
+	RegisterCategoryRoutes(app)
+	RegisterMarketOfficerRoutes(app)
+	OfficerRoutes(app)
+	RegisterBarangRoutes(app)
+	SetupRoutes(app)
+	RegisterSyncRoutes(app)
+	RegisterUploadRoutes(app)
+	RegisterDocsRoutes(app)
+	// Route login, token dan health check didaftarkan langsung di main
+	for _, path := range []string{"/api/login", "/api/admin/login", "/auth/login", "/auth/refresh", "/auth/logout"} {
+		app.Post(path, func(c *fiber.Ctx) error { return nil })
+	}
+	app.Get("/healthz", func(c *fiber.Ctx) error { return nil })
+	return app
+}
+
+// documentedOperations membaca spesifikasi dari /swagger/doc.json sebagai "METHOD /path/:param"
+func documentedOperations(t *testing.T, app *fiber.App) map[string]bool {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", "/swagger/doc.json", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var spec struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(body, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	param := regexp.MustCompile(`\{([^}]+)\}`)
+	operations := map[string]bool{}
+	for path, methods := range spec.Paths {
+		route := strings.TrimSuffix(param.ReplaceAllString(path, ":$1"), "/")
+		for method := range methods {
+			if method == "parameters" {
+				continue
+			}
+			operations[strings.ToUpper(method)+" "+route] = true
+		}
+	}
+	return operations
+}
+
+// Setiap path dan method di spesifikasi harus benar-benar terdaftar di router
+func TestSwaggerPathsAreRegistered(t *testing.T) {
+	app := allRoutesApp()
+
+	registered := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		registered[route.Method+" "+strings.TrimSuffix(route.Path, "/")] = true
+	}
+
+	for operation := range documentedOperations(t, app) {
+		if !registered[operation] {
+			t.Errorf("%s ada di spesifikasi tetapi tidak terdaftar di router", operation)
+		}
+	}
+}
+
+// Sebaliknya, setiap route yang terdaftar harus ada di spesifikasi
+func TestRegisteredRoutesAreDocumented(t *testing.T) {
+	app := allRoutesApp()
+	documented := documentedOperations(t, app)
+
+	for _, route := range app.GetRoutes(true) {
+		// HEAD dibuat otomatis oleh fiber untuk setiap GET, halaman Swagger bukan bagian API
+		if route.Method == fiber.MethodHead || strings.HasPrefix(route.Path, "/swagger") {
+			continue
+		}
+		if operation := route.Method + " " + strings.TrimSuffix(route.Path, "/"); !documented[operation] {
+			t.Errorf("%s terdaftar di router tetapi tidak ada di spesifikasi", operation)
+		}
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	app := fiber.New()
+	RegisterDocsRoutes(app)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/swagger", fiber.StatusMovedPermanently},
+		{"/swagger/", fiber.StatusMovedPermanently},
+		{"/swagger/index.html", 200},
+		{"/swagger/tidak-ada.js", 404},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("GET %s: status = %d, want %d", tt.path, resp.StatusCode, tt.wantStatus)
+		}
+	}
+}
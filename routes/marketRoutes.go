@@ -2,6 +2,7 @@ package routes
 
 import (
 	"backend/controllers"
+	"backend/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,10 +10,17 @@ import (
 func RegisterMarketRoutes(app *fiber.App) {
 	api := app.Group("/api")
 
-	api.Get("/markets", controllers.GetMarkets)            // Ambil semua pasar
+	includeDeleted := func(c *fiber.Ctx) bool { return c.QueryBool("include_deleted") }
+	api.Get("/markets", middleware.AdminWhen(includeDeleted), middleware.ETag, controllers.GetMarkets) // Ambil semua pasar
+	api.Get("/markets/nearby", controllers.GetNearbyMarkets) // Pasar terdekat dari koordinat
 	api.Get("/markets/:id", controllers.GetMarketByID)     // Ambil pasar berdasarkan ID
+	api.Get("/markets/:id/profile", controllers.GetMarketProfile) // Profil lengkap pasar
+	api.Get("/markets/:id/categories/summary", controllers.GetCategorySummaryByMarket) // Kategori + jumlah barang
+	api.Get("/markets/:id/daily-digest", controllers.GetMarketDailyDigest) // Ringkasan harian untuk notifikasi
+	api.Get("/markets/:market_id/dashboard", middleware.JWTMiddleware, middleware.ValidateMarketAccess, controllers.GetMarketDashboard) // Dashboard khusus pasar petugas
 	api.Post("/markets", controllers.CreateMarket)         // Tambah pasar baru
 	api.Put("/markets/:id", controllers.UpdateMarket)      // Update pasar
 	api.Put("/markets/:id/location", controllers.UpdateMarketLocation) // Perbaiki lokasi pasar
-	api.Delete("/markets/:id", controllers.DeleteMarket)   // Hapus pasar
+	api.Delete("/markets/:id", middleware.JWTAdminMiddleware, controllers.DeleteMarket) // Hapus pasar (khusus admin)
+	api.Post("/markets/:id/restore", middleware.JWTAdminMiddleware, controllers.RestoreMarket) // Pulihkan pasar yang dihapus
 }
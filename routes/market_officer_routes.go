@@ -10,20 +10,35 @@ import (
 func RegisterMarketOfficerRoutes(app *fiber.App) {
 	api := app.Group("/api/market-officers")
 
-	api.Get("/", controllers.GetMarketOfficers)         // Ambil semua petugas pasar
-	api.Get("/:id", controllers.GetMarketOfficerByID)   // Ambil petugas pasar berdasarkan ID
-	api.Post("/", controllers.CreateMarketOfficer)      // Tambah petugas pasar baru
-	api.Put("/:id", controllers.UpdateMarketOfficer)    // Perbarui data petugas pasar
-	api.Delete("/:id", controllers.DeleteMarketOfficer) // Hapus petugas pasar
+	// Sesi login petugas, harus didaftarkan sebelum /:id
+	api.Get("/me/sessions", middleware.JWTMiddleware, controllers.GetMySessions)
+	api.Delete("/me/sessions/:jti", middleware.JWTMiddleware, controllers.RevokeMySession)
+	api.Get("/:id/sessions", middleware.JWTAdminMiddleware, controllers.GetOfficerSessions)
+	api.Delete("/:id/sessions/:jti", middleware.JWTAdminMiddleware, controllers.RevokeOfficerSession)
+
+	api.Get("/", controllers.GetMarketOfficers)       // Ambil semua petugas pasar
+	api.Get("/:id", controllers.GetMarketOfficerByID) // Ambil petugas pasar berdasarkan ID
+
+	// Perubahan data petugas hanya untuk admin
+	requireAdmin := middleware.RequireRole("admin")
+	api.Post("/", middleware.JWTMiddleware, requireAdmin, middleware.Idempotency, controllers.CreateMarketOfficer) // Tambah petugas pasar baru
+	api.Put("/:id", middleware.JWTMiddleware, requireAdmin, controllers.UpdateMarketOfficer)                       // Perbarui data petugas pasar
+	api.Delete("/:id", middleware.JWTMiddleware, requireAdmin, controllers.DeleteMarketOfficer)                    // Hapus petugas pasar
 }
 
 func OfficerRoutes(app *fiber.App) {
-	app.Patch("/api/officers/:id/toggle", controllers.ToggleOfficerStatus)
+	app.Get("/api/officers/me", middleware.JWTMiddleware, controllers.GetMyProfile)
+	app.Put("/api/officers/me", middleware.JWTMiddleware, controllers.UpdateMyProfile)
+	app.Post("/api/officers/me/password", middleware.JWTMiddleware, controllers.ChangeMyPassword)
+	app.Patch("/api/officers/:id/toggle", middleware.JWTMiddleware, middleware.RequireRole("admin"), controllers.ToggleOfficerStatus)
+	// Audit perubahan harga per petugas, khusus admin
+	app.Get("/api/officers/:id/prices", middleware.JWTMiddleware, middleware.RequireRole("admin"), controllers.GetOfficerPriceChanges)
 }
 
 func SetupRoutes(app *fiber.App) {
+	// Alias lama untuk tambah petugas, dijaga sama seperti POST /api/market-officers
 	officerRoutes := app.Group("/officers")
-	officerRoutes.Post("/", controllers.CreateMarketOfficer)
+	officerRoutes.Post("/", middleware.JWTMiddleware, middleware.RequireRole("admin"), middleware.Idempotency, controllers.CreateMarketOfficer)
 
 	api := app.Group("/api")
 
@@ -34,7 +49,3 @@ func SetupRoutes(app *fiber.App) {
 	protected.Put("/categories/:id", controllers.UpdateCategory)
 	protected.Delete("/categories/:id", controllers.DeleteCategory)
 }
-func MarketOfficer(app *fiber.App) {
-	auth := app.Group("/auth")
-	auth.Post("/login", controllers.Login)
-}
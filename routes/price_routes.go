@@ -2,6 +2,7 @@ package routes
 
 import (
 	"backend/controllers"
+	"backend/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,13 +11,29 @@ func RegisterPriceRoutes(app *fiber.App) {
 	api := app.Group("/api")
 	api.Get("/prices/chart/:id", controllers.GetPriceHistory)
 	api.Get("/price-histories/:item_id", controllers.GetPriceHistoryByItem)
+	api.Get("/price-histories/:item_id/chart.png", controllers.GetPriceHistoryChart)
 	api.Get("/price-histories/category/:category_id", controllers.GetPriceHistoryByCategory)
 
-	api.Get("/prices", controllers.GetPrices)
+	api.Get("/prices/compare", controllers.ComparePriceAcrossMarkets)
+	api.Post("/prices/bulk", middleware.JWTMiddleware, controllers.BulkUpdatePrices)
+	api.Post("/prices/import", middleware.JWTMiddleware, controllers.ImportPrices)
+	api.Get("/prices/new", controllers.GetNewCommodities)
+	api.Get("/prices/movers", controllers.GetPriceMovers)
+	api.Get("/prices/export", controllers.ExportPrices)
+	api.Get("/prices/disputes", controllers.GetPriceDisputes)
+	api.Put("/prices/disputes/:id/resolve", middleware.JWTAdminMiddleware, controllers.ResolvePriceDispute)
+	api.Post("/prices/:id/dispute", middleware.JWTMiddleware, controllers.CreatePriceDispute)
+	api.Post("/prices/:id/confirm", middleware.JWTMiddleware, controllers.ConfirmPriceUnchanged)
+	api.Get("/prices/:item_id/matrix", controllers.GetPriceMatrix)
+
+	api.Get("/prices", middleware.ETag, controllers.GetPrices)
 	api.Get("/prices/:id", controllers.GetPriceByID)
-	api.Post("/prices", controllers.CreatePrice)
-	api.Put("/prices/:id", controllers.UpdatePrice)
-	api.Delete("/prices/:id", controllers.DeletePrice)
+	// Perubahan harga wajib login, petugas hanya untuk pasarnya sendiri dan hapus khusus admin
+	api.Post("/prices", middleware.JWTMiddleware, middleware.Idempotency, controllers.CreatePrice)
+	api.Put("/prices/:id", middleware.JWTMiddleware, controllers.UpdatePrice)
+	api.Delete("/prices/:id", middleware.JWTMiddleware, middleware.RequireRole("admin"), controllers.DeletePrice)
 
 	api.Get("/dashboard-data", controllers.GetDashboardData)
+	api.Get("/meta", controllers.GetMeta)
+	api.Get("/price-index", controllers.GetPriceIndex)
 }
@@ -9,4 +9,5 @@ import (
 func RegisterSyncRoutes(app *fiber.App) {
 	api := app.Group("/api")
 	api.Get("/sync", controllers.SyncBarangAndPrice)
+	api.Get("/sync/status/:job_id", controllers.GetSyncStatus)
 }
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"backend/controllers"
+	"backend/middleware"
+	"backend/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegisterUploadRoutes(app *fiber.App) {
+	api := app.Group("/api")
+	api.Post("/uploads/image", middleware.JWTMiddleware, controllers.UploadImage)
+
+	// Berkas di storage lokal disajikan langsung oleh aplikasi
+	if local, ok := storage.Default.(*storage.LocalStorage); ok {
+		app.Static(local.BaseURL, local.Dir)
+	}
+}
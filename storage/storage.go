@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage menyimpan berkas unggahan dan mengembalikan URL publiknya.
+// Backend lain (mis. S3) cukup memenuhi interface ini.
+type Storage interface {
+	Save(ctx context.Context, name, contentType string, r io.Reader) (string, error)
+}
+
+// LocalStorage menyimpan berkas di direktori lokal yang disajikan aplikasi di BaseURL
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+const (
+	defaultUploadDir     = "uploads"
+	defaultUploadBaseURL = "/uploads"
+)
+
+// NewLocalStorageFromEnv membaca UPLOAD_DIR (default "uploads") dan UPLOAD_BASE_URL (default "/uploads")
+func NewLocalStorageFromEnv(getenv func(string) string) *LocalStorage {
+	s := &LocalStorage{Dir: getenv("UPLOAD_DIR"), BaseURL: getenv("UPLOAD_BASE_URL")}
+	if s.Dir == "" {
+		s.Dir = defaultUploadDir
+	}
+	if s.BaseURL == "" {
+		s.BaseURL = defaultUploadBaseURL
+	}
+	return s
+}
+
+func (s *LocalStorage) Save(_ context.Context, name, _ string, r io.Reader) (string, error) {
+	if name == "" || name != filepath.Base(name) {
+		return "", fmt.Errorf("nama berkas tidak valid: %q", name)
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return "", err
+	}
+
+	// Tulis ke berkas sementara lalu rename agar berkas setengah jadi tidak pernah tersaji
+	tmp, err := os.CreateTemp(s.Dir, ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.Dir, name)); err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(s.BaseURL, "/") + "/" + name, nil
+}
+
+// Default adalah storage yang dipakai handler upload, diatur sekali saat aplikasi start
+var Default Storage = NewLocalStorageFromEnv(os.Getenv)
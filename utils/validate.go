@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	nikPattern   = regexp.MustCompile(`^\d{16}$`)
+	phonePattern = regexp.MustCompile(`^(\+62|0)8\d{8,11}$`)
+)
+
+// validate dipakai bersama oleh semua pemanggil, validator.Validate aman dipakai paralel
+// dan menyimpan cache aturan per tipe struct
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// Nama field di FieldError mengikuti tag json agar sama dengan body request
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	v.RegisterValidation("nik", func(fl validator.FieldLevel) bool {
+		return nikPattern.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		return phonePattern.MatchString(fl.Field().String())
+	})
+	return v
+}
+
+// FieldError menjelaskan satu field yang gagal validasi, Field memakai nama dari tag json
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors adalah kumpulan field yang gagal validasi
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fe := range e {
+		messages = append(messages, fe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate memeriksa tag `validate` pada struct dengan go-playground/validator dan
+// mengembalikan ValidationErrors bila ada field yang tidak valid. Selain aturan bawaan
+// library (required, omitempty, gte, max, oneof, ...) tersedia aturan tambahan:
+//
+//	notblank        string tidak boleh kosong atau hanya berisi spasi
+//	nik             NIK Indonesia, 16 digit angka
+//	phone           nomor HP Indonesia: 08 atau +628 diikuti 8-11 digit
+//
+// Relasi gorm harus diberi tag `validate:"-"` agar tidak ikut divalidasi.
+// Input yang bukan struct atau aturan yang tidak dikenal menghasilkan error biasa.
+func Validate(v any) (err error) {
+	// validator panic bila tag berisi aturan yang tidak terdaftar
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("validate: %v", r)
+		}
+	}()
+
+	verr := validate.Struct(v)
+	if verr == nil {
+		return nil
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(verr, &invalid) {
+		return fmt.Errorf("validate: %w", verr)
+	}
+
+	errs := make(ValidationErrors, 0, len(invalid))
+	for _, fe := range invalid {
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		errs = append(errs, FieldError{Field: fe.Field(), Rule: rule, Message: fieldMessage(fe)})
+	}
+	return errs
+}
+
+// fieldMessage menyusun pesan berbahasa Indonesia untuk satu field yang gagal validasi
+func fieldMessage(fe validator.FieldError) string {
+	name, param := fe.Field(), fe.Param()
+	isLength := false
+	switch fe.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		isLength = true
+	}
+
+	switch fe.Tag() {
+	case "required", "notblank":
+		return name + " wajib diisi"
+	case "nik":
+		return name + " harus 16 digit angka"
+	case "phone":
+		return name + " harus nomor HP Indonesia, mis. 081234567890 atau +6281234567890"
+	case "oneof":
+		return fmt.Sprintf("%s harus salah satu dari %s", name, strings.Join(strings.Fields(param), ", "))
+	case "min", "gte":
+		if isLength {
+			return fmt.Sprintf("%s minimal %s karakter", name, param)
+		}
+		return fmt.Sprintf("%s tidak boleh kurang dari %s", name, param)
+	case "max", "lte":
+		if isLength {
+			return fmt.Sprintf("%s maksimal %s karakter", name, param)
+		}
+		return fmt.Sprintf("%s tidak boleh lebih dari %s", name, param)
+	case "gt":
+		if isLength {
+			return fmt.Sprintf("%s harus lebih dari %s karakter", name, param)
+		}
+		return fmt.Sprintf("%s harus lebih dari %s", name, param)
+	case "lt":
+		if isLength {
+			return fmt.Sprintf("%s harus kurang dari %s karakter", name, param)
+		}
+		return fmt.Sprintf("%s harus kurang dari %s", name, param)
+	}
+	return fmt.Sprintf("%s tidak memenuhi aturan %s", name, fe.Tag())
+}
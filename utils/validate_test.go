@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"backend/models"
+	"errors"
+	"testing"
+)
+
+// fieldRules mengembalikan field -> rule dari ValidationErrors, atau nil bila valid
+func fieldRules(t *testing.T, v any) map[string]string {
+	t.Helper()
+	err := Validate(v)
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate mengembalikan error non-validasi: %v", err)
+	}
+	rules := map[string]string{}
+	for _, fe := range errs {
+		rules[fe.Field] = fe.Rule
+	}
+	return rules
+}
+
+func TestValidatePrice(t *testing.T) {
+	valid := models.Price{ItemName: "Beras", InitialPrice: 12000, CurrentPrice: 12500}
+	if rules := fieldRules(t, &valid); rules != nil {
+		t.Fatalf("harga valid ditolak: %v", rules)
+	}
+
+	missingName := valid
+	missingName.ItemName = "   "
+	if rules := fieldRules(t, &missingName); rules["item_name"] != "notblank" {
+		t.Errorf("nama kosong: rules = %v, want item_name notblank", rules)
+	}
+
+	negative := valid
+	negative.CurrentPrice = -1
+	negative.InitialPrice = -5
+	rules := fieldRules(t, negative)
+	if rules["current_price"] != "gte=0" || rules["initial_price"] != "gte=0" {
+		t.Errorf("harga negatif: rules = %v, want current_price dan initial_price gte=0", rules)
+	}
+}
+
+func TestValidateBarang(t *testing.T) {
+	barang := models.Barang{Nama: "", HargaPedagang1: -100}
+	rules := fieldRules(t, &barang)
+	if rules["nama"] != "required" {
+		t.Errorf("rules[nama] = %q, want required", rules["nama"])
+	}
+	if rules["harga_pedagang1"] != "gte=0" {
+		t.Errorf("rules[harga_pedagang1] = %q, want gte=0", rules["harga_pedagang1"])
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	type input struct {
+		Name    string   `json:"name" validate:"required,min=3,max=5"`
+		Qty     int      `json:"qty" validate:"gt=0,lte=10"`
+		Note    *string  `json:"note" validate:"required"`
+		Kind    string   `json:"kind" validate:"omitempty,oneof=a b"`
+		Tags    []string `json:"tags" validate:"max=2"`
+		Ignored string   `json:"-"`
+	}
+	note := "ok"
+
+	tests := []struct {
+		name  string
+		input input
+		want  map[string]string
+	}{
+		{"valid", input{Name: "abcd", Qty: 1, Note: &note}, nil},
+		{"terlalu pendek dan nol", input{Name: "ab", Qty: 0, Note: &note}, map[string]string{"name": "min=3", "qty": "gt=0"}},
+		{"terlalu panjang dan besar", input{Name: "abcdef", Qty: 11, Note: &note}, map[string]string{"name": "max=5", "qty": "lte=10"}},
+		{"pointer nil wajib", input{Name: "abc", Qty: 1}, map[string]string{"note": "required"}},
+		{"oneof", input{Name: "abc", Qty: 1, Note: &note, Kind: "c"}, map[string]string{"kind": "oneof=a b"}},
+		{"slice terlalu panjang", input{Name: "abc", Qty: 1, Note: &note, Tags: []string{"x", "y", "z"}}, map[string]string{"tags": "max=2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldRules(t, tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("rules = %v, want %v", got, tt.want)
+			}
+			for field, rule := range tt.want {
+				if got[field] != rule {
+					t.Errorf("rules[%s] = %q, want %q", field, got[field], rule)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRejectsNonStruct(t *testing.T) {
+	var nilPrice *models.Price
+	for _, v := range []any{nilPrice, "teks", 42} {
+		err := Validate(v)
+		var errs ValidationErrors
+		if err == nil || errors.As(err, &errs) {
+			t.Errorf("Validate(%#v) = %v, want error non-validasi", v, err)
+		}
+	}
+}
+
+func TestValidateOfficerNIKAndPhone(t *testing.T) {
+	officer := func(nik, phone string) models.MarketOfficer {
+		return models.MarketOfficer{Name: "Siti", Username: "siti", MarketID: 1, Nik: nik, Phone: phone}
+	}
+
+	tests := []struct {
+		name  string
+		nik   string
+		phone string
+		want  map[string]string
+	}{
+		{"valid", "3201234567890001", "081234567890", nil},
+		{"telepon +62", "3201234567890001", "+6281234567890", nil},
+		{"telepon boleh kosong", "3201234567890001", "", nil},
+		{"NIK kosong", "", "", map[string]string{"nik": "required"}},
+		{"NIK 15 digit", "320123456789000", "", map[string]string{"nik": "nik"}},
+		{"NIK 17 digit", "32012345678900012", "", map[string]string{"nik": "nik"}},
+		{"NIK berisi huruf", "32012345678900AB", "", map[string]string{"nik": "nik"}},
+		{"NIK dengan spasi", "3201 2345 6789 0001", "", map[string]string{"nik": "nik"}},
+		{"telepon bukan 08", "3201234567890001", "0212345678", map[string]string{"phone": "phone"}},
+		{"telepon terlalu pendek", "3201234567890001", "0812345", map[string]string{"phone": "phone"}},
+		{"telepon terlalu panjang", "3201234567890001", "0812345678901234", map[string]string{"phone": "phone"}},
+		{"telepon dengan tanda hubung", "3201234567890001", "0812-3456-7890", map[string]string{"phone": "phone"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := officer(tt.nik, tt.phone)
+			got := fieldRules(t, &o)
+			if len(got) != len(tt.want) {
+				t.Fatalf("rules = %v, want %v", got, tt.want)
+			}
+			for field, rule := range tt.want {
+				if got[field] != rule {
+					t.Errorf("rules[%s] = %q, want %q", field, got[field], rule)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	type input struct {
+		Name  string  `json:"name" validate:"required,notblank,max=5"`
+		Price float64 `json:"price" validate:"gte=0"`
+		Role  string  `json:"role" validate:"omitempty,oneof=admin officer"`
+	}
+
+	err := Validate(input{Name: "abcdefg", Price: -1, Role: "tamu"})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate = %v, want ValidationErrors", err)
+	}
+	want := map[string]string{
+		"name":  "name maksimal 5 karakter",
+		"price": "price tidak boleh kurang dari 0",
+		"role":  "role harus salah satu dari admin, officer",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("errs = %v, want %d field", errs, len(want))
+	}
+	for _, fe := range errs {
+		if fe.Message != want[fe.Field] {
+			t.Errorf("message[%s] = %q, want %q", fe.Field, fe.Message, want[fe.Field])
+		}
+	}
+
+	if err := Validate(input{Name: "  "}); err == nil || err.Error() != "name wajib diisi" {
+		t.Errorf("nama spasi: err = %v, want name wajib diisi", err)
+	}
+}
+
+// Relasi gorm bertag validate:"-" tidak boleh ikut divalidasi walau kosong
+func TestValidateSkipsAssociations(t *testing.T) {
+	price := models.Price{ItemName: "Beras", CurrentPrice: 12000, Market: models.Market{Name: ""}}
+	if rules := fieldRules(t, &price); rules != nil {
+		t.Errorf("harga dengan market kosong ditolak: %v", rules)
+	}
+
+	officer := models.MarketOfficer{Name: "Siti", Username: "siti", MarketID: 1, Nik: "3201234567890001"}
+	if rules := fieldRules(t, &officer); rules != nil {
+		t.Errorf("petugas dengan market kosong ditolak: %v", rules)
+	}
+}
+
+func TestValidateUnknownRule(t *testing.T) {
+	type input struct {
+		Name string `json:"name" validate:"tidak_ada"`
+	}
+	err := Validate(input{Name: "x"})
+	var errs ValidationErrors
+	if err == nil || errors.As(err, &errs) {
+		t.Errorf("Validate = %v, want error non-validasi", err)
+	}
+}